@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"open-meet/pkg/api"
 	"open-meet/pkg/config"
+	"open-meet/pkg/scheduler"
 )
 
 func main() {
@@ -17,14 +24,35 @@ func main() {
 	}
 
 	// Initialize API service with config
-	service, err := api.NewEngine(cfg)
+	service, err := api.NewService(cfg)
 	if err != nil {
 		fmt.Printf("Failed to create service: %v\n", err)
 		return
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background jobs run alongside the HTTP server and stop with it
+	jobs := service.NewScheduler(scheduler.SystemClock{})
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		jobs.Run(ctx)
+	}()
+
+	server := &http.Server{Addr: ":" + cfg.Port, Handler: api.NewEngine(service)}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
 	fmt.Printf("Server starting on port %s\n", cfg.Port)
-	if err := http.ListenAndServe(":"+cfg.Port, service); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		fmt.Printf("Server failed: %v\n", err)
+		stop()
 	}
+	<-jobsDone
 }
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"open-meet/pkg/calendar"
+	"open-meet/pkg/store"
+	"open-meet/pkg/util"
+)
+
+// calendarPushTimeout bounds a background push to an external calendar
+const calendarPushTimeout = 30 * time.Second
+
+// RoomCalendarHandler serves the room's meeting as a downloadable .ics invitation,
+// or as a cancellation once the host called it off
+func (s *Service) RoomCalendarHandler(c *gin.Context) {
+	log := s.Log.WithName("RoomCalendarHandler")
+
+	roomName := c.Param("roomName")
+	meeting, err := s.Store.Meetings().Get(c.Request.Context(), roomName)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	method := calendar.MethodRequest
+	if meeting.Status == store.MeetingCancelled {
+		method = calendar.MethodCancel
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+roomName+`.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8; method="+method, calendar.Marshal(method, meeting.CalendarEvents(s.joinURL(meeting.Code))...))
+}
+
+// CreateCalendarFeedHandler issues a new subscribable feed URL for the caller, revoking the previous one
+func (s *Service) CreateCalendarFeedHandler(c *gin.Context) {
+	log := s.Log.WithName("CreateCalendarFeedHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	token, err := s.Store.Meetings().CreateFeedToken(c.Request.Context(), userEmail)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("calendar feed issued", "email", userEmail)
+	c.JSON(http.StatusCreated, gin.H{"url": s.publicURL("/calendar/feed/" + token + ".ics")})
+}
+
+// CalendarFeedHandler serves everything the feed's owner hosts or is invited to.
+// Calendar apps poll it without a session, so the token in the URL is the credential.
+func (s *Service) CalendarFeedHandler(c *gin.Context) {
+	log := s.Log.WithName("CalendarFeedHandler")
+
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	_, meetings, err := s.Store.Meetings().FeedMeetings(c.Request.Context(), token)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	var events []calendar.Event
+	for i := range meetings {
+		events = append(events, meetings[i].CalendarEvents(s.joinURL(meetings[i].Code))...)
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", calendar.Marshal(calendar.MethodPublish, events...))
+}
+
+// ConnectGoogleCalendarHandler returns the Google consent page that lets the service
+// put the caller's meetings into their calendar
+func (s *Service) ConnectGoogleCalendarHandler(c *gin.Context) {
+	log := s.Log.WithName("ConnectGoogleCalendarHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": s.Store.Calendar().ConnectURL(userEmail)})
+}
+
+// GoogleCalendarCallbackHandler completes the consent flow. Google redirects the browser
+// here, so the signed state rather than a session identifies the user.
+func (s *Service) GoogleCalendarCallbackHandler(c *gin.Context) {
+	log := s.Log.WithName("GoogleCalendarCallbackHandler")
+
+	if reason := c.Query("error"); reason != "" {
+		log.Info("calendar consent declined", "reason", reason)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "calendar access was not granted", "code": "CONSENT_DECLINED"})
+		return
+	}
+
+	email, err := s.Store.Calendar().Connect(c.Request.Context(), c.Query("state"), c.Query("code"))
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("google calendar connected", "email", email)
+	c.JSON(http.StatusOK, gin.H{"connected": true, "email": email})
+}
+
+func (s *Service) DisconnectGoogleCalendarHandler(c *gin.Context) {
+	log := s.Log.WithName("DisconnectGoogleCalendarHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	if err := s.Store.Calendar().Disconnect(c.Request.Context(), userEmail); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("google calendar disconnected", "email", userEmail)
+	c.Status(http.StatusNoContent)
+}
+
+// pushCalendar brings the host's external calendar in line with the meeting in the
+// background, so a slow or failing calendar API never holds up the request
+func (s *Service) pushCalendar(code string) {
+	log := s.Log.WithName("pushCalendar").WithValues("code", code)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), calendarPushTimeout)
+		defer cancel()
+		if err := s.Store.Calendar().Push(ctx, code); err != nil {
+			log.Error(err, "failed to push meeting to calendar")
+		}
+	}()
+}
+
+// joinURL is the link people follow to join a meeting
+func (s *Service) joinURL(code string) string {
+	if s.Config.MeetingURL != "" {
+		return strings.TrimSuffix(s.Config.MeetingURL, "/") + "/" + code
+	}
+	return s.publicURL("/meetings/" + code)
+}
+
+// publicURL turns a path into a link clients can follow
+func (s *Service) publicURL(path string) string {
+	return strings.TrimSuffix(s.Config.PublicURL, "/") + path
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRoomCalendarHandler(t *testing.T) {
+	svc, _ := newTestService(t)
+	rec := serve(svc.CreateRoomHandler, http.MethodPost, "/rooms", "/rooms", testHost, CreateRoomRequest{
+		Title:     "Planning",
+		Attendees: []string{testUser},
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d: %s", rec.Code, rec.Body)
+	}
+	created := decode[CreateRoomResponse](t, rec)
+	if created.Meeting == nil || !strings.HasSuffix(created.CalendarURL, "/rooms/"+created.Room.Name+"/calendar.ics") {
+		t.Fatalf("created %+v, want a meeting with a calendar URL", created)
+	}
+	path := "/rooms/" + created.Room.Name + "/calendar.ics"
+
+	calendar := func(wantMethod string) {
+		t.Helper()
+		rec := serve(svc.RoomCalendarHandler, http.MethodGet, "/rooms/:roomName/calendar.ics", path, testUser, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+		if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/calendar") || !strings.Contains(got, "method="+wantMethod) {
+			t.Errorf("content type = %q, want text/calendar with method %s", got, wantMethod)
+		}
+		body := rec.Body.String()
+		// Long lines are folded, so the attendee's address may not be on one line
+		for _, want := range []string{"METHOD:" + wantMethod, "SUMMARY:Planning", "ORGANIZER:mailto:" + testHost, "ATTENDEE;"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("calendar lacks %q:\n%s", want, body)
+			}
+		}
+	}
+	calendar("REQUEST")
+
+	cancel := serve(svc.CancelMeetingHandler, http.MethodDelete, "/meetings/:code", "/meetings/"+created.Meeting.Code, testHost, nil)
+	if cancel.Code != http.StatusOK {
+		t.Fatalf("cancel: status = %d: %s", cancel.Code, cancel.Body)
+	}
+	calendar("CANCEL")
+
+	rec = serve(svc.RoomCalendarHandler, http.MethodGet, "/rooms/:roomName/calendar.ics", "/rooms/"+testRoom+"/calendar.ics", testHost, nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unscheduled room: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestCalendarFeedHandler(t *testing.T) {
+	svc, _ := newTestService(t)
+	scheduleMeeting(t, svc)
+
+	rec := serve(svc.CreateCalendarFeedHandler, http.MethodPost, "/calendar/feed", "/calendar/feed", testUser, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create feed: status = %d: %s", rec.Code, rec.Body)
+	}
+	url := decode[map[string]string](t, rec)["url"]
+	feedPath := strings.TrimPrefix(url, svc.Config.PublicURL)
+	if !strings.HasPrefix(feedPath, "/calendar/feed/") || !strings.HasSuffix(feedPath, ".ics") {
+		t.Fatalf("feed url = %q", url)
+	}
+
+	// Calendar apps poll the feed without a session
+	rec = serve(svc.CalendarFeedHandler, http.MethodGet, "/calendar/feed/:token", feedPath, "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("feed: status = %d: %s", rec.Code, rec.Body)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "METHOD:PUBLISH") || !strings.Contains(body, "SUMMARY:Planning") {
+		t.Errorf("feed lacks the invitee's meeting:\n%s", body)
+	}
+
+	rec = serve(svc.CalendarFeedHandler, http.MethodGet, "/calendar/feed/:token", "/calendar/feed/not-a-token.ics", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown token: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
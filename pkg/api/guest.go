@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"open-meet/pkg/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GuestJoinRequest struct {
+	DisplayName string `json:"display_name"`
+	GuestPass   string `json:"guest_pass"` // returned by an earlier request, to poll or rejoin as the same guest
+}
+
+// GuestJoinHandler lets someone without an account join a room that admits guests
+func (s *Service) GuestJoinHandler(c *gin.Context) {
+	log := s.Log.WithName("GuestJoinHandler")
+
+	roomName := c.Param("roomName")
+	req := new(GuestJoinRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: display_name or guest_pass is required", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	room, found, err := s.Store.Room().Get(ctx, roomName)
+	if err != nil {
+		log.Error(err, "failed to get room info", "roomName", roomName)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	if !found {
+		log.Info("room not found", "roomName", roomName)
+		c.JSON(http.StatusNotFound, gin.H{"error": "room not found", "code": "NOT_FOUND"})
+		return
+	}
+
+	admission, err := s.Store.Guests().Join(ctx, roomName, req.DisplayName, req.GuestPass)
+	if errors.Is(err, store.ErrRoomLocked) {
+		log.Info("room is locked", "roomName", roomName)
+		c.JSON(http.StatusForbidden, gin.H{"error": "room is locked", "code": "ROOM_LOCKED"})
+		return
+	}
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	if admission.Status == store.AdmissionPending {
+		log.Info("guest waiting for host approval", "roomName", roomName, "identity", admission.Identity)
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":     admission.Status,
+			"code":       "WAITING_FOR_APPROVAL",
+			"identity":   admission.Identity,
+			"guest_pass": admission.Pass,
+		})
+		return
+	}
+
+	log.Info("guest token generated", "roomName", roomName, "identity", admission.Identity, "roomSid", room.Sid)
+	c.JSON(http.StatusOK, gin.H{
+		"token":      admission.Token,
+		"identity":   admission.Identity,
+		"name":       admission.Name,
+		"guest_pass": admission.Pass,
+		"room": gin.H{
+			"name":             room.Name,
+			"sid":              room.Sid,
+			"num_participants": room.NumParticipants,
+		},
+	})
+}
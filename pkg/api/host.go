@@ -0,0 +1,617 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+
+	"open-meet/pkg/store"
+	"open-meet/pkg/util"
+)
+
+type ParticipantActionRequest struct {
+	Identity string `json:"identity" binding:"required"`
+}
+
+type MuteRequest struct {
+	Identity string `json:"identity" binding:"required"`
+	Source   string `json:"source"` // track source to mute, defaults to microphone
+}
+
+type MuteAllRequest struct {
+	Source string `json:"source"`
+}
+
+type TransferHostRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type SuccessionPolicyRequest struct {
+	Policy string `json:"policy" binding:"required"`
+}
+
+type WaitingRoomRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+type GuestPolicyRequest struct {
+	Policy string `json:"policy" binding:"required"`
+}
+
+type AdmissionRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type MediaPolicyRequest struct {
+	PublishSources []string `json:"publish_sources"`
+}
+
+type ScreenSharePolicyRequest struct {
+	Policy string `json:"policy" binding:"required"`
+}
+
+type PresenterRequest struct {
+	Identity string `json:"identity" binding:"required"`
+}
+
+type CoHostRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role"`
+}
+
+func (s *Service) EndMeetingHandler(c *gin.Context) {
+	log := s.Log.WithName("EndMeetingHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	if err := s.Store.Host().EndMeeting(c.Request.Context(), roomName, hostEmail); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("meeting ended", "roomName", roomName, "host", hostEmail)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "ended": true})
+}
+
+func (s *Service) LockRoomHandler(c *gin.Context) {
+	log := s.Log.WithName("LockRoomHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	if err := s.Store.Host().LockRoom(c.Request.Context(), roomName, hostEmail); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("room locked", "roomName", roomName, "host", hostEmail)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "locked": true})
+}
+
+func (s *Service) UnlockRoomHandler(c *gin.Context) {
+	log := s.Log.WithName("UnlockRoomHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	if err := s.Store.Host().UnlockRoom(c.Request.Context(), roomName, hostEmail); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("room unlocked", "roomName", roomName, "host", hostEmail)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "locked": false})
+}
+
+func (s *Service) KickParticipantHandler(c *gin.Context) {
+	log := s.Log.WithName("KickParticipantHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(ParticipantActionRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: identity is required", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	if err := s.Store.Host().KickParticipant(c.Request.Context(), roomName, hostEmail, req.Identity); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("participant kicked", "roomName", roomName, "host", hostEmail, "identity", req.Identity)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "identity": req.Identity, "kicked": true})
+}
+
+func (s *Service) MuteParticipantHandler(c *gin.Context) {
+	log := s.Log.WithName("MuteParticipantHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(MuteRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: identity is required", "code": "INVALID_REQUEST"})
+		return
+	}
+	if req.Source == "" {
+		req.Source = store.DefaultMuteSource
+	}
+
+	if err := s.Store.Host().MuteParticipant(c.Request.Context(), roomName, hostEmail, req.Identity, req.Source); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("participant muted", "roomName", roomName, "host", hostEmail, "identity", req.Identity, "source", req.Source)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "identity": req.Identity, "source": req.Source, "muted": true})
+}
+
+func (s *Service) UnmuteParticipantHandler(c *gin.Context) {
+	log := s.Log.WithName("UnmuteParticipantHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(MuteRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: identity is required", "code": "INVALID_REQUEST"})
+		return
+	}
+	if req.Source == "" {
+		req.Source = store.DefaultMuteSource
+	}
+
+	if err := s.Store.Host().UnmuteParticipant(c.Request.Context(), roomName, hostEmail, req.Identity, req.Source); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("participant unmuted", "roomName", roomName, "host", hostEmail, "identity", req.Identity, "source", req.Source)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "identity": req.Identity, "source": req.Source, "muted": false})
+}
+
+func (s *Service) MuteAllHandler(c *gin.Context) {
+	log := s.Log.WithName("MuteAllHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(MuteAllRequest)
+	if err := c.ShouldBindJSON(req); err != nil && !errors.Is(err, io.EOF) {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "code": "INVALID_REQUEST"})
+		return
+	}
+	if req.Source == "" {
+		req.Source = store.DefaultMuteSource
+	}
+
+	muted, err := s.Store.Host().MuteAll(c.Request.Context(), roomName, hostEmail, req.Source)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("room muted", "roomName", roomName, "host", hostEmail, "source", req.Source, "count", len(muted))
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "source": req.Source, "muted": muted})
+}
+
+func (s *Service) TransferHostHandler(c *gin.Context) {
+	log := s.Log.WithName("TransferHostHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(TransferHostRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: email is required", "code": "INVALID_REQUEST"})
+		return
+	}
+	req.Email = store.NormalizeEmail(req.Email)
+
+	if err := s.Store.Host().AssignHost(c.Request.Context(), roomName, hostEmail, req.Email); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("host transferred", "roomName", roomName, "from", hostEmail, "to", req.Email)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "host": req.Email})
+}
+
+func (s *Service) SuccessionPolicyHandler(c *gin.Context) {
+	log := s.Log.WithName("SuccessionPolicyHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(SuccessionPolicyRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: policy is required", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	policy, err := store.ParseSuccessionPolicy(req.Policy)
+	if err != nil {
+		log.Info("invalid succession policy", "policy", req.Policy)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_REQUEST"})
+		return
+	}
+
+	if err := s.Store.Host().SetSuccessionPolicy(c.Request.Context(), roomName, hostEmail, policy); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("succession policy updated", "roomName", roomName, "host", hostEmail, "policy", policy)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "policy": policy})
+}
+
+func (s *Service) AddCoHostHandler(c *gin.Context) {
+	log := s.Log.WithName("AddCoHostHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(CoHostRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: email is required", "code": "INVALID_REQUEST"})
+		return
+	}
+	req.Email = store.NormalizeEmail(req.Email)
+	if req.Role == "" {
+		req.Role = string(store.RoleCoHost)
+	}
+
+	role, err := store.ParseRole(req.Role)
+	if err != nil {
+		log.Info("invalid role", "role", req.Role)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_REQUEST"})
+		return
+	}
+
+	if err := s.Store.Host().AddCoHost(c.Request.Context(), roomName, hostEmail, req.Email, role); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("role granted", "roomName", roomName, "host", hostEmail, "email", req.Email, "role", role)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "email": req.Email, "role": role})
+}
+
+func (s *Service) RemoveCoHostHandler(c *gin.Context) {
+	log := s.Log.WithName("RemoveCoHostHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	email := store.NormalizeEmail(c.Param("email"))
+	if err := s.Store.Host().RemoveCoHost(c.Request.Context(), roomName, hostEmail, email); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("role revoked", "roomName", roomName, "host", hostEmail, "email", email)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "email": email, "role": store.RoleParticipant})
+}
+
+func (s *Service) WaitingRoomHandler(c *gin.Context) {
+	log := s.Log.WithName("WaitingRoomHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(WaitingRoomRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: enabled is required", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	if err := s.Store.Host().SetWaitingRoom(c.Request.Context(), roomName, hostEmail, *req.Enabled); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("waiting room updated", "roomName", roomName, "host", hostEmail, "enabled", *req.Enabled)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "waiting_room": *req.Enabled})
+}
+
+func (s *Service) GuestPolicyHandler(c *gin.Context) {
+	log := s.Log.WithName("GuestPolicyHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(GuestPolicyRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: policy is required", "code": "INVALID_REQUEST"})
+		return
+	}
+	policy, err := store.ParseGuestPolicy(req.Policy)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	if err := s.Store.Host().SetGuestPolicy(c.Request.Context(), roomName, hostEmail, policy); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("guest policy updated", "roomName", roomName, "host", hostEmail, "policy", policy)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "guests": policy})
+}
+
+func (s *Service) ListAdmissionsHandler(c *gin.Context) {
+	log := s.Log.WithName("ListAdmissionsHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	status := store.AdmissionStatus(c.DefaultQuery("status", string(store.AdmissionPending)))
+	admissions, err := s.Store.Host().ListAdmissions(c.Request.Context(), roomName, hostEmail, status)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "admissions": admissions})
+}
+
+func (s *Service) ApproveAdmissionHandler(c *gin.Context) {
+	log := s.Log.WithName("ApproveAdmissionHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(AdmissionRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: email is required", "code": "INVALID_REQUEST"})
+		return
+	}
+	req.Email = store.NormalizeEmail(req.Email)
+
+	if err := s.Store.Host().ApproveAdmission(c.Request.Context(), roomName, hostEmail, req.Email); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("admission approved", "roomName", roomName, "host", hostEmail, "email", req.Email)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "email": req.Email, "status": store.AdmissionApproved})
+}
+
+func (s *Service) DenyAdmissionHandler(c *gin.Context) {
+	log := s.Log.WithName("DenyAdmissionHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(AdmissionRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: email is required", "code": "INVALID_REQUEST"})
+		return
+	}
+	req.Email = store.NormalizeEmail(req.Email)
+
+	if err := s.Store.Host().DenyAdmission(c.Request.Context(), roomName, hostEmail, req.Email); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("admission denied", "roomName", roomName, "host", hostEmail, "email", req.Email)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "email": req.Email, "status": store.AdmissionDenied})
+}
+
+func (s *Service) MediaPolicyHandler(c *gin.Context) {
+	log := s.Log.WithName("MediaPolicyHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(MediaPolicyRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	if err := s.Store.Host().SetPublishSources(c.Request.Context(), roomName, hostEmail, req.PublishSources); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("media policy updated", "roomName", roomName, "host", hostEmail, "publishSources", req.PublishSources)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "publish_sources": req.PublishSources})
+}
+
+func (s *Service) ScreenSharePolicyHandler(c *gin.Context) {
+	log := s.Log.WithName("ScreenSharePolicyHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(ScreenSharePolicyRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: policy is required", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	policy, err := store.ParseScreenSharePolicy(req.Policy)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	if err := s.Store.Host().SetScreenSharePolicy(c.Request.Context(), roomName, hostEmail, policy); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("screen share policy updated", "roomName", roomName, "host", hostEmail, "policy", policy)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "policy": policy})
+}
+
+func (s *Service) ListPresentersHandler(c *gin.Context) {
+	log := s.Log.WithName("ListPresentersHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	state, err := s.Store.Host().GetScreenShareState(c.Request.Context(), roomName, hostEmail)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+func (s *Service) GrantPresenterHandler(c *gin.Context) {
+	log := s.Log.WithName("GrantPresenterHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(PresenterRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: identity is required", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	if err := s.Store.Host().GrantPresenter(c.Request.Context(), roomName, hostEmail, req.Identity); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("presenter granted", "roomName", roomName, "host", hostEmail, "identity", req.Identity)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "identity": req.Identity, "presenter": true})
+}
+
+func (s *Service) RevokePresenterHandler(c *gin.Context) {
+	log := s.Log.WithName("RevokePresenterHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	identity := c.Param("identity")
+	if err := s.Store.Host().RevokePresenter(c.Request.Context(), roomName, hostEmail, identity); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("presenter revoked", "roomName", roomName, "host", hostEmail, "identity", identity)
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "identity": identity, "presenter": false})
+}
+
+// hostRequest resolves the caller and target room of a host control request.
+// It writes the error response itself and returns ok=false when the request cannot proceed.
+func (s *Service) hostRequest(c *gin.Context, log logr.Logger) (string, string, bool) {
+	// Get user email from context (set by Authentication middleware)
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return "", "", false
+	}
+
+	roomName := c.Param("roomName")
+	if roomName == "" {
+		log.Info("room name not provided")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "room name is required", "code": "INVALID_REQUEST"})
+		return "", "", false
+	}
+
+	_, found, err := s.Store.Room().Get(c.Request.Context(), roomName)
+	if err != nil {
+		log.Error(err, "failed to get room", "roomName", roomName)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error", "code": "INTERNAL_ERROR"})
+		return "", "", false
+	}
+	if !found {
+		log.Info("room not found", "roomName", roomName)
+		c.JSON(http.StatusNotFound, gin.H{"error": "room not found", "code": "ROOM_NOT_FOUND"})
+		return "", "", false
+	}
+
+	return roomName, userEmail, true
+}
+
+// hostError maps store errors from host operations onto HTTP responses
+func hostError(c *gin.Context, log logr.Logger, err error) {
+	switch {
+	case errors.Is(err, store.ErrUnauthorized):
+		log.Info("host operation forbidden", "reason", err.Error())
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "FORBIDDEN"})
+	case errors.Is(err, store.ErrNotFound):
+		log.Info("host operation target not found", "reason", err.Error())
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": "NOT_FOUND"})
+	case errors.Is(err, store.ErrInvalid):
+		log.Info("host operation given invalid input", "reason", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_REQUEST"})
+	case errors.Is(err, store.ErrConflict):
+		log.Info("host operation conflicts with room state", "reason", err.Error())
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "CONFLICT"})
+	default:
+		log.Error(err, "host operation failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error", "code": "INTERNAL_ERROR"})
+	}
+}
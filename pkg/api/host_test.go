@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestMuteParticipantHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller string
+		body   any
+		want   int
+	}{
+		{name: "host", caller: testHost, body: MuteRequest{Identity: testUser}, want: http.StatusOK},
+		{name: "camera", caller: testHost, body: MuteRequest{Identity: testUser, Source: "camera"}, want: http.StatusOK},
+		{name: "not a host", caller: "bob@example.com", body: MuteRequest{Identity: testUser}, want: http.StatusForbidden},
+		{name: "unknown source", caller: testHost, body: MuteRequest{Identity: testUser, Source: "speaker"}, want: http.StatusBadRequest},
+		{name: "not in the room", caller: testHost, body: MuteRequest{Identity: "carol@example.com"}, want: http.StatusNotFound},
+		{name: "no identity", caller: testHost, body: MuteRequest{}, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(t, testUser, "bob@example.com")
+			rec := serve(svc.MuteParticipantHandler, http.MethodPost, "/rooms/:roomName/host/mute", "/rooms/"+testRoom+"/host/mute", tt.caller, tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestMuteParticipantKeepsOtherTracks(t *testing.T) {
+	svc, server := newTestService(t, testUser)
+	publish(server, testUser, livekit.TrackSource_MICROPHONE)
+	publish(server, testUser, livekit.TrackSource_CAMERA)
+
+	rec := serve(svc.MuteParticipantHandler, http.MethodPost, "/rooms/:roomName/host/mute", "/rooms/"+testRoom+"/host/mute", testHost,
+		MuteRequest{Identity: testUser})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	// Muting takes the microphone track down without revoking the right to publish
+	if !server.Permission(testRoom, testUser).GetCanPublish() {
+		t.Error("mute revoked publish permission")
+	}
+	info, err := svc.Store.Participant().GetParticipantInfo(context.Background(), testRoom, testUser)
+	if err != nil {
+		t.Fatalf("GetParticipantInfo: %v", err)
+	}
+	for _, track := range info.GetTracks() {
+		if want := track.GetSource() == livekit.TrackSource_MICROPHONE; track.GetMuted() != want {
+			t.Errorf("%s muted = %v, want %v", track.GetSource(), track.GetMuted(), want)
+		}
+	}
+}
+
+func TestMuteAllHandlerLeavesHost(t *testing.T) {
+	svc, server := newTestService(t, testUser)
+	publish(server, testUser, livekit.TrackSource_MICROPHONE)
+	publish(server, testHost, livekit.TrackSource_MICROPHONE)
+
+	rec := serve(svc.MuteAllHandler, http.MethodPost, "/rooms/:roomName/host/mute-all", "/rooms/"+testRoom+"/host/mute-all", testHost, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	resp := decode[struct {
+		Muted []string `json:"muted"`
+	}](t, rec)
+	if len(resp.Muted) != 1 || resp.Muted[0] != testUser {
+		t.Errorf("muted = %v, want [%s]", resp.Muted, testUser)
+	}
+}
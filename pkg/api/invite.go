@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"open-meet/pkg/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateInviteRequest struct {
+	Email     string     `json:"email"`      // binds the invite to one user when set
+	Role      string     `json:"role"`       // participant, co-host or viewer; participant when empty
+	MaxUses   int        `json:"max_uses"`   // 0 for unlimited
+	ExpiresAt *time.Time `json:"expires_at"` // a week from now when absent
+}
+
+func (s *Service) CreateInviteHandler(c *gin.Context) {
+	log := s.Log.WithName("CreateInviteHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(CreateInviteRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "code": "INVALID_REQUEST"})
+		return
+	}
+	opts := store.InviteOptions{
+		Email:   req.Email,
+		Role:    store.Role(req.Role),
+		MaxUses: req.MaxUses,
+	}
+	if req.ExpiresAt != nil {
+		opts.TTL = time.Until(*req.ExpiresAt)
+		if opts.TTL <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: expires_at must be in the future", "code": "INVALID_REQUEST"})
+			return
+		}
+	}
+
+	invite, token, err := s.Store.Invites().Create(c.Request.Context(), roomName, hostEmail, opts)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("invite created", "roomName", roomName, "host", hostEmail, "invite", invite.ID, "role", invite.Role)
+	c.JSON(http.StatusCreated, gin.H{
+		"invite": invite,
+		"token":  token,
+		"url":    s.joinURL(roomName) + "?invite=" + url.QueryEscape(token),
+	})
+}
+
+func (s *Service) ListInvitesHandler(c *gin.Context) {
+	log := s.Log.WithName("ListInvitesHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	invites, err := s.Store.Invites().List(c.Request.Context(), roomName, hostEmail)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "invites": invites})
+}
+
+func (s *Service) RevokeInviteHandler(c *gin.Context) {
+	log := s.Log.WithName("RevokeInviteHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	inviteID := c.Param("inviteID")
+	if err := s.Store.Invites().Revoke(c.Request.Context(), roomName, hostEmail, inviteID); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("invite revoked", "roomName", roomName, "host", hostEmail, "invite", inviteID)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"open-meet/pkg/notify"
+	"open-meet/pkg/scheduler"
+)
+
+const (
+	deliverInterval  = 15 * time.Second
+	reminderInterval = time.Minute
+	idleRoomInterval = 5 * time.Minute
+
+	defaultReminderLead    = 10 * time.Minute
+	defaultRoomIdleTimeout = 15 * time.Minute
+)
+
+// NewScheduler returns a scheduler running the service's background jobs on clock
+func (s *Service) NewScheduler(clock scheduler.Clock) *scheduler.Scheduler {
+	sched := scheduler.New(clock, s.Log.WithName("scheduler"))
+	dispatcher := notify.NewDispatcher(s.Store.Outbox(), s.notificationChannels(), s.Log.WithName("notify"))
+
+	sched.Every("deliver-notifications", deliverInterval, dispatcher.Deliver)
+	sched.Every("meeting-reminders", reminderInterval, func(ctx context.Context, now time.Time) error {
+		return s.enqueueReminders(ctx, dispatcher, now)
+	})
+	sched.Every("idle-room-cleanup", idleRoomInterval, s.cleanupIdleRooms)
+	return sched
+}
+
+// notificationChannels returns the channels enabled in the config
+func (s *Service) notificationChannels() map[string]notify.Channel {
+	channels := make(map[string]notify.Channel)
+	if s.Config.SMTPAddr != "" {
+		channels["email"] = &notify.SMTPChannel{
+			Addr:     s.Config.SMTPAddr,
+			From:     s.Config.SMTPFrom,
+			Username: s.Config.SMTPUsername,
+			Password: s.Config.SMTPPassword,
+		}
+	}
+	if s.Config.NotifyWebhookURL != "" {
+		channels["webhook"] = &notify.WebhookChannel{URL: s.Config.NotifyWebhookURL}
+	}
+	if s.Config.SlackWebhookURL != "" {
+		channels["slack"] = &notify.SlackChannel{WebhookURL: s.Config.SlackWebhookURL}
+	}
+	return channels
+}
+
+// enqueueReminders queues a reminder for every occurrence starting within the lead time.
+// Reminders are keyed by occurrence, so runs overlapping the same window, before or
+// after a restart, remind once.
+func (s *Service) enqueueReminders(ctx context.Context, dispatcher *notify.Dispatcher, now time.Time) error {
+	lead := s.Config.ReminderLeadTime
+	if lead <= 0 {
+		lead = defaultReminderLead
+	}
+
+	upcoming, err := s.Store.Meetings().Starting(ctx, now, now.Add(lead))
+	if err != nil {
+		return err
+	}
+
+	for _, occurrence := range upcoming {
+		meeting := occurrence.Meeting
+		start := occurrence.StartAt
+		if loc, err := time.LoadLocation(meeting.Timezone); err == nil {
+			start = start.In(loc)
+		}
+
+		key := fmt.Sprintf("reminder|%s|%d", meeting.ID, occurrence.RecurrenceID.Unix())
+		err := dispatcher.Enqueue(ctx, key, notify.Message{
+			Recipients: append([]string{meeting.HostEmail}, meeting.Invitees...),
+			Subject:    fmt.Sprintf("Reminder: %s starts at %s", occurrence.Title, start.Format("15:04 MST")),
+			Body: fmt.Sprintf("%s starts in %d minutes, on %s.",
+				occurrence.Title, int(occurrence.StartAt.Sub(now).Round(time.Minute).Minutes()), start.Format("Monday, January 2 at 15:04 MST")),
+			URL: s.joinURL(meeting.Code),
+		}, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanupIdleRooms deletes rooms that have been empty for longer than the idle timeout,
+// unless a meeting is about to use them
+func (s *Service) cleanupIdleRooms(ctx context.Context, now time.Time) error {
+	log := s.Log.WithName("cleanupIdleRooms")
+
+	timeout := s.Config.RoomIdleTimeout
+	if timeout <= 0 {
+		timeout = defaultRoomIdleTimeout
+	}
+
+	rooms, err := s.Store.Room().List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, room := range rooms {
+		if room.GetNumParticipants() > 0 {
+			continue
+		}
+
+		idleSince := time.Unix(room.GetCreationTime(), 0)
+		presence, err := s.Store.Registry().ListPresence(ctx, room.GetName())
+		if err != nil {
+			return err
+		}
+		for _, record := range presence {
+			idleSince = latest(idleSince, record.JoinedAt, record.LeftAt)
+		}
+		if now.Sub(idleSince) < timeout {
+			continue
+		}
+
+		// Scheduled rooms are kept for a meeting that is under way or about to start
+		if occurrences, err := s.Store.Meetings().Occurrences(ctx, room.GetName(), now, 1); err == nil &&
+			len(occurrences) > 0 && occurrences[0].StartAt.Before(now.Add(timeout)) {
+			continue
+		}
+
+		if err := s.Store.Room().Delete(ctx, room.GetName()); err != nil {
+			log.Error(err, "failed to delete idle room", "roomName", room.GetName())
+			continue
+		}
+		log.Info("idle room deleted", "roomName", room.GetName(), "idleSince", idleSince)
+	}
+	return nil
+}
+
+func latest(times ...time.Time) time.Time {
+	return slices.MaxFunc(times, time.Time.Compare)
+}
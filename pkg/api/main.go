@@ -1,6 +1,7 @@
 package api
 
 import (
+	"open-meet/pkg/auth"
 	"open-meet/pkg/config"
 	"open-meet/pkg/logger"
 	"open-meet/pkg/middleware"
@@ -16,27 +17,36 @@ type Service struct {
 	Log    logr.Logger
 	Store  store.Store
 	Cache  any
+
+	// Providers sign users in with Google and the other configured identity providers
+	Providers *auth.Providers
 }
 
-func NewEngine(config *config.Config) (*gin.Engine, error) {
+// NewService creates the service shared by the HTTP API and the background jobs
+func NewService(config *config.Config) (*Service, error) {
 	log, err := logger.NewDevelopmentLogger()
 	if err != nil {
 		return nil, err
 	}
 
-	st, err := store.NewStore()
+	st, err := store.NewStore(config)
 	if err != nil {
 		log.Error(err, "failed to create store")
 		return nil, err
 	}
 
-	svc := &Service{
+	return &Service{
 		Config: config,
 		Log:    log,
 		Store:  st,
 		Cache:  nil,
-	}
 
+		Providers: auth.ProvidersFromConfig(config),
+	}, nil
+}
+
+// NewEngine creates the HTTP API of svc
+func NewEngine(svc *Service) *gin.Engine {
 	r := gin.Default()
 	gin.SetMode(gin.ReleaseMode)
 
@@ -47,22 +57,122 @@ func NewEngine(config *config.Config) (*gin.Engine, error) {
 	}
 	r.Use(middleware.Cors()).Use(middleware.Timeout(5 * time.Second))
 
-	room := r.Group("/rooms").Use(middleware.Authentication(), middleware.RateLimit())
+	authenticate := middleware.Authentication(svc.Log.WithName("Authentication"), svc.Store.Sessions(), svc.Providers)
+
+	room := r.Group("/rooms").Use(authenticate, middleware.RateLimit())
 	{
 		room.POST("", svc.CreateRoomHandler)
 		room.GET("/:roomName", svc.GetRoomHandler)
+		room.GET("/:roomName/participants", svc.ListParticipantsHandler)
+		room.GET("/:roomName/recordings", svc.ListRoomRecordingsHandler)
+		room.GET("/:roomName/calendar.ics", svc.RoomCalendarHandler)
+
+		// Host controls
+		room.POST("/:roomName/host/end", svc.EndMeetingHandler)
+		room.POST("/:roomName/host/lock", svc.LockRoomHandler)
+		room.POST("/:roomName/host/unlock", svc.UnlockRoomHandler)
+		room.POST("/:roomName/host/kick", svc.KickParticipantHandler)
+		room.POST("/:roomName/host/mute", svc.MuteParticipantHandler)
+		room.POST("/:roomName/host/unmute", svc.UnmuteParticipantHandler)
+		room.POST("/:roomName/host/mute-all", svc.MuteAllHandler)
+		room.POST("/:roomName/host/transfer", svc.TransferHostHandler)
+		room.POST("/:roomName/host/succession", svc.SuccessionPolicyHandler)
+		room.POST("/:roomName/host/cohosts", svc.AddCoHostHandler)
+		room.DELETE("/:roomName/host/cohosts/:email", svc.RemoveCoHostHandler)
+		room.POST("/:roomName/host/waiting-room", svc.WaitingRoomHandler)
+		room.POST("/:roomName/host/guests", svc.GuestPolicyHandler)
+		room.POST("/:roomName/host/media-policy", svc.MediaPolicyHandler)
+		room.POST("/:roomName/host/screen-share", svc.ScreenSharePolicyHandler)
+		room.GET("/:roomName/host/presenters", svc.ListPresentersHandler)
+		room.POST("/:roomName/host/presenters", svc.GrantPresenterHandler)
+		room.DELETE("/:roomName/host/presenters/:identity", svc.RevokePresenterHandler)
+		room.POST("/:roomName/host/recordings", svc.StartRecordingHandler)
+		room.POST("/:roomName/host/recordings/:recordingID/stop", svc.StopRecordingHandler)
+		room.GET("/:roomName/host/admissions", svc.ListAdmissionsHandler)
+		room.POST("/:roomName/host/admissions/approve", svc.ApproveAdmissionHandler)
+		room.POST("/:roomName/host/admissions/deny", svc.DenyAdmissionHandler)
+		room.GET("/:roomName/host/invites", svc.ListInvitesHandler)
+		room.POST("/:roomName/host/invites", svc.CreateInviteHandler)
+		room.DELETE("/:roomName/host/invites/:inviteID", svc.RevokeInviteHandler)
+
+		// Self-service media controls, always applied to the caller
+		room.POST("/:roomName/me/mute", svc.MuteSelfHandler)
+		room.POST("/:roomName/me/unmute", svc.UnmuteSelfHandler)
+		room.POST("/:roomName/me/video/enable", svc.EnableVideoHandler)
+		room.POST("/:roomName/me/video/disable", svc.DisableVideoHandler)
+		room.POST("/:roomName/me/screen/start", svc.ShareScreenHandler)
+		room.POST("/:roomName/me/screen/stop", svc.StopScreenShareHandler)
+		room.POST("/:roomName/me/connection-quality", svc.ConnectionQualityHandler)
+	}
+
+	meetings := r.Group("/meetings").Use(authenticate, middleware.RateLimit())
+	{
+		meetings.POST("", svc.ScheduleMeetingHandler)
+		meetings.GET("", svc.ListMeetingsHandler)
+		meetings.GET("/:code", svc.GetMeetingHandler)
+		meetings.PUT("/:code", svc.UpdateMeetingHandler)
+		meetings.DELETE("/:code", svc.CancelMeetingHandler)
+		meetings.POST("/:code/join", svc.JoinMeetingHandler)
+		meetings.GET("/:code/occurrences", svc.ListOccurrencesHandler)
+		meetings.PUT("/:code/occurrences/:recurrenceID", svc.OverrideOccurrenceHandler)
+		meetings.DELETE("/:code/occurrences/:recurrenceID", svc.CancelOccurrenceHandler)
+	}
+
+	cal := r.Group("/calendar")
+	{
+		cal.POST("/feed", authenticate, svc.CreateCalendarFeedHandler)
+		// Calendar apps cannot log in; the unguessable token in the path authorizes the feed
+		cal.GET("/feed/:token", svc.CalendarFeedHandler)
 
+		cal.POST("/google/connect", authenticate, svc.ConnectGoogleCalendarHandler)
+		cal.DELETE("/google", authenticate, svc.DisconnectGoogleCalendarHandler)
+		// Google redirects the browser here; the signed state identifies the user
+		cal.GET("/google/callback", svc.GoogleCalendarCallbackHandler)
 	}
 
+	recordings := r.Group("/recordings").Use(authenticate, middleware.RateLimit())
+	{
+		recordings.GET("", svc.ListMyRecordingsHandler)
+		recordings.GET("/:recordingID", svc.GetRecordingHandler)
+		recordings.DELETE("/:recordingID", svc.DeleteRecordingHandler)
+		recordings.POST("/:recordingID/link", svc.RecordingLinkHandler)
+	}
+
+	// Download links carry their own signature instead of a user session
+	r.GET("/recordings/:recordingID/download", svc.DownloadRecordingHandler)
+
 	oauth := r.Group("/")
 	{
 		oauth.POST("/callback", svc.CallbackHandler)
+		// The refresh token in the body is the credential, as the access token may have expired
+		oauth.POST("/token/refresh", svc.RefreshTokenHandler)
+		oauth.POST("/token/revoke", svc.RevokeTokenHandler)
+		oauth.POST("/logout", authenticate, svc.LogoutHandler)
+	}
+
+	providers := r.Group("/auth")
+	{
+		providers.GET("/providers", svc.ListProvidersHandler)
+		providers.GET("/:provider/authorize", svc.AuthorizeHandler)
+		providers.POST("/:provider/callback", svc.ProviderCallbackHandler)
+	}
+
+	// LiveKit authenticates webhooks with a signed token, not a user session
+	webhooks := r.Group("/webhooks")
+	{
+		webhooks.POST("/livekit", svc.LiveKitWebhookHandler)
+	}
+
+	// Guests have no account; rooms opt in and hosts may still approve each guest
+	guests := r.Group("/guest").Use(middleware.RateLimit())
+	{
+		guests.POST("/rooms/:roomName/join", svc.GuestJoinHandler)
 	}
 
-	participant := r.Group("/").Use(middleware.Authentication())
+	participant := r.Group("/").Use(authenticate)
 	{
 		participant.POST("/livekit-tokens", svc.LiveKitTokenHandler)
 	}
 
-	return r, nil
+	return r
 }
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+	"github.com/livekit/protocol/livekit"
+
+	"open-meet/pkg/config"
+	"open-meet/pkg/store"
+	"open-meet/pkg/store/storetest"
+)
+
+const (
+	testRoom = "standup"
+	testHost = "host@example.com"
+	testUser = "ada@example.com"
+)
+
+// newTestService returns a Service on an in-memory store whose LiveKit rooms live in
+// the returned fake. testRoom exists, owned by testHost, who is connected along with identities.
+func newTestService(t *testing.T, identities ...string) (*Service, *storetest.RoomService) {
+	t.Helper()
+	cfg := &config.Config{
+		PublicURL:         "https://meet.example.com",
+		SessionSigningKey: "test session key",
+		CalendarDriver:    "local",
+		EgressDriver:      "local",
+		LiveKitAPIKey:     "test api key",
+		LiveKitAPISecret:  "test api secret",
+	}
+	server := storetest.NewRoomService()
+	st, err := store.NewStoreWithRoomService(cfg, server)
+	if err != nil {
+		t.Fatalf("NewStoreWithRoomService: %v", err)
+	}
+	if _, err := st.Room().Create(context.Background(), testRoom, testHost); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, identity := range append(identities, testHost) {
+		server.Join(testRoom, identity)
+	}
+	return &Service{Config: cfg, Log: logr.Discard(), Store: st}, server
+}
+
+// publish gives a connected participant an unmuted track of source
+func publish(server *storetest.RoomService, identity string, source livekit.TrackSource) {
+	server.Publish(testRoom, identity, &livekit.TrackInfo{Sid: identity + "/" + source.String(), Source: source})
+}
+
+// serve sends a request to handler mounted at route, signed in as email
+func serve(handler gin.HandlerFunc, method, route, path, email string, body any) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Handle(method, route, func(c *gin.Context) { c.Set("email", email) }, handler)
+
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		encoded, _ := json.Marshal(body)
+		reader = bytes.NewReader(encoded)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode reads a JSON response body
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	return v
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+	"github.com/livekit/protocol/livekit"
+
+	"open-meet/pkg/store"
+)
+
+// ParticipantView is the public view of a participant in a room
+type ParticipantView struct {
+	Identity string                    `json:"identity"`
+	Name     string                    `json:"name"`
+	State    string                    `json:"state"`
+	JoinedAt int64                     `json:"joined_at"`
+	Metadata store.ParticipantMetadata `json:"metadata"`
+	Tracks   []store.TrackSummary      `json:"tracks"`
+}
+
+func newParticipantView(info *livekit.ParticipantInfo) ParticipantView {
+	view := ParticipantView{
+		Identity: info.GetIdentity(),
+		Name:     info.GetName(),
+		State:    info.GetState().String(),
+		JoinedAt: info.GetJoinedAt(),
+		Metadata: store.DecodeParticipantMetadata(info.GetMetadata()),
+		Tracks:   make([]store.TrackSummary, 0, len(info.GetTracks())),
+	}
+	for _, track := range info.GetTracks() {
+		view.Tracks = append(view.Tracks, store.TrackSummary{
+			Sid:    track.GetSid(),
+			Source: track.GetSource().String(),
+			Muted:  track.GetMuted(),
+		})
+	}
+	return view
+}
+
+func (s *Service) MuteSelfHandler(c *gin.Context) {
+	s.selfMedia(c, s.Log.WithName("MuteSelfHandler"), s.Store.Participant().MuteSelf)
+}
+
+func (s *Service) UnmuteSelfHandler(c *gin.Context) {
+	s.selfMedia(c, s.Log.WithName("UnmuteSelfHandler"), s.Store.Participant().UnmuteSelf)
+}
+
+func (s *Service) EnableVideoHandler(c *gin.Context) {
+	s.selfMedia(c, s.Log.WithName("EnableVideoHandler"), s.Store.Participant().EnableVideo)
+}
+
+func (s *Service) DisableVideoHandler(c *gin.Context) {
+	s.selfMedia(c, s.Log.WithName("DisableVideoHandler"), s.Store.Participant().DisableVideo)
+}
+
+func (s *Service) ShareScreenHandler(c *gin.Context) {
+	s.selfMedia(c, s.Log.WithName("ShareScreenHandler"), s.Store.Participant().ShareScreen)
+}
+
+func (s *Service) StopScreenShareHandler(c *gin.Context) {
+	s.selfMedia(c, s.Log.WithName("StopScreenShareHandler"), s.Store.Participant().StopScreenShare)
+}
+
+// selfMedia applies a media change to the caller's own participant and responds with their updated state.
+// The identity always comes from the authenticated session, never from the request.
+func (s *Service) selfMedia(c *gin.Context, log logr.Logger, action func(ctx context.Context, roomName, identity string) error) {
+	// Room lookup and caller resolution are the same as for host controls
+	roomName, identity, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	participants := s.Store.Participant()
+
+	// Only members currently in the room can change their media
+	if _, err := participants.GetParticipantInfo(ctx, roomName, identity); err != nil {
+		participantError(c, log, err)
+		return
+	}
+
+	if err := action(ctx, roomName, identity); err != nil {
+		participantError(c, log, err)
+		return
+	}
+
+	info, err := participants.GetParticipantInfo(ctx, roomName, identity)
+	if err != nil {
+		participantError(c, log, err)
+		return
+	}
+
+	log.Info("media state updated", "roomName", roomName, "identity", identity)
+	c.JSON(http.StatusOK, newParticipantView(info))
+}
+
+// participantError maps store errors from participant operations onto HTTP responses
+func participantError(c *gin.Context, log logr.Logger, err error) {
+	switch {
+	case errors.Is(err, store.ErrPresenterRequested):
+		log.Info("screen share awaits approval", "reason", err.Error())
+		c.JSON(http.StatusAccepted, gin.H{"status": store.AdmissionPending, "code": "WAITING_FOR_APPROVAL"})
+	case errors.Is(err, store.ErrNotFound):
+		log.Info("caller is not in the room", "reason", err.Error())
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not in this room", "code": "NOT_IN_ROOM"})
+	default:
+		hostError(c, log, err)
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/livekit/protocol/livekit"
+
+	"open-meet/pkg/store"
+	"open-meet/pkg/store/storetest"
+)
+
+func TestSelfMediaHandlers(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(*Service) gin.HandlerFunc
+		path    string
+		setup   func(t *testing.T, svc *Service, server *storetest.RoomService)
+		caller  string
+		want    int
+		code    string
+	}{
+		{
+			name:    "mute",
+			handler: func(s *Service) gin.HandlerFunc { return s.MuteSelfHandler },
+			path:    "/rooms/" + testRoom + "/me/mute",
+			caller:  testUser,
+			want:    http.StatusOK,
+		},
+		{
+			name:    "not in the room",
+			handler: func(s *Service) gin.HandlerFunc { return s.MuteSelfHandler },
+			path:    "/rooms/" + testRoom + "/me/mute",
+			caller:  "bob@example.com",
+			want:    http.StatusForbidden,
+			code:    "NOT_IN_ROOM",
+		},
+		{
+			name:    "unknown room",
+			handler: func(s *Service) gin.HandlerFunc { return s.MuteSelfHandler },
+			path:    "/rooms/retro/me/mute",
+			caller:  testUser,
+			want:    http.StatusNotFound,
+			code:    "ROOM_NOT_FOUND",
+		},
+		{
+			name:    "screen share awaiting approval",
+			handler: func(s *Service) gin.HandlerFunc { return s.ShareScreenHandler },
+			path:    "/rooms/" + testRoom + "/me/screen/start",
+			setup: func(t *testing.T, svc *Service, server *storetest.RoomService) {
+				if err := svc.Store.Host().SetScreenSharePolicy(context.Background(), testRoom, testHost, store.ScreenShareApproval); err != nil {
+					t.Fatal(err)
+				}
+			},
+			caller: testUser,
+			want:   http.StatusAccepted,
+			code:   "WAITING_FOR_APPROVAL",
+		},
+		{
+			name:    "camera the room withholds",
+			handler: func(s *Service) gin.HandlerFunc { return s.EnableVideoHandler },
+			path:    "/rooms/" + testRoom + "/me/video/enable",
+			setup: func(t *testing.T, svc *Service, server *storetest.RoomService) {
+				// As granted by a token issued under a microphone-only media policy
+				if _, err := server.UpdateParticipant(context.Background(), &livekit.UpdateParticipantRequest{
+					Room:     testRoom,
+					Identity: testUser,
+					Permission: &livekit.ParticipantPermission{
+						CanPublish:        true,
+						CanPublishSources: []livekit.TrackSource{livekit.TrackSource_MICROPHONE},
+					},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			caller: testUser,
+			want:   http.StatusForbidden,
+			code:   "FORBIDDEN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, server := newTestService(t, testUser)
+			if tt.setup != nil {
+				tt.setup(t, svc, server)
+			}
+			rec := serve(tt.handler(svc), http.MethodPost, "/rooms/:roomName/me/*action", tt.path, tt.caller, nil)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.code != "" {
+				if got := decode[map[string]any](t, rec)["code"]; got != tt.code {
+					t.Errorf("code = %v, want %s", got, tt.code)
+				}
+			}
+		})
+	}
+}
+
+func TestSelfMediaActsOnCaller(t *testing.T) {
+	svc, server := newTestService(t, testUser, "bob@example.com")
+	publish(server, testUser, livekit.TrackSource_MICROPHONE)
+	publish(server, "bob@example.com", livekit.TrackSource_MICROPHONE)
+
+	rec := serve(svc.MuteSelfHandler, http.MethodPost, "/rooms/:roomName/me/mute", "/rooms/"+testRoom+"/me/mute", testUser, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	view := decode[ParticipantView](t, rec)
+	if view.Identity != testUser || len(view.Tracks) != 1 || !view.Tracks[0].Muted {
+		t.Errorf("view = %+v, want %s with a muted microphone", view, testUser)
+	}
+
+	bob, err := svc.Store.Participant().GetParticipantInfo(context.Background(), testRoom, "bob@example.com")
+	if err != nil {
+		t.Fatalf("GetParticipantInfo: %v", err)
+	}
+	if bob.GetTracks()[0].GetMuted() {
+		t.Error("muting oneself muted someone else")
+	}
+}
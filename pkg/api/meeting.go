@@ -0,0 +1,323 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"open-meet/pkg/store"
+	"open-meet/pkg/util"
+)
+
+type MeetingRequest struct {
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	StartAt     time.Time   `json:"start_at" binding:"required"`
+	EndAt       time.Time   `json:"end_at" binding:"required"`
+	Timezone    string      `json:"timezone"`
+	Invitees    []string    `json:"invitees"`
+	Recurrence  string      `json:"recurrence"` // RRULE, e.g. "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR"
+	ExDates     []time.Time `json:"exdates"`
+}
+
+func (r *MeetingRequest) input() store.MeetingInput {
+	return store.MeetingInput{
+		Title:       r.Title,
+		Description: r.Description,
+		StartAt:     r.StartAt,
+		EndAt:       r.EndAt,
+		Timezone:    r.Timezone,
+		Invitees:    r.Invitees,
+		Recurrence:  r.Recurrence,
+		ExDates:     r.ExDates,
+	}
+}
+
+// OccurrenceRequest changes a single occurrence of a series; omitted fields keep the series' values
+type OccurrenceRequest struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	StartAt     *time.Time `json:"start_at"`
+	EndAt       *time.Time `json:"end_at"`
+}
+
+func (r *OccurrenceRequest) input() store.OccurrenceInput {
+	input := store.OccurrenceInput{
+		Title:       r.Title,
+		Description: r.Description,
+	}
+	if r.StartAt != nil {
+		input.StartAt = *r.StartAt
+	}
+	if r.EndAt != nil {
+		input.EndAt = *r.EndAt
+	}
+	return input
+}
+
+const (
+	defaultOccurrences = 10
+	maxOccurrences     = 100
+)
+
+func (s *Service) ScheduleMeetingHandler(c *gin.Context) {
+	log := s.Log.WithName("ScheduleMeetingHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	req := new(MeetingRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: start_at and end_at are required", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	meeting, err := s.Store.Meetings().Schedule(c.Request.Context(), userEmail, req.input())
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("meeting scheduled", "code", meeting.Code, "host", userEmail, "startAt", meeting.StartAt)
+	s.pushCalendar(meeting.Code)
+	c.JSON(http.StatusCreated, meeting)
+}
+
+func (s *Service) ListMeetingsHandler(c *gin.Context) {
+	log := s.Log.WithName("ListMeetingsHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	meetings, err := s.Store.Meetings().List(c.Request.Context(), userEmail)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"meetings": meetings})
+}
+
+// PublicMeeting is what people who know a meeting's code but do not attend it see: enough
+// for the join page, without the host's and invitees' emails
+type PublicMeeting struct {
+	Code        string              `json:"code"`
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	StartAt     time.Time           `json:"start_at"`
+	EndAt       time.Time           `json:"end_at"`
+	Timezone    string              `json:"timezone"`
+	Status      store.MeetingStatus `json:"status"`
+	Recurrence  *store.Recurrence   `json:"recurrence,omitempty"`
+}
+
+func publicMeeting(meeting *store.Meeting) *PublicMeeting {
+	return &PublicMeeting{
+		Code:        meeting.Code,
+		Title:       meeting.Title,
+		Description: meeting.Description,
+		StartAt:     meeting.StartAt,
+		EndAt:       meeting.EndAt,
+		Timezone:    meeting.Timezone,
+		Status:      meeting.Status,
+		Recurrence:  meeting.Recurrence,
+	}
+}
+
+// GetMeetingHandler returns a meeting to its host and invitees. Anybody else who knows
+// its code, like the join link itself, gets the public view.
+func (s *Service) GetMeetingHandler(c *gin.Context) {
+	log := s.Log.WithName("GetMeetingHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	meeting, err := s.Store.Meetings().Get(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	if !meeting.Attends(userEmail) {
+		c.JSON(http.StatusOK, publicMeeting(meeting))
+		return
+	}
+	c.JSON(http.StatusOK, meeting)
+}
+
+func (s *Service) UpdateMeetingHandler(c *gin.Context) {
+	log := s.Log.WithName("UpdateMeetingHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	req := new(MeetingRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: start_at and end_at are required", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	meeting, err := s.Store.Meetings().Update(c.Request.Context(), c.Param("code"), userEmail, req.input())
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("meeting updated", "code", meeting.Code, "host", userEmail)
+	s.pushCalendar(meeting.Code)
+	c.JSON(http.StatusOK, meeting)
+}
+
+func (s *Service) CancelMeetingHandler(c *gin.Context) {
+	log := s.Log.WithName("CancelMeetingHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	meeting, err := s.Store.Meetings().Cancel(c.Request.Context(), c.Param("code"), userEmail)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("meeting cancelled", "code", meeting.Code, "host", userEmail)
+	s.pushCalendar(meeting.Code)
+	c.JSON(http.StatusOK, meeting)
+}
+
+// JoinMeetingHandler makes sure the meeting's room exists; the caller then requests a token for it
+func (s *Service) JoinMeetingHandler(c *gin.Context) {
+	log := s.Log.WithName("JoinMeetingHandler")
+
+	code := c.Param("code")
+	lkRoom, err := s.Store.Meetings().Join(c.Request.Context(), code)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("meeting joined", "code", code, "roomSid", lkRoom.GetSid())
+	c.JSON(http.StatusOK, gin.H{
+		"room": gin.H{
+			"name":             lkRoom.GetName(),
+			"sid":              lkRoom.GetSid(),
+			"num_participants": lkRoom.GetNumParticipants(),
+		},
+	})
+}
+
+// ListOccurrencesHandler expands a meeting into its next occurrences, all of which meet in the same room
+func (s *Service) ListOccurrencesHandler(c *gin.Context) {
+	log := s.Log.WithName("ListOccurrencesHandler")
+
+	after := time.Now()
+	if raw := c.Query("after"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "after must be an RFC 3339 time", "code": "INVALID_REQUEST"})
+			return
+		}
+		after = parsed
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultOccurrences)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number", "code": "INVALID_REQUEST"})
+		return
+	}
+	limit = min(limit, maxOccurrences)
+
+	occurrences, err := s.Store.Meetings().Occurrences(c.Request.Context(), c.Param("code"), after, limit)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"occurrences": occurrences})
+}
+
+// OverrideOccurrenceHandler changes one occurrence of a series, identified by its original start
+func (s *Service) OverrideOccurrenceHandler(c *gin.Context) {
+	log := s.Log.WithName("OverrideOccurrenceHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	recurrenceID, err := time.Parse(time.RFC3339, c.Param("recurrenceID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "occurrence must be identified by its RFC 3339 start", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	req := new(OccurrenceRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	meeting, err := s.Store.Meetings().OverrideOccurrence(c.Request.Context(), c.Param("code"), userEmail, recurrenceID, req.input())
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("occurrence updated", "code", meeting.Code, "recurrenceID", recurrenceID, "host", userEmail)
+	s.pushCalendar(meeting.Code)
+	c.JSON(http.StatusOK, meeting)
+}
+
+// CancelOccurrenceHandler calls off one occurrence of a series, identified by its original start
+func (s *Service) CancelOccurrenceHandler(c *gin.Context) {
+	log := s.Log.WithName("CancelOccurrenceHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	recurrenceID, err := time.Parse(time.RFC3339, c.Param("recurrenceID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "occurrence must be identified by its RFC 3339 start", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	meeting, err := s.Store.Meetings().CancelOccurrence(c.Request.Context(), c.Param("code"), userEmail, recurrenceID)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("occurrence cancelled", "code", meeting.Code, "recurrenceID", recurrenceID, "host", userEmail)
+	s.pushCalendar(meeting.Code)
+	c.JSON(http.StatusOK, meeting)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"open-meet/pkg/store"
+)
+
+// scheduleMeeting schedules a meeting hosted by testHost with testUser invited
+func scheduleMeeting(t *testing.T, svc *Service) *store.Meeting {
+	t.Helper()
+	start := time.Now().Add(time.Hour).Truncate(time.Minute)
+	rec := serve(svc.ScheduleMeetingHandler, http.MethodPost, "/meetings", "/meetings", testHost, MeetingRequest{
+		Title:    "Planning",
+		StartAt:  start,
+		EndAt:    start.Add(30 * time.Minute),
+		Invitees: []string{testUser},
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("schedule: status = %d: %s", rec.Code, rec.Body)
+	}
+	meeting := decode[store.Meeting](t, rec)
+	return &meeting
+}
+
+func TestScheduleMeetingHandlerValidation(t *testing.T) {
+	svc, _ := newTestService(t)
+	start := time.Now().Add(time.Hour)
+	tests := []struct {
+		name string
+		body any
+		want int
+	}{
+		{name: "valid", body: MeetingRequest{StartAt: start, EndAt: start.Add(time.Hour)}, want: http.StatusCreated},
+		{name: "ends before it starts", body: MeetingRequest{StartAt: start, EndAt: start.Add(-time.Hour)}, want: http.StatusBadRequest},
+		{name: "unknown timezone", body: MeetingRequest{StartAt: start, EndAt: start.Add(time.Hour), Timezone: "Mars/Olympus"}, want: http.StatusBadRequest},
+		{name: "bad invitee", body: MeetingRequest{StartAt: start, EndAt: start.Add(time.Hour), Invitees: []string{"ada"}}, want: http.StatusBadRequest},
+		{name: "no times", body: map[string]string{"title": "Planning"}, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := serve(svc.ScheduleMeetingHandler, http.MethodPost, "/meetings", "/meetings", testHost, tt.body)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, rec.Code, tt.want, rec.Body)
+		}
+	}
+}
+
+func TestGetMeetingHandlerHidesAttendeesFromOthers(t *testing.T) {
+	svc, _ := newTestService(t)
+	meeting := scheduleMeeting(t, svc)
+
+	for _, tt := range []struct {
+		caller   string
+		attendee bool
+	}{
+		{caller: testHost, attendee: true},
+		{caller: testUser, attendee: true},
+		{caller: "mallory@example.com", attendee: false},
+	} {
+		rec := serve(svc.GetMeetingHandler, http.MethodGet, "/meetings/:code", "/meetings/"+meeting.Code, tt.caller, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("as %s: status = %d: %s", tt.caller, rec.Code, rec.Body)
+		}
+		body := decode[map[string]any](t, rec)
+		_, hasHost := body["host_email"]
+		_, hasInvitees := body["invitees"]
+		if hasHost != tt.attendee || hasInvitees != tt.attendee {
+			t.Errorf("as %s: host_email shown = %v, invitees shown = %v, want %v", tt.caller, hasHost, hasInvitees, tt.attendee)
+		}
+		if body["title"] != "Planning" {
+			t.Errorf("as %s: title = %v", tt.caller, body["title"])
+		}
+	}
+
+	rec := serve(svc.GetMeetingHandler, http.MethodGet, "/meetings/:code", "/meetings/unknown", testHost, nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown code: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestMeetingHandlersStatusCodes(t *testing.T) {
+	svc, _ := newTestService(t)
+	meeting := scheduleMeeting(t, svc)
+	path := "/meetings/" + meeting.Code
+	update := MeetingRequest{StartAt: meeting.StartAt, EndAt: meeting.EndAt.Add(time.Hour)}
+
+	steps := []struct {
+		name   string
+		rec    func() int
+		status int
+	}{
+		{name: "update by an invitee", status: http.StatusForbidden, rec: func() int {
+			return serve(svc.UpdateMeetingHandler, http.MethodPut, "/meetings/:code", path, testUser, update).Code
+		}},
+		{name: "update by the host", status: http.StatusOK, rec: func() int {
+			return serve(svc.UpdateMeetingHandler, http.MethodPut, "/meetings/:code", path, testHost, update).Code
+		}},
+		{name: "cancel by an invitee", status: http.StatusForbidden, rec: func() int {
+			return serve(svc.CancelMeetingHandler, http.MethodDelete, "/meetings/:code", path, testUser, nil).Code
+		}},
+		{name: "cancel by the host", status: http.StatusOK, rec: func() int {
+			return serve(svc.CancelMeetingHandler, http.MethodDelete, "/meetings/:code", path, testHost, nil).Code
+		}},
+		{name: "update once cancelled", status: http.StatusConflict, rec: func() int {
+			return serve(svc.UpdateMeetingHandler, http.MethodPut, "/meetings/:code", path, testHost, update).Code
+		}},
+		{name: "join once cancelled", status: http.StatusConflict, rec: func() int {
+			return serve(svc.JoinMeetingHandler, http.MethodPost, "/meetings/:code/join", path+"/join", testUser, nil).Code
+		}},
+	}
+	for _, step := range steps {
+		if got := step.rec(); got != step.status {
+			t.Errorf("%s: status = %d, want %d", step.name, got, step.status)
+		}
+	}
+}
+
+func TestCreateRoomHandlerLeavesNoRoomWhenScheduleFails(t *testing.T) {
+	svc, _ := newTestService(t)
+	rec := serve(svc.CreateRoomHandler, http.MethodPost, "/rooms", "/rooms", testHost, CreateRoomRequest{
+		Title:    "Planning",
+		Timezone: "Mars/Olympus",
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+	}
+
+	rooms, err := svc.Store.Room().List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	for _, room := range rooms {
+		if room.GetName() != testRoom {
+			t.Errorf("room %s was left behind", room.GetName())
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/go-logr/logr"
+
+	"open-meet/pkg/auth"
+	"open-meet/pkg/store"
 )
 
 type GoogleSignInResponse struct {
@@ -13,14 +17,13 @@ type GoogleSignInResponse struct {
 	SelectBy   string `json:"select_by"`
 }
 
-type GoogleClaims struct {
-	Email         string `json:"email"`
-	EmailVerified bool   `json:"email_verified"`
-	Name          string `json:"name"`
-	Picture       string `json:"picture"`
-	jwt.RegisteredClaims
+// RefreshTokenRequest carries the refresh token to rotate or revoke
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+// CallbackHandler verifies a Google Sign-In credential and signs the user in with the
+// service's own tokens, so the session outlives the one-hour Google token
 func (s *Service) CallbackHandler(c *gin.Context) {
 	log := s.Log.WithName("CallbackHandler")
 
@@ -37,31 +40,177 @@ func (s *Service) CallbackHandler(c *gin.Context) {
 		return
 	}
 
-	// Parse the JWT token without verification (already verified by middleware)
-	token, _, err := new(jwt.Parser).ParseUnverified(signInResponse.Credential, &GoogleClaims{})
+	// No middleware runs on /callback, so the credential is verified here
+	s.signIn(c, log, "google", auth.Credential{IDToken: signInResponse.Credential})
+}
+
+// ProviderCallbackRequest is what the client brings back from a provider's sign-in:
+// an ID token, or the authorization code the provider redirected with
+type ProviderCallbackRequest struct {
+	Credential   string `json:"credential"`
+	Code         string `json:"code"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// ListProvidersHandler lists the identity providers users can sign in with
+func (s *Service) ListProvidersHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": s.Providers.Names()})
+}
+
+// AuthorizeHandler returns the provider's sign-in page. The client picks the state
+// and checks it when the provider redirects back.
+func (s *Service) AuthorizeHandler(c *gin.Context) {
+	log := s.Log.WithName("AuthorizeHandler")
+
+	provider, found := s.Providers.Get(c.Param("provider"))
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown identity provider", "code": "NOT_FOUND"})
+		return
+	}
+	state := c.Query("state")
+	if state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state is required", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	url, err := provider.AuthCodeURL(c.Request.Context(), state)
 	if err != nil {
-		log.Error(err, "failed to parse token")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse token"})
+		log.Error(err, "failed to build authorization URL", "provider", provider.Name())
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider unavailable", "code": "PROVIDER_UNAVAILABLE"})
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"url": url})
+}
+
+// ProviderCallbackHandler verifies what the client got from signing in with the
+// provider and signs the user in with the service's own tokens
+func (s *Service) ProviderCallbackHandler(c *gin.Context) {
+	log := s.Log.WithName("ProviderCallbackHandler")
 
-	claims, ok := token.Claims.(*GoogleClaims)
-	if !ok {
-		log.Error(nil, "failed to parse claims")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse claims"})
+	var req ProviderCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_REQUEST"})
+		return
+	}
+	if req.Credential == "" && req.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "credential or code is required", "code": "INVALID_REQUEST"})
 		return
 	}
 
-	// Log successful login
-	log.Info("user information extracted",
-		"email", claims.Email,
-		"name", claims.Name)
-
-	c.JSON(http.StatusOK, gin.H{
-		"token":   signInResponse.Credential,
-		"type":    "Bearer",
-		"name":    claims.Name,
-		"email":   claims.Email,
-		"picture": claims.Picture,
+	s.signIn(c, log, c.Param("provider"), auth.Credential{
+		IDToken:      req.Credential,
+		Code:         req.Code,
+		CodeVerifier: req.CodeVerifier,
 	})
 }
+
+// signIn authenticates the credential with the named provider and starts a session
+func (s *Service) signIn(c *gin.Context, log logr.Logger, providerName string, credential auth.Credential) {
+	identity, err := s.Providers.Authenticate(c.Request.Context(), providerName, credential)
+	if errors.Is(err, auth.ErrUnknownProvider) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown identity provider", "code": "NOT_FOUND"})
+		return
+	}
+	if errors.Is(err, auth.ErrInvalidToken) {
+		log.Info("credential rejected", "provider", providerName, "reason", err.Error())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credential", "code": "UNAUTHORIZED"})
+		return
+	}
+	if err != nil {
+		log.Error(err, "failed to authenticate with identity provider", "provider", providerName)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider unavailable", "code": "PROVIDER_UNAVAILABLE"})
+		return
+	}
+
+	tokens, err := s.Store.Sessions().Start(c.Request.Context(), *identity)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	// Log successful login
+	log.Info("user signed in",
+		"provider", identity.Provider,
+		"email", identity.Email,
+		"name", identity.Name)
+
+	c.JSON(http.StatusOK, tokenResponse(tokens))
+}
+
+// RefreshTokenHandler trades a refresh token for a new access and refresh token.
+// The old refresh token stops working; presenting it again ends the session.
+func (s *Service) RefreshTokenHandler(c *gin.Context) {
+	log := s.Log.WithName("RefreshTokenHandler")
+
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_REQUEST"})
+		return
+	}
+
+	tokens, err := s.Store.Sessions().Refresh(c.Request.Context(), req.RefreshToken)
+	if errors.Is(err, store.ErrUnauthorized) {
+		log.Info("refresh rejected", "reason", err.Error())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token", "code": "UNAUTHORIZED"})
+		return
+	}
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(tokens))
+}
+
+// RevokeTokenHandler ends the session a refresh token belongs to. Unknown tokens
+// are accepted too, so the response tells nothing about which tokens exist.
+func (s *Service) RevokeTokenHandler(c *gin.Context) {
+	log := s.Log.WithName("RevokeTokenHandler")
+
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_REQUEST"})
+		return
+	}
+
+	if err := s.Store.Sessions().Revoke(c.Request.Context(), req.RefreshToken); err != nil {
+		hostError(c, log, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutHandler ends the caller's session, invalidating its access and refresh tokens
+func (s *Service) LogoutHandler(c *gin.Context) {
+	log := s.Log.WithName("LogoutHandler")
+
+	sessionID := c.GetString("session_id")
+	if sessionID == "" {
+		// Google tokens carry no session; there is nothing to end
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	if err := s.Store.Sessions().End(c.Request.Context(), sessionID); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("user signed out", "email", c.GetString("email"))
+	c.Status(http.StatusNoContent)
+}
+
+// tokenResponse is the body returned on sign-in and refresh
+func tokenResponse(tokens *store.TokenPair) gin.H {
+	return gin.H{
+		"token":              tokens.AccessToken,
+		"type":               "Bearer",
+		"expires_at":         tokens.AccessExpiresAt,
+		"refresh_token":      tokens.RefreshToken,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
+		"provider":           tokens.Provider,
+		"name":               tokens.Name,
+		"email":              tokens.Email,
+		"picture":            tokens.Picture,
+	}
+}
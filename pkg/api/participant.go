@@ -1,8 +1,10 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
+	"open-meet/pkg/store"
 	"open-meet/pkg/util"
 
 	"github.com/gin-gonic/gin"
@@ -10,6 +12,8 @@ import (
 
 type LiveKitTokenRequest struct {
 	RoomName string `json:"room_name" binding:"required"`
+	Hidden   bool   `json:"hidden"`
+	Invite   string `json:"invite"` // token from an invite link
 }
 
 func (s *Service) LiveKitTokenHandler(c *gin.Context) {
@@ -19,14 +23,14 @@ func (s *Service) LiveKitTokenHandler(c *gin.Context) {
 	if err := c.ShouldBindJSON(req); err != nil {
 		log.Error(err, "invalid request body")
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request: room_name and identity are required",
+			"error": "Invalid request: room_name is required",
 		})
 		return
 	}
 	if req.RoomName == "" {
-		log.Info("room name or identity is empty")
+		log.Info("room name is empty")
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request: room_name and identity cannot be empty",
+			"error": "Invalid request: room_name cannot be empty",
 		})
 		return
 	}
@@ -53,8 +57,58 @@ func (s *Service) LiveKitTokenHandler(c *gin.Context) {
 		return
 	}
 
-	// Generate token
-	token, err := s.Store.Participant().GenerateToken(roomCtx, req.RoomName, userEmail)
+	// Enforce room lock and waiting room. An invite grants its role and skips the
+	// waiting room, within its constraints.
+	var (
+		invite *store.Invite
+		status store.AdmissionStatus
+	)
+	if req.Invite != "" {
+		invite, status, err = s.Store.Invites().Redeem(roomCtx, req.RoomName, userEmail, req.Invite)
+	} else {
+		status, err = s.Store.Host().RequestJoin(roomCtx, req.RoomName, userEmail)
+	}
+	if errors.Is(err, store.ErrRoomLocked) {
+		log.Info("room is locked", "roomName", req.RoomName, "identity", userEmail)
+		c.JSON(http.StatusForbidden, gin.H{"error": "room is locked", "code": "ROOM_LOCKED"})
+		return
+	}
+	if errors.Is(err, store.ErrUnauthorized) {
+		log.Info("invite refused", "roomName", req.RoomName, "identity", userEmail, "reason", err.Error())
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "INVALID_INVITE"})
+		return
+	}
+	if err != nil {
+		log.Error(err, "failed to check admission", "roomName", req.RoomName, "identity", userEmail)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	if invite != nil {
+		log.Info("invite redeemed", "roomName", req.RoomName, "identity", userEmail, "invite", invite.ID, "role", invite.Role)
+	}
+	switch status {
+	case store.AdmissionPending:
+		log.Info("waiting for host approval", "roomName", req.RoomName, "identity", userEmail)
+		c.JSON(http.StatusAccepted, gin.H{"status": status, "code": "WAITING_FOR_APPROVAL"})
+		return
+	case store.AdmissionDenied:
+		log.Info("admission denied", "roomName", req.RoomName, "identity", userEmail)
+		c.JSON(http.StatusForbidden, gin.H{"error": "host denied admission", "code": "ADMISSION_DENIED"})
+		return
+	}
+
+	// Generate token with the display name and avatar from the identity provider
+	name, picture := util.GetUserProfileFromContext(c)
+	token, err := s.Store.Participant().GenerateToken(roomCtx, req.RoomName, userEmail, store.TokenOptions{
+		Name:   name,
+		Avatar: picture,
+		Hidden: req.Hidden,
+	})
+	if errors.Is(err, store.ErrUnauthorized) {
+		log.Info("token request forbidden", "roomName", req.RoomName, "identity", userEmail, "reason", err.Error())
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "FORBIDDEN"})
+		return
+	}
 	if err != nil {
 		log.Error(err, "failed to generate token", "roomName", req.RoomName, "identity", userEmail)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"open-meet/pkg/store"
+	"open-meet/pkg/util"
+)
+
+type StartRecordingRequest struct {
+	TrackSid string `json:"track_sid"` // record a single track instead of the whole room
+}
+
+func (s *Service) StartRecordingHandler(c *gin.Context) {
+	log := s.Log.WithName("StartRecordingHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(StartRecordingRequest)
+	if err := c.ShouldBindJSON(req); err != nil && !errors.Is(err, io.EOF) {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	recording, err := s.Store.Recorder().StartRecording(c.Request.Context(), roomName, hostEmail, store.RecordingOptions{
+		TrackSid: req.TrackSid,
+	})
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("recording started", "roomName", roomName, "host", hostEmail, "recordingID", recording.ID, "kind", recording.Kind)
+	c.JSON(http.StatusCreated, recording)
+}
+
+func (s *Service) StopRecordingHandler(c *gin.Context) {
+	log := s.Log.WithName("StopRecordingHandler")
+
+	roomName, hostEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	recordingID := c.Param("recordingID")
+	recording, err := s.Store.Recorder().StopRecording(c.Request.Context(), roomName, hostEmail, recordingID)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("recording stopped", "roomName", roomName, "host", hostEmail, "recordingID", recording.ID, "status", recording.Status)
+	c.JSON(http.StatusOK, recording)
+}
+
+func (s *Service) ListRoomRecordingsHandler(c *gin.Context) {
+	log := s.Log.WithName("ListRoomRecordingsHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	// Recordings outlive their room, so unlike host controls this does not require the room to exist
+	roomName := c.Param("roomName")
+	recordings, err := s.Store.Recorder().ListRoomRecordings(c.Request.Context(), roomName, userEmail)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "recordings": recordings})
+}
+
+func (s *Service) ListMyRecordingsHandler(c *gin.Context) {
+	log := s.Log.WithName("ListMyRecordingsHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	recordings, err := s.Store.Recorder().ListUserRecordings(c.Request.Context(), userEmail)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recordings": recordings})
+}
+
+func (s *Service) GetRecordingHandler(c *gin.Context) {
+	log := s.Log.WithName("GetRecordingHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	recording, err := s.Store.Recorder().GetRecording(c.Request.Context(), c.Param("recordingID"), userEmail)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recording)
+}
+
+func (s *Service) DeleteRecordingHandler(c *gin.Context) {
+	log := s.Log.WithName("DeleteRecordingHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	recordingID := c.Param("recordingID")
+	if err := s.Store.Recorder().DeleteRecording(c.Request.Context(), recordingID, userEmail); err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	log.Info("recording deleted", "recordingID", recordingID, "by", userEmail)
+	c.JSON(http.StatusOK, gin.H{"id": recordingID, "deleted": true})
+}
+
+func (s *Service) RecordingLinkHandler(c *gin.Context) {
+	log := s.Log.WithName("RecordingLinkHandler")
+
+	userEmail, err := util.GetUserEmailFromContext(c)
+	if err != nil {
+		log.Error(err, "failed to get user email from context")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	link, err := s.Store.Recorder().DownloadLink(c.Request.Context(), c.Param("recordingID"), userEmail)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"url":        s.publicURL(link.Path),
+		"expires_at": link.ExpiresAt,
+	})
+}
+
+// DownloadRecordingHandler serves a recording to anybody holding a valid signed link
+func (s *Service) DownloadRecordingHandler(c *gin.Context) {
+	log := s.Log.WithName("DownloadRecordingHandler")
+
+	download, err := s.Store.Recorder().ResolveDownload(c.Request.Context(), c.Param("recordingID"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	if download.URL != "" {
+		c.Redirect(http.StatusFound, download.URL)
+		return
+	}
+	c.FileAttachment(download.Path, download.Recording.FileName)
+}
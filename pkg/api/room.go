@@ -3,12 +3,15 @@ package api
 import (
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"open-meet/pkg/store"
 	"open-meet/pkg/util"
 )
 
@@ -23,7 +26,15 @@ func (s *Service) CreateRoomHandler(c *gin.Context) {
 		return
 	}
 
+	req := new(CreateRoomRequest)
+	if err := c.ShouldBindJSON(req); err != nil && !errors.Is(err, io.EOF) {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "code": "INVALID_REQUEST"})
+		return
+	}
+
 	roomName := generateRoomName()
+
 	lkRoom, err := s.Store.Room().Create(c.Request.Context(), roomName, userEmail)
 	if err != nil {
 		log.Error(err, "failed to create room", "roomName", roomName, "creator", userEmail)
@@ -31,15 +42,35 @@ func (s *Service) CreateRoomHandler(c *gin.Context) {
 		return
 	}
 
+	// Rooms created with a schedule also get a calendar entry; a schedule that cannot be
+	// saved takes the room down with it, so no unscheduled room is left behind
+	var meeting *store.Meeting
+	if req.scheduled() {
+		meeting, err = s.Store.Meetings().ScheduleRoom(c.Request.Context(), roomName, userEmail, req.input())
+		if err != nil {
+			if deleteErr := s.Store.Room().Delete(c.Request.Context(), roomName); deleteErr != nil {
+				log.Error(deleteErr, "failed to delete room after scheduling failed", "roomName", roomName)
+			}
+			hostError(c, log, err)
+			return
+		}
+	}
+
 	log.Info("room created", "roomID", lkRoom.GetSid(), "roomName", lkRoom.GetName(), "creator", userEmail)
 
-	c.JSON(http.StatusCreated, &CreateRoomResponse{
+	resp := &CreateRoomResponse{
 		Room: &Room{
 			Name:      lkRoom.GetName(),
 			CreatedBy: userEmail,
 			CreatedAt: time.Now(),
 		},
-	})
+		Meeting: meeting,
+	}
+	if meeting != nil {
+		resp.CalendarURL = s.publicURL("/rooms/" + roomName + "/calendar.ics")
+		s.pushCalendar(meeting.Code)
+	}
+	c.JSON(http.StatusCreated, resp)
 }
 
 func (s *Service) GetRoomHandler(c *gin.Context) {
@@ -68,7 +99,12 @@ func (s *Service) GetRoomHandler(c *gin.Context) {
 	log.Info("room accessed", "roomName", lkRoom.GetName(), "numParticipants", lkRoom.NumParticipants)
 
 	hostMetadata := make(map[string]any)
-	host, found := s.Store.Room().GetRoomHost(lkRoom.GetName())
+	host, found, err := s.Store.Room().GetRoomHost(c.Request.Context(), lkRoom.GetName())
+	if err != nil {
+		log.Error(err, "failed to get room host")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
 	if !found {
 		log.Info("host not found")
 	} else {
@@ -105,6 +141,41 @@ type Room struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// CreateRoomRequest optionally schedules the new room; an empty body creates an ad-hoc room
+type CreateRoomRequest struct {
+	Title      string     `json:"title"`
+	StartAt    *time.Time `json:"start_at"` // defaults to now
+	EndAt      *time.Time `json:"end_at"`   // defaults to one hour after the start
+	Timezone   string     `json:"timezone"`
+	Attendees  []string   `json:"attendees"`
+	Recurrence string     `json:"recurrence"` // RRULE; every occurrence reuses this room
+}
+
+func (r *CreateRoomRequest) scheduled() bool {
+	return r.Title != "" || r.StartAt != nil || r.EndAt != nil || len(r.Attendees) > 0 || r.Recurrence != ""
+}
+
+func (r *CreateRoomRequest) input() store.MeetingInput {
+	start := time.Now()
+	if r.StartAt != nil {
+		start = *r.StartAt
+	}
+	end := start.Add(time.Hour)
+	if r.EndAt != nil {
+		end = *r.EndAt
+	}
+	return store.MeetingInput{
+		Title:      r.Title,
+		StartAt:    start,
+		EndAt:      end,
+		Timezone:   r.Timezone,
+		Invitees:   r.Attendees,
+		Recurrence: r.Recurrence,
+	}
+}
+
 type CreateRoomResponse struct {
-	Room *Room `json:"room"`
+	Room        *Room          `json:"room"`
+	Meeting     *store.Meeting `json:"meeting,omitempty"`
+	CalendarURL string         `json:"calendar_url,omitempty"`
 }
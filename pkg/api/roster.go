@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"open-meet/pkg/store"
+)
+
+const (
+	defaultRosterPageSize = 50
+	maxRosterPageSize     = 200
+)
+
+type ConnectionQualityRequest struct {
+	Quality string `json:"quality" binding:"required"` // POOR, GOOD, EXCELLENT or LOST
+}
+
+// ListParticipantsHandler returns a page of the room's roster to people in the room and its hosts
+func (s *Service) ListParticipantsHandler(c *gin.Context) {
+	log := s.Log.WithName("ListParticipantsHandler")
+
+	roomName, userEmail, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultRosterPageSize)))
+	if err != nil || limit <= 0 || limit > maxRosterPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxRosterPageSize), "code": "INVALID_REQUEST"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative number", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	role, err := s.Store.Host().GetRole(ctx, roomName, userEmail)
+	if err != nil {
+		hostError(c, log, err)
+		return
+	}
+	if !role.Outranks(store.RoleParticipant) {
+		_, err := s.Store.Participant().GetParticipantInfo(ctx, roomName, userEmail)
+		if err != nil {
+			participantError(c, log, err)
+			return
+		}
+	}
+
+	roster, err := s.Store.Participant().Roster(ctx, roomName)
+	if errors.Is(err, store.ErrNotFound) {
+		// The LiveKit room has not started yet or is already over
+		roster = nil
+	} else if err != nil {
+		hostError(c, log, err)
+		return
+	}
+
+	page := []store.RosterEntry{}
+	if offset < len(roster) {
+		page = roster[offset:min(offset+limit, len(roster))]
+	}
+
+	resp := gin.H{"room": roomName, "participants": page, "total": len(roster)}
+	if offset+len(page) < len(roster) {
+		resp["next_offset"] = offset + len(page)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ConnectionQualityHandler stores the connection quality reported by the caller's client
+func (s *Service) ConnectionQualityHandler(c *gin.Context) {
+	log := s.Log.WithName("ConnectionQualityHandler")
+
+	roomName, identity, ok := s.hostRequest(c, log)
+	if !ok {
+		return
+	}
+
+	req := new(ConnectionQualityRequest)
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Error(err, "invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: quality is required", "code": "INVALID_REQUEST"})
+		return
+	}
+
+	if err := s.Store.Participant().ReportConnectionQuality(c.Request.Context(), roomName, identity, req.Quality); err != nil {
+		participantError(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"room": roomName, "identity": identity, "connection_quality": req.Quality})
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+type rosterPage struct {
+	Participants []struct {
+		Identity string `json:"identity"`
+	} `json:"participants"`
+	Total      int  `json:"total"`
+	NextOffset *int `json:"next_offset"`
+}
+
+func TestListParticipantsPagination(t *testing.T) {
+	svc, _ := newTestService(t, testUser, "bob@example.com")
+	// Nobody has a join time in the fake, so the roster is ordered by identity
+	tests := []struct {
+		query string
+		want  []string
+		next  int // 0 for the last page
+	}{
+		{query: "?limit=2", want: []string{testUser, "bob@example.com"}, next: 2},
+		{query: "?limit=2&offset=2", want: []string{testHost}},
+		{query: "?offset=3", want: []string{}},
+		{query: "?offset=50&limit=10", want: []string{}},
+	}
+	for _, tt := range tests {
+		rec := serve(svc.ListParticipantsHandler, http.MethodGet, "/rooms/:roomName/participants", "/rooms/"+testRoom+"/participants"+tt.query, testUser, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d: %s", tt.query, rec.Code, rec.Body)
+		}
+		page := decode[rosterPage](t, rec)
+		if page.Participants == nil {
+			t.Errorf("%s: participants = null, want a list", tt.query)
+		}
+		var got []string
+		for _, p := range page.Participants {
+			got = append(got, p.Identity)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: page = %v, want %v", tt.query, got, tt.want)
+		}
+		if page.Total != 3 {
+			t.Errorf("%s: total = %d, want 3", tt.query, page.Total)
+		}
+		switch {
+		case tt.next == 0 && page.NextOffset != nil:
+			t.Errorf("%s: next_offset = %d on the last page", tt.query, *page.NextOffset)
+		case tt.next != 0 && (page.NextOffset == nil || *page.NextOffset != tt.next):
+			t.Errorf("%s: next_offset = %v, want %d", tt.query, page.NextOffset, tt.next)
+		}
+	}
+}
+
+func TestListParticipantsRejectsBadCursor(t *testing.T) {
+	svc, _ := newTestService(t, testUser)
+	for _, query := range []string{"?offset=abc", "?offset=-1", "?limit=0", "?limit=201", "?limit=ten"} {
+		rec := serve(svc.ListParticipantsHandler, http.MethodGet, "/rooms/:roomName/participants", "/rooms/"+testRoom+"/participants"+query, testUser, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestListParticipantsOnlyForPeopleInTheRoom(t *testing.T) {
+	svc, _ := newTestService(t, testUser)
+	for _, tt := range []struct {
+		caller string
+		want   int
+	}{
+		{caller: testUser, want: http.StatusOK},
+		{caller: testHost, want: http.StatusOK},
+		{caller: "mallory@example.com", want: http.StatusForbidden},
+	} {
+		rec := serve(svc.ListParticipantsHandler, http.MethodGet, "/rooms/:roomName/participants", "/rooms/"+testRoom+"/participants", tt.caller, nil)
+		if rec.Code != tt.want {
+			t.Errorf("as %s: status = %d, want %d", tt.caller, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+)
+
+// LiveKitWebhookHandler receives LiveKit server events and keeps the store in sync with them
+func (s *Service) LiveKitWebhookHandler(c *gin.Context) {
+	log := s.Log.WithName("LiveKitWebhookHandler")
+
+	// Verify the event was signed with our API key and secret
+	provider := auth.NewSimpleKeyProvider(s.Config.LiveKitAPIKey, s.Config.LiveKitAPISecret)
+	event, err := webhook.ReceiveWebhookEvent(c.Request, provider)
+	if err != nil {
+		log.Error(err, "failed to verify webhook")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid webhook signature", "code": "INVALID_WEBHOOK"})
+		return
+	}
+
+	log = log.WithValues("event", event.GetEvent(), "eventID", event.GetId(), "roomName", event.GetRoom().GetName())
+
+	if err := s.handleWebhookEvent(c.Request.Context(), log, event); err != nil {
+		log.Error(err, "failed to handle webhook event")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error", "code": "INTERNAL_ERROR"})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (s *Service) handleWebhookEvent(ctx context.Context, log logr.Logger, event *livekit.WebhookEvent) error {
+	roomName := event.GetRoom().GetName()
+	at := time.Unix(event.GetCreatedAt(), 0)
+	if event.GetCreatedAt() == 0 {
+		at = time.Now()
+	}
+
+	switch event.GetEvent() {
+	case webhook.EventRoomFinished:
+		// The room record outlives the session: scheduled meetings reuse the room with
+		// its settings and roles, so only who was there and who ran it are forgotten
+		log.Info("room finished, clearing presence and host mapping")
+		if err := s.Store.Registry().ClearPresence(ctx, roomName); err != nil {
+			return err
+		}
+		return s.Store.Registry().ClearHost(ctx, roomName)
+
+	case webhook.EventParticipantJoined:
+		p := event.GetParticipant()
+		// Hidden observers are not in the room as far as others, or succession, are concerned
+		if p.GetKind() != livekit.ParticipantInfo_STANDARD || p.GetPermission().GetHidden() {
+			return nil
+		}
+		log.Info("participant joined", "identity", p.GetIdentity())
+		return s.Store.Registry().RecordJoin(ctx, roomName, p.GetIdentity(), at)
+
+	case webhook.EventParticipantConnectionAborted:
+		// A dropped connection is usually followed by a reconnect, so the host keeps the room
+		p := event.GetParticipant()
+		if p.GetKind() != livekit.ParticipantInfo_STANDARD || p.GetPermission().GetHidden() {
+			return nil
+		}
+		log.Info("participant connection aborted", "identity", p.GetIdentity())
+		return s.Store.Registry().RecordLeave(ctx, roomName, p.GetIdentity(), at)
+
+	case webhook.EventParticipantLeft:
+		p := event.GetParticipant()
+		// Hidden observers never joined as far as the store knows, so they cannot hand off the room either
+		if p.GetKind() != livekit.ParticipantInfo_STANDARD || p.GetPermission().GetHidden() {
+			return nil
+		}
+		log.Info("participant left", "identity", p.GetIdentity())
+		if err := s.Store.Registry().RecordLeave(ctx, roomName, p.GetIdentity(), at); err != nil {
+			return err
+		}
+
+		newHost, err := s.Store.Host().HandOff(ctx, roomName, p.GetIdentity())
+		if err != nil {
+			return err
+		}
+		if newHost != "" {
+			log.Info("host handed off", "from", p.GetIdentity(), "to", newHost)
+		}
+		return nil
+
+	case webhook.EventEgressStarted, webhook.EventEgressUpdated, webhook.EventEgressEnded:
+		info := event.GetEgressInfo()
+		recording, err := s.Store.Recorder().HandleEgressUpdate(ctx, info)
+		if err != nil {
+			return err
+		}
+		if recording == nil {
+			log.V(1).Info("ignoring egress the service did not start", "egressID", info.GetEgressId())
+			return nil
+		}
+		log.Info("recording updated", "recordingID", recording.ID, "status", recording.Status)
+		return nil
+
+	default:
+		log.V(1).Info("ignoring webhook event")
+		return nil
+	}
+}
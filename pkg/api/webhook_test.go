@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+)
+
+func TestHiddenParticipantLeavingKeepsHost(t *testing.T) {
+	svc, _ := newTestService(t, testUser)
+	ctx := context.Background()
+	if err := svc.Store.Registry().RecordJoin(ctx, testRoom, testUser, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The host observes hidden, e.g. from a recorder, and that connection goes away
+	event := &livekit.WebhookEvent{
+		Event: webhook.EventParticipantLeft,
+		Room:  &livekit.Room{Name: testRoom},
+		Participant: &livekit.ParticipantInfo{
+			Identity:   testHost,
+			Permission: &livekit.ParticipantPermission{Hidden: true},
+		},
+	}
+	if err := svc.handleWebhookEvent(ctx, logr.Discard(), event); err != nil {
+		t.Fatalf("handleWebhookEvent: %v", err)
+	}
+
+	if host, _, err := svc.Store.Room().GetRoomHost(ctx, testRoom); err != nil || host != testHost {
+		t.Errorf("host = %q, %v; want %s", host, err, testHost)
+	}
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultKeyTTL applies when the key source gives no max-age
+	DefaultKeyTTL = time.Hour
+	// MinKeyTTL keeps an uncacheable key set from being fetched on every token
+	MinKeyTTL = time.Minute
+	// MaxKeyTTL bounds how long a rotated-out key keeps verifying tokens, plus StaleKeyGrace
+	// while the source cannot be reached
+	MaxKeyTTL = 24 * time.Hour
+	// StaleKeyGrace is how long expired keys keep verifying while the source cannot be reached
+	StaleKeyGrace = time.Hour
+
+	// keyRefreshTimeout bounds a fetch, whoever started it
+	keyRefreshTimeout = 30 * time.Second
+	// keyRetryInterval spaces out fetches after one failed
+	keyRetryInterval = 10 * time.Second
+)
+
+// KeyCache holds an issuer's signing keys between fetches. Once three quarters of
+// their lifetime have passed, lookups still answer from the cache while a refresh
+// runs in the background, so verification only waits on the network when the keys
+// have expired or a token names a key that is not cached yet. Concurrent lookups
+// share a single fetch.
+type KeyCache struct {
+	source KeySource
+	now    func() time.Time
+
+	mu          sync.Mutex
+	keys        KeySet
+	fetchedAt   time.Time
+	expiresAt   time.Time
+	attemptedAt time.Time // when the last fetch, successful or not, finished
+	lastErr     error     // error of the last fetch
+	fetch       *keyFetch // the fetch in flight, if any
+}
+
+// keyFetch is a fetch that lookups can wait on
+type keyFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// NewKeyCache creates an empty cache in front of source
+func NewKeyCache(source KeySource) *KeyCache {
+	return &KeyCache{source: source, now: time.Now}
+}
+
+// WithClock makes the cache tell time with now, for tests
+func (c *KeyCache) WithClock(now func() time.Time) *KeyCache {
+	c.now = now
+	return c
+}
+
+// Key returns the public key with the given ID
+func (c *KeyCache) Key(ctx context.Context, kid string) (any, error) {
+	c.mu.Lock()
+	now := c.now()
+	key, cached := c.keys[kid]
+	fresh := now.Before(c.expiresAt)
+	if fresh && cached {
+		if !now.Before(c.refreshAt()) && c.fetch == nil {
+			c.startFetch()
+		}
+		c.mu.Unlock()
+		return key, nil
+	}
+	// An unknown key ID means the issuer rotated keys; refetch, at most once per MinKeyTTL
+	rotated := !cached && !now.Before(c.fetchedAt.Add(MinKeyTTL))
+	c.mu.Unlock()
+
+	if !fresh || rotated {
+		err := c.refresh(ctx)
+
+		c.mu.Lock()
+		key, cached = c.keys[kid]
+		usable := c.now().Before(c.expiresAt.Add(StaleKeyGrace))
+		c.mu.Unlock()
+		if err != nil {
+			// Ride out a short outage with the keys we have, but not indefinitely
+			if cached && usable {
+				return key, nil
+			}
+			return nil, err
+		}
+	}
+	if !cached {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// refreshAt is when a background refresh starts; callers hold mu
+func (c *KeyCache) refreshAt() time.Time {
+	return c.fetchedAt.Add(c.expiresAt.Sub(c.fetchedAt) * 3 / 4)
+}
+
+// refresh waits for a fetch of the key set, joining one already in flight. Shortly
+// after a failed fetch, it fails the same way without calling the source again.
+func (c *KeyCache) refresh(ctx context.Context) error {
+	c.mu.Lock()
+	f := c.fetch
+	if f == nil {
+		if c.lastErr != nil && c.now().Before(c.attemptedAt.Add(keyRetryInterval)) {
+			err := c.lastErr
+			c.mu.Unlock()
+			return err
+		}
+		f = c.startFetch()
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startFetch fetches the key set in the background and replaces the cached one on
+// success. The fetch has its own deadline, so a caller giving up does not cancel it
+// for the others. Callers hold mu.
+func (c *KeyCache) startFetch() *keyFetch {
+	f := &keyFetch{done: make(chan struct{})}
+	c.fetch = f
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), keyRefreshTimeout)
+		defer cancel()
+		keys, ttl, err := c.source.FetchKeys(ctx)
+
+		c.mu.Lock()
+		now := c.now()
+		if err == nil {
+			c.keys = keys
+			c.fetchedAt = now
+			c.expiresAt = now.Add(boundKeyTTL(ttl))
+		}
+		c.attemptedAt = now
+		c.lastErr = err
+		c.fetch = nil
+		c.mu.Unlock()
+
+		f.err = err
+		close(f.done)
+	}()
+	return f
+}
+
+// boundKeyTTL applies the default and the bounds to a source's max-age
+func boundKeyTTL(ttl time.Duration) time.Duration {
+	switch {
+	case ttl == 0:
+		return DefaultKeyTTL
+	case ttl < MinKeyTTL:
+		return MinKeyTTL
+	case ttl > MaxKeyTTL:
+		return MaxKeyTTL
+	}
+	return ttl
+}
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"open-meet/pkg/scheduler"
+)
+
+var epoch = time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+
+// fakeKeySource serves whatever key set the test gives it and counts its fetches
+type fakeKeySource struct {
+	mu    sync.Mutex
+	keys  KeySet
+	ttl   time.Duration
+	err   error
+	calls int
+	gate  chan struct{} // when set, fetches wait until it is closed
+}
+
+func (s *fakeKeySource) FetchKeys(ctx context.Context) (KeySet, time.Duration, error) {
+	s.mu.Lock()
+	s.calls++
+	gate := s.gate
+	s.mu.Unlock()
+
+	if gate != nil {
+		<-gate
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	return s.keys, s.ttl, nil
+}
+
+func (s *fakeKeySource) set(keys KeySet, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys, s.err = keys, err
+}
+
+func (s *fakeKeySource) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func lookup(t *testing.T, cache *KeyCache, kid string) error {
+	t.Helper()
+	_, err := cache.Key(context.Background(), kid)
+	return err
+}
+
+// waitForCalls waits for the source's background fetches to reach want
+func waitForCalls(t *testing.T, source *fakeKeySource, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for source.Calls() < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("source fetched %d times, want %d", source.Calls(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestKeyCacheServesFromCacheUntilExpiry(t *testing.T) {
+	clock := scheduler.NewManualClock(epoch)
+	source := &fakeKeySource{keys: KeySet{"k1": &newECKey(t).PublicKey}}
+	cache := NewKeyCache(source).WithClock(clock.Now)
+
+	for range 3 {
+		if err := lookup(t, cache, "k1"); err != nil {
+			t.Fatalf("Key: %v", err)
+		}
+	}
+	if got := source.Calls(); got != 1 {
+		t.Errorf("fetched %d times, want 1", got)
+	}
+
+	// Past three quarters of the TTL the cached key answers while a refresh runs
+	clock.Advance(DefaultKeyTTL * 3 / 4)
+	if err := lookup(t, cache, "k1"); err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	waitForCalls(t, source, 2)
+}
+
+func TestKeyCacheRefetchesOnRotation(t *testing.T) {
+	clock := scheduler.NewManualClock(epoch)
+	source := &fakeKeySource{keys: KeySet{"k1": &newECKey(t).PublicKey}}
+	cache := NewKeyCache(source).WithClock(clock.Now)
+	if err := lookup(t, cache, "k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	source.set(KeySet{"k1": &newECKey(t).PublicKey, "k2": &newECKey(t).PublicKey}, nil)
+
+	// Unknown key IDs only trigger a fetch once per MinKeyTTL
+	if err := lookup(t, cache, "k2"); err == nil {
+		t.Fatal("found a key that was not fetched yet")
+	}
+	if got := source.Calls(); got != 1 {
+		t.Fatalf("fetched %d times within MinKeyTTL, want 1", got)
+	}
+
+	clock.Advance(MinKeyTTL)
+	if err := lookup(t, cache, "k2"); err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+	if got := source.Calls(); got != 2 {
+		t.Errorf("fetched %d times, want 2", got)
+	}
+}
+
+func TestKeyCacheStaleKeysWithinGrace(t *testing.T) {
+	clock := scheduler.NewManualClock(epoch)
+	source := &fakeKeySource{keys: KeySet{"k1": &newECKey(t).PublicKey}}
+	cache := NewKeyCache(source).WithClock(clock.Now)
+	if err := lookup(t, cache, "k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	source.set(nil, errors.New("issuer unreachable"))
+
+	clock.Advance(DefaultKeyTTL + StaleKeyGrace/2)
+	if err := lookup(t, cache, "k1"); err != nil {
+		t.Errorf("expired key within grace: %v", err)
+	}
+
+	clock.Advance(StaleKeyGrace)
+	if err := lookup(t, cache, "k1"); err == nil {
+		t.Error("expired key verified past its grace period")
+	}
+}
+
+func TestKeyCacheSpacesOutFailedFetches(t *testing.T) {
+	clock := scheduler.NewManualClock(epoch)
+	source := &fakeKeySource{err: errors.New("issuer unreachable")}
+	cache := NewKeyCache(source).WithClock(clock.Now)
+
+	for range 3 {
+		if err := lookup(t, cache, "k1"); err == nil {
+			t.Fatal("lookup succeeded without keys")
+		}
+	}
+	if got := source.Calls(); got != 1 {
+		t.Fatalf("fetched %d times right after a failure, want 1", got)
+	}
+
+	clock.Advance(keyRetryInterval)
+	source.set(KeySet{"k1": &newECKey(t).PublicKey}, nil)
+	if err := lookup(t, cache, "k1"); err != nil {
+		t.Errorf("Key after the retry interval: %v", err)
+	}
+}
+
+func TestKeyCacheSharesOneFetch(t *testing.T) {
+	source := &fakeKeySource{keys: KeySet{"k1": &newECKey(t).PublicKey}, gate: make(chan struct{})}
+	cache := NewKeyCache(source)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Go(func() {
+			_, err := cache.Key(context.Background(), "k1")
+			errs <- err
+		})
+	}
+	waitForCalls(t, source, 1)
+	time.Sleep(10 * time.Millisecond)
+	close(source.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Key: %v", err)
+		}
+	}
+	if got := source.Calls(); got != 1 {
+		t.Errorf("fetched %d times for concurrent lookups, want 1", got)
+	}
+}
+
+func TestBoundKeyTTL(t *testing.T) {
+	tests := []struct {
+		ttl, want time.Duration
+	}{
+		{ttl: 0, want: DefaultKeyTTL},
+		{ttl: time.Nanosecond, want: MinKeyTTL},
+		{ttl: 6 * time.Hour, want: 6 * time.Hour},
+		{ttl: 7 * 24 * time.Hour, want: MaxKeyTTL},
+	}
+	for _, tt := range tests {
+		if got := boundKeyTTL(tt.ttl); got != tt.want {
+			t.Errorf("boundKeyTTL(%s) = %s, want %s", tt.ttl, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// GitHubAPIURL is where GitHubProvider reads the signed-in user
+const GitHubAPIURL = "https://api.github.com"
+
+// GitHubProvider signs users in with GitHub OAuth. GitHub issues no ID tokens, so the
+// authorization code is exchanged and the user's profile and emails are read from the API.
+type GitHubProvider struct {
+	oauth  *oauth2.Config
+	apiURL string
+}
+
+// NewGitHubProvider creates a provider for the GitHub OAuth app identified by clientID
+func NewGitHubProvider(clientID, clientSecret, redirectURL string) *GitHubProvider {
+	return &GitHubProvider{
+		oauth: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint:     github.Endpoint,
+			Scopes:       []string{"read:user", "user:email"},
+		},
+		apiURL: GitHubAPIURL,
+	}
+}
+
+// WithEndpoints points the provider at a GitHub Enterprise server or a fake, for tests
+func (p *GitHubProvider) WithEndpoints(endpoint oauth2.Endpoint, apiURL string) *GitHubProvider {
+	p.oauth.Endpoint = endpoint
+	p.apiURL = apiURL
+	return p
+}
+
+// Name identifies the provider
+func (p *GitHubProvider) Name() string {
+	return "github"
+}
+
+// AuthCodeURL returns GitHub's authorization page
+func (p *GitHubProvider) AuthCodeURL(ctx context.Context, state string) (string, error) {
+	return p.oauth.AuthCodeURL(state), nil
+}
+
+// Authenticate exchanges the authorization code and reads who granted it.
+// The identity's email is the user's primary verified address.
+func (p *GitHubProvider) Authenticate(ctx context.Context, credential Credential) (*Identity, error) {
+	if credential.Code == "" {
+		return nil, fmt.Errorf("%w: github sign-in needs an authorization code", ErrInvalidToken)
+	}
+
+	var opts []oauth2.AuthCodeOption
+	if credential.CodeVerifier != "" {
+		opts = append(opts, oauth2.VerifierOption(credential.CodeVerifier))
+	}
+	token, err := p.oauth.Exchange(ctx, credential.Code, opts...)
+	if err != nil {
+		return nil, exchangeError("github", err)
+	}
+	client := p.oauth.Client(ctx, token)
+
+	var user struct {
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.get(ctx, client, "/user", &user); err != nil {
+		return nil, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.get(ctx, client, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	email := ""
+	for _, candidate := range emails {
+		if candidate.Verified && (candidate.Primary || email == "") {
+			email = candidate.Email
+		}
+	}
+	if email == "" {
+		return nil, fmt.Errorf("%w: github account %s has no verified email", ErrInvalidToken, user.Login)
+	}
+
+	name := user.Name
+	if name == "" {
+		name = user.Login
+	}
+	return &Identity{
+		Provider: "github",
+		Email:    email,
+		Name:     name,
+		Picture:  user.AvatarURL,
+	}, nil
+}
+
+// get decodes a GitHub API response into out
+func (p *GitHubProvider) get(ctx context.Context, client *http.Client, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request for github %s: %w", path, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call github %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to call github %s: status %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode github %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// oidcScopes are requested in the authorization code flow
+var oidcScopes = []string{"openid", "email", "profile"}
+
+// OIDCProvider signs users in with any OpenID Connect issuer, e.g. Google, Keycloak,
+// Okta or Azure AD. Endpoints and keys are discovered from the issuer on first use.
+type OIDCProvider struct {
+	name         string
+	issuer       string
+	issuers      []string // iss values tokens may carry; Google uses two
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	source       KeySource // overrides the discovered JWKS, e.g. with a fake issuer's keys
+	client       *http.Client
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config // nil until discovered
+	verifier *Verifier
+}
+
+// NewOIDCProvider creates a provider named name for the issuer at issuerURL. A nil
+// source verifies with the keys the issuer publishes.
+func NewOIDCProvider(name, issuerURL, clientID, clientSecret, redirectURL string, source KeySource) *OIDCProvider {
+	if name == "" {
+		name = "oidc"
+	}
+	issuer := strings.TrimSuffix(issuerURL, "/")
+	return &OIDCProvider{
+		name:         name,
+		issuer:       issuer,
+		issuers:      []string{issuer},
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		source:       source,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewGoogleProvider signs users in with Google. Its endpoints are well known, so
+// nothing is discovered; a nil source verifies with Google's published keys.
+func NewGoogleProvider(clientID, clientSecret, redirectURL string, source KeySource) *OIDCProvider {
+	p := NewOIDCProvider("google", GoogleIssuers[1], clientID, clientSecret, redirectURL, source)
+	p.issuers = GoogleIssuers
+	p.oauth = p.oauthConfig(google.Endpoint)
+	p.verifier = NewGoogleVerifier(clientID, source)
+	return p
+}
+
+// Name identifies the provider
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL returns the issuer's sign-in page
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state string) (string, error) {
+	oauth, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauth.AuthCodeURL(state), nil
+}
+
+// Authenticate verifies an ID token, or exchanges an authorization code for one first
+func (p *OIDCProvider) Authenticate(ctx context.Context, credential Credential) (*Identity, error) {
+	if credential.IDToken != "" {
+		return p.Verify(ctx, credential.IDToken)
+	}
+	if credential.Code == "" {
+		return nil, fmt.Errorf("%w: neither an ID token nor an authorization code was given", ErrInvalidToken)
+	}
+
+	oauth, _, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var opts []oauth2.AuthCodeOption
+	if credential.CodeVerifier != "" {
+		opts = append(opts, oauth2.VerifierOption(credential.CodeVerifier))
+	}
+	token, err := oauth.Exchange(ctx, credential.Code, opts...)
+	if err != nil {
+		return nil, exchangeError(p.name, err)
+	}
+	idToken, _ := token.Extra("id_token").(string)
+	if idToken == "" {
+		return nil, fmt.Errorf("%s returned no ID token", p.name)
+	}
+	return p.Verify(ctx, idToken)
+}
+
+// Issues reports whether iss is this provider's issuer
+func (p *OIDCProvider) Issues(iss string) bool {
+	return slices.Contains(p.issuers, iss)
+}
+
+// Verify checks an ID token the issuer signed for this application
+func (p *OIDCProvider) Verify(ctx context.Context, token string) (*Identity, error) {
+	_, verifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Email == "" {
+		return nil, fmt.Errorf("%w: token carries no email", ErrInvalidToken)
+	}
+	return &Identity{
+		Provider: p.name,
+		Email:    claims.Email,
+		Name:     claims.Name,
+		Picture:  claims.Picture,
+	}, nil
+}
+
+// discover fetches the issuer's metadata once; a failure is retried on the next call
+func (p *OIDCProvider) discover(ctx context.Context) (*oauth2.Config, *Verifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oauth != nil {
+		return p.oauth, p.verifier, nil
+	}
+
+	wellKnown := p.issuer + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request for %s: %w", wellKnown, err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover %s: %w", p.issuer, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("failed to discover %s: status %d", p.issuer, resp.StatusCode)
+	}
+
+	var metadata struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode metadata of %s: %w", p.issuer, err)
+	}
+	// The spec requires the metadata to name the issuer it was fetched from
+	if metadata.Issuer != p.issuer {
+		return nil, nil, fmt.Errorf("issuer %s publishes metadata for %q", p.issuer, metadata.Issuer)
+	}
+
+	source := p.source
+	if source == nil {
+		source = NewHTTPKeySource(metadata.JWKSURI)
+	}
+	p.oauth = p.oauthConfig(oauth2.Endpoint{AuthURL: metadata.AuthorizationEndpoint, TokenURL: metadata.TokenEndpoint})
+	p.verifier = NewVerifier(NewKeyCache(source), p.clientID, p.issuer)
+	return p.oauth, p.verifier, nil
+}
+
+func (p *OIDCProvider) oauthConfig(endpoint oauth2.Endpoint) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		RedirectURL:  p.redirectURL,
+		Endpoint:     endpoint,
+		Scopes:       oidcScopes,
+	}
+}
+
+// exchangeError marks codes the provider refused as invalid credentials, unlike
+// failures to reach it
+func exchangeError(provider string, err error) error {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return fmt.Errorf("%w: %s refused the authorization code: %v", ErrInvalidToken, provider, err)
+	}
+	return fmt.Errorf("failed to exchange authorization code with %s: %w", provider, err)
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"open-meet/pkg/config"
+)
+
+// ErrUnknownProvider is returned when no provider has the requested name
+var ErrUnknownProvider = errors.New("unknown identity provider")
+
+// Identity is a signed-in user as the rest of the service sees it, whichever
+// provider vouched for them. Providers only return identities with a verified email.
+type Identity struct {
+	Provider string `json:"idp,omitempty"`
+	Email    string `json:"email"`
+	Name     string `json:"name,omitempty"`
+	Picture  string `json:"picture,omitempty"`
+}
+
+// Credential is what the client brings back from signing in with a provider:
+// an ID token, or an authorization code to exchange
+type Credential struct {
+	IDToken      string
+	Code         string
+	CodeVerifier string // PKCE verifier, when the authorization request carried a challenge
+}
+
+// IdentityProvider signs users in with an external account
+type IdentityProvider interface {
+	// Name identifies the provider in routes and sessions, e.g. "google"
+	Name() string
+	// AuthCodeURL is where users sign in for the authorization code flow; state comes back with the code
+	AuthCodeURL(ctx context.Context, state string) (string, error)
+	// Authenticate verifies the credential and returns whose it is
+	Authenticate(ctx context.Context, credential Credential) (*Identity, error)
+}
+
+// IDTokenProvider is an identity provider whose ID tokens the service can verify on its own,
+// which lets clients send them as bearer tokens
+type IDTokenProvider interface {
+	IdentityProvider
+	// Issues reports whether the provider signs tokens with the issuer iss
+	Issues(iss string) bool
+	Verify(ctx context.Context, token string) (*Identity, error)
+}
+
+// Providers are the identity providers users may sign in with. Emails identify users
+// across providers, so each email domain is vouched for by one provider only: a provider
+// trusted for listed domains speaks for those alone, and the others for every domain
+// nobody lists.
+type Providers struct {
+	providers []IdentityProvider
+	domains   map[string][]string // map[provider name]email domains it is trusted for
+}
+
+// NewProviders creates a set of providers, in the order clients should offer them
+func NewProviders(providers ...IdentityProvider) *Providers {
+	return &Providers{providers: providers, domains: make(map[string][]string)}
+}
+
+// ProvidersFromConfig sets up Google and every other provider cfg configures
+func ProvidersFromConfig(cfg *config.Config) *Providers {
+	p := NewProviders(NewGoogleProvider(cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.AuthRedirectURL, nil))
+	p.TrustDomains("google", cfg.GoogleEmailDomains...)
+	if cfg.GitHubClientID != "" {
+		p.providers = append(p.providers, NewGitHubProvider(cfg.GitHubClientID, cfg.GitHubClientSecret, cfg.AuthRedirectURL))
+		p.TrustDomains("github", cfg.GitHubEmailDomains...)
+	}
+	if cfg.OIDCIssuerURL != "" {
+		oidc := NewOIDCProvider(cfg.OIDCProviderName, cfg.OIDCIssuerURL, cfg.OIDCClientID, cfg.OIDCClientSecret, cfg.AuthRedirectURL, nil)
+		p.providers = append(p.providers, oidc)
+		p.TrustDomains(oidc.Name(), cfg.OIDCEmailDomains...)
+	}
+	return p
+}
+
+// TrustDomains limits the provider named name to emails in domains and keeps every
+// other provider from vouching for them. Without domains the provider is left as is.
+func (p *Providers) TrustDomains(name string, domains ...string) *Providers {
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if domain != "" && !slices.Contains(p.domains[name], domain) {
+			p.domains[name] = append(p.domains[name], domain)
+		}
+	}
+	return p
+}
+
+// checkDomain refuses identities whose email domain provider is not trusted for
+func (p *Providers) checkDomain(provider string, identity *Identity) error {
+	at := strings.LastIndex(identity.Email, "@")
+	if at < 0 {
+		return fmt.Errorf("%w: %s returned no email domain", ErrInvalidToken, provider)
+	}
+	domain := strings.ToLower(identity.Email[at+1:])
+
+	if listed, ok := p.domains[provider]; ok {
+		if slices.Contains(listed, domain) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s is not trusted for %s emails", ErrInvalidToken, provider, domain)
+	}
+	for other, listed := range p.domains {
+		if slices.Contains(listed, domain) {
+			return fmt.Errorf("%w: %s emails must sign in with %s, not %s", ErrInvalidToken, domain, other, provider)
+		}
+	}
+	return nil
+}
+
+// Authenticate verifies the credential with the provider named name and returns whose it is,
+// as long as the provider is trusted for their email domain
+func (p *Providers) Authenticate(ctx context.Context, name string, credential Credential) (*Identity, error) {
+	provider, found := p.Get(name)
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	identity, err := provider.Authenticate(ctx, credential)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkDomain(name, identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// Get returns the provider with the given name
+func (p *Providers) Get(name string) (IdentityProvider, bool) {
+	i := slices.IndexFunc(p.providers, func(provider IdentityProvider) bool {
+		return provider.Name() == name
+	})
+	if i < 0 {
+		return nil, false
+	}
+	return p.providers[i], true
+}
+
+// Names lists the providers
+func (p *Providers) Names() []string {
+	names := make([]string, 0, len(p.providers))
+	for _, provider := range p.providers {
+		names = append(names, provider.Name())
+	}
+	return names
+}
+
+// Verify checks an ID token with the provider that issued it, and that the provider
+// is trusted for the email domain in it
+func (p *Providers) Verify(ctx context.Context, token string) (*Identity, error) {
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	for _, provider := range p.providers {
+		if verifier, ok := provider.(IDTokenProvider); ok && verifier.Issues(claims.Issuer) {
+			identity, err := verifier.Verify(ctx, token)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.checkDomain(verifier.Name(), identity); err != nil {
+				return nil, err
+			}
+			return identity, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no provider for issuer %q", ErrInvalidToken, claims.Issuer)
+}
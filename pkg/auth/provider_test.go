@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeProvider vouches for whatever email the credential or token carries
+type fakeProvider struct {
+	name   string
+	issuer string
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) AuthCodeURL(ctx context.Context, state string) (string, error) {
+	return "https://" + p.name + ".example/authorize?state=" + state, nil
+}
+
+func (p *fakeProvider) Authenticate(ctx context.Context, credential Credential) (*Identity, error) {
+	return &Identity{Provider: p.name, Email: credential.IDToken}, nil
+}
+
+func (p *fakeProvider) Issues(iss string) bool { return iss == p.issuer }
+
+func (p *fakeProvider) Verify(ctx context.Context, token string) (*Identity, error) {
+	claims := &Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return nil, err
+	}
+	return &Identity{Provider: p.name, Email: claims.Email}, nil
+}
+
+func fakeToken(t *testing.T, issuer, email string) string {
+	t.Helper()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		Email:            email,
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: issuer},
+	}).SignedString([]byte("test key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestProvidersTrustEmailDomains(t *testing.T) {
+	issuers := map[string]string{"google": "https://accounts.google.com", "oidc": "https://sso.corp.example"}
+	providers := NewProviders(
+		&fakeProvider{name: "google", issuer: issuers["google"]},
+		&fakeProvider{name: "oidc", issuer: issuers["oidc"]},
+	).TrustDomains("oidc", "corp.example", "@Labs.Example")
+
+	tests := []struct {
+		provider string
+		email    string
+		trusted  bool
+	}{
+		{provider: "google", email: "ada@gmail.com", trusted: true},
+		{provider: "oidc", email: "grace@corp.example", trusted: true},
+		{provider: "oidc", email: "alan@labs.example", trusted: true},
+		// The issuer's admin cannot speak for accounts outside its domains
+		{provider: "oidc", email: "ada@gmail.com", trusted: false},
+		// Nor can another provider speak for the domains the issuer owns
+		{provider: "google", email: "grace@corp.example", trusted: false},
+		{provider: "google", email: "Grace@CORP.example", trusted: false},
+	}
+	for _, tt := range tests {
+		identity, err := providers.Authenticate(context.Background(), tt.provider, Credential{IDToken: tt.email})
+		if tt.trusted && (err != nil || identity.Email != tt.email) {
+			t.Errorf("Authenticate %s with %s = %v, %v; want trusted", tt.email, tt.provider, identity, err)
+		}
+		if !tt.trusted && !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Authenticate %s with %s: err = %v, want ErrInvalidToken", tt.email, tt.provider, err)
+		}
+
+		_, err = providers.Verify(context.Background(), fakeToken(t, issuers[tt.provider], tt.email))
+		if tt.trusted && err != nil {
+			t.Errorf("Verify %s from %s: %v", tt.email, tt.provider, err)
+		}
+		if !tt.trusted && !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify %s from %s: err = %v, want ErrInvalidToken", tt.email, tt.provider, err)
+		}
+	}
+
+	if _, err := providers.Authenticate(context.Background(), "github", Credential{IDToken: "ada@gmail.com"}); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("Authenticate with an unknown provider: err = %v, want ErrUnknownProvider", err)
+	}
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// GoogleIssuers are the iss values Google signs ID tokens with
+var GoogleIssuers = []string{"accounts.google.com", "https://accounts.google.com"}
+
+// ErrInvalidToken is returned for every token that does not verify
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the ID token claims the service uses
+type Claims struct {
+	Email         string `json:"email"`
+	EmailVerified Bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+// Bool decodes a boolean claim sent either as a boolean, as Google does, or as the
+// string some providers send; a missing claim stays false
+type Bool bool
+
+func (v *Bool) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*v = Bool(b)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("claim is neither a boolean nor a string")
+	}
+	*v = s == "true"
+	return nil
+}
+
+// Verifier checks ID tokens against an issuer's cached signing keys, without
+// calling the issuer per token
+type Verifier struct {
+	keys     *KeyCache
+	issuers  []string
+	audience string
+	now      func() time.Time
+
+	// RequireVerifiedEmail rejects tokens whose email_verified claim is not true
+	RequireVerifiedEmail bool
+}
+
+// NewVerifier creates a verifier accepting tokens from any of issuers for audience
+func NewVerifier(keys *KeyCache, audience string, issuers ...string) *Verifier {
+	return &Verifier{
+		keys:                 keys,
+		issuers:              issuers,
+		audience:             audience,
+		now:                  time.Now,
+		RequireVerifiedEmail: true,
+	}
+}
+
+// NewGoogleVerifier verifies Google Sign-In credentials issued to clientID. A nil
+// source fetches Google's published keys.
+func NewGoogleVerifier(clientID string, source KeySource) *Verifier {
+	if source == nil {
+		source = NewHTTPKeySource(GoogleCertsURL)
+	}
+	return NewVerifier(NewKeyCache(source), clientID, GoogleIssuers...)
+}
+
+// WithClock makes the verifier check expiry against now, for tests
+func (v *Verifier) WithClock(now func() time.Time) *Verifier {
+	v.now = now
+	v.keys.WithClock(now)
+	return v
+}
+
+// Verify checks the token's signature, issuer, audience, expiry and, unless disabled,
+// that its email is verified
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	if v.audience == "" {
+		return nil, fmt.Errorf("verifier has no audience configured")
+	}
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithTimeFunc(v.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !slices.Contains(v.issuers, claims.Issuer) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if v.RequireVerifiedEmail && !bool(claims.EmailVerified) {
+		return nil, fmt.Errorf("%w: email not verified", ErrInvalidToken)
+	}
+	return claims, nil
+}
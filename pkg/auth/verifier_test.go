@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"open-meet/pkg/scheduler"
+)
+
+const testAudience = "client-id.apps.example.com"
+
+// testIssuer signs ID tokens with RSA keys and publishes them as a JWKS document
+type testIssuer struct {
+	t    *testing.T
+	keys map[string]*rsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T, kids ...string) *testIssuer {
+	t.Helper()
+	issuer := &testIssuer{t: t, keys: make(map[string]*rsa.PrivateKey)}
+	for _, kid := range kids {
+		issuer.addKey(kid)
+	}
+	return issuer
+}
+
+func (i *testIssuer) addKey(kid string) {
+	i.t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		i.t.Fatal(err)
+	}
+	i.keys[kid] = key
+}
+
+func (i *testIssuer) sign(kid string, claims Claims) string {
+	i.t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(i.keys[kid])
+	if err != nil {
+		i.t.Fatal(err)
+	}
+	return signed
+}
+
+func (i *testIssuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	for kid, key := range i.keys {
+		doc.Keys = append(doc.Keys, jsonWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(doc)
+}
+
+func validClaims(now time.Time) Claims {
+	return Claims{
+		Email:         "ada@example.com",
+		EmailVerified: true,
+		Name:          "Ada",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "https://accounts.google.com",
+			Subject:   "1234",
+			Audience:  jwt.ClaimStrings{testAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+func newTestVerifier(t *testing.T, issuer *testIssuer, clock *scheduler.ManualClock) *Verifier {
+	t.Helper()
+	server := httptest.NewServer(issuer)
+	t.Cleanup(server.Close)
+	return NewGoogleVerifier(testAudience, NewHTTPKeySource(server.URL)).WithClock(clock.Now)
+}
+
+func TestVerifyAcceptsSignedToken(t *testing.T) {
+	clock := scheduler.NewManualClock(epoch)
+	issuer := newTestIssuer(t, "k1")
+	verifier := newTestVerifier(t, issuer, clock)
+
+	claims, err := verifier.Verify(context.Background(), issuer.sign("k1", validClaims(clock.Now())))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Email != "ada@example.com" || claims.Name != "Ada" {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestVerifyFollowsKeyRotation(t *testing.T) {
+	clock := scheduler.NewManualClock(epoch)
+	issuer := newTestIssuer(t, "k1")
+	verifier := newTestVerifier(t, issuer, clock)
+	if _, err := verifier.Verify(context.Background(), issuer.sign("k1", validClaims(clock.Now()))); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	// The issuer starts signing with a new key while the old one is still cached
+	issuer.addKey("k2")
+	clock.Advance(MinKeyTTL)
+	if _, err := verifier.Verify(context.Background(), issuer.sign("k2", validClaims(clock.Now()))); err != nil {
+		t.Errorf("token signed with the rotated key: %v", err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	clock := scheduler.NewManualClock(epoch)
+	issuer := newTestIssuer(t, "k1")
+	forger := newTestIssuer(t, "k1", "k9")
+	verifier := newTestVerifier(t, issuer, clock)
+	now := clock.Now()
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "foreign signature", token: forger.sign("k1", validClaims(now))},
+		{name: "unknown key", token: forger.sign("k9", validClaims(now))},
+		{name: "wrong audience", token: issuer.sign("k1", with(validClaims(now), func(c *Claims) { c.Audience = jwt.ClaimStrings{"someone-else"} }))},
+		{name: "wrong issuer", token: issuer.sign("k1", with(validClaims(now), func(c *Claims) { c.Issuer = "https://evil.example.com" }))},
+		{name: "expired", token: issuer.sign("k1", with(validClaims(now), func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-2 * time.Minute)) }))},
+		{name: "no expiry", token: issuer.sign("k1", with(validClaims(now), func(c *Claims) { c.ExpiresAt = nil }))},
+		{name: "unverified email", token: issuer.sign("k1", with(validClaims(now), func(c *Claims) { c.EmailVerified = false }))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := verifier.Verify(context.Background(), tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("err = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func with(claims Claims, change func(*Claims)) Claims {
+	change(&claims)
+	return claims
+}
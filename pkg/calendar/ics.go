@@ -0,0 +1,160 @@
+// Package calendar renders meetings as RFC 5545 iCalendar data
+package calendar
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// iTIP methods (RFC 5546) used for the calendars the service hands out
+const (
+	// MethodPublish is used for subscribed feeds, which only inform
+	MethodPublish = "PUBLISH"
+	// MethodRequest invites the attendees to an event or updates it
+	MethodRequest = "REQUEST"
+	// MethodCancel calls an event off
+	MethodCancel = "CANCEL"
+)
+
+const (
+	prodID              = "-//open-meet//open-meet//EN"
+	dateTimeFormat      = "20060102T150405Z"
+	localDateTimeFormat = "20060102T150405"
+	maxLineOctets       = 75
+)
+
+// Event is a single VEVENT
+type Event struct {
+	UID         string // stable across updates
+	Sequence    int    // incremented on every change the attendees should pick up
+	Summary     string
+	Description string
+	URL         string // where to join
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time // when this version of the event was last modified
+	Organizer   string    // email
+	Attendees   []string  // emails
+	Cancelled   bool
+
+	// Recurring events
+	TimeZone     string      // IANA name the recurrence is expanded in; times are written in UTC without it
+	Rule         string      // RRULE value
+	ExDates      []time.Time // starts of occurrences that were called off
+	RecurrenceID time.Time   // original start of the occurrence this event overrides
+}
+
+// Marshal renders a VCALENDAR holding events
+func Marshal(method string, events ...Event) []byte {
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", prodID)
+	w.line("CALSCALE", "GREGORIAN")
+	if method != "" {
+		w.line("METHOD", method)
+	}
+	for _, event := range events {
+		w.event(event)
+	}
+	w.line("END", "VCALENDAR")
+	return w.buf.Bytes()
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+func (w *writer) event(event Event) {
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", escape(event.UID))
+	w.line("SEQUENCE", fmt.Sprint(event.Sequence))
+	w.line("DTSTAMP", event.Stamp.UTC().Format(dateTimeFormat))
+	w.time("DTSTART", event.TimeZone, event.Start)
+	w.time("DTEND", event.TimeZone, event.End)
+	if !event.RecurrenceID.IsZero() {
+		w.time("RECURRENCE-ID", event.TimeZone, event.RecurrenceID)
+	}
+	if event.Rule != "" {
+		w.line("RRULE", event.Rule)
+	}
+	for _, exdate := range event.ExDates {
+		w.time("EXDATE", event.TimeZone, exdate)
+	}
+	w.line("SUMMARY", escape(event.Summary))
+	if event.Description != "" || event.URL != "" {
+		description := event.Description
+		if event.URL != "" {
+			description = strings.TrimSpace(description + "\n\nJoin: " + event.URL)
+		}
+		w.line("DESCRIPTION", escape(description))
+	}
+	if event.URL != "" {
+		w.line("URL", event.URL)
+		w.line("LOCATION", escape(event.URL))
+	}
+	if event.Organizer != "" {
+		w.line("ORGANIZER", "mailto:"+event.Organizer)
+	}
+	for _, attendee := range event.Attendees {
+		w.line("ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE", "mailto:"+attendee)
+	}
+	if event.Cancelled {
+		w.line("STATUS", "CANCELLED")
+	} else {
+		w.line("STATUS", "CONFIRMED")
+	}
+	w.line("END", "VEVENT")
+}
+
+// time writes a DATE-TIME property. Recurring events are written in local time with
+// a TZID so calendar apps expand them across daylight saving changes like the service does;
+// the IANA name is understood by all major calendar apps without a VTIMEZONE.
+func (w *writer) time(name, tz string, t time.Time) {
+	if tz == "" || tz == "UTC" {
+		w.line(name, t.UTC().Format(dateTimeFormat))
+		return
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		w.line(name, t.UTC().Format(dateTimeFormat))
+		return
+	}
+	w.line(name+";TZID="+tz, t.In(loc).Format(localDateTimeFormat))
+}
+
+// line writes a content line, folded at 75 octets without splitting UTF-8 sequences
+func (w *writer) line(name, value string) {
+	content := name + ":" + value
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(content[cut]) {
+			cut--
+		}
+		w.buf.WriteString(content[:cut])
+		w.buf.WriteString("\r\n ")
+		content = content[cut:]
+		// Continuation lines start with the folding space, which counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	w.buf.WriteString(content)
+	w.buf.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// escape escapes TEXT values
+func escape(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
@@ -0,0 +1,303 @@
+package calendar
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ of a recurrence rule
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// maxEmptyPeriods stops expanding rules that can never produce another occurrence,
+// e.g. BYMONTHDAY=30 on a series that only ever lands on February
+const maxEmptyPeriods = 1000
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// WeekdayNum is a BYDAY entry. N selects the Nth such weekday of the month
+// (negative counts from the end); zero means every one of them.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule is the subset of an RFC 5545 RRULE the service supports:
+// DAILY, WEEKLY and MONTHLY frequencies with INTERVAL, COUNT, UNTIL, BYDAY and BYMONTHDAY
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int       // zero for no limit
+	Until      time.Time // zero for no limit, inclusive
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+// ParseRule parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10",
+// with or without the "RRULE:" prefix
+func ParseRule(value string) (Rule, error) {
+	rule := Rule{Interval: 1}
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return rule, fmt.Errorf("empty recurrence rule")
+	}
+
+	for part := range strings.SplitSeq(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return rule, fmt.Errorf("malformed rule part %q", part)
+		}
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			switch freq := Frequency(strings.ToUpper(val)); freq {
+			case Daily, Weekly, Monthly:
+				rule.Freq = freq
+			default:
+				return rule, fmt.Errorf("unsupported frequency %q", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("invalid interval %q", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("invalid count %q", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return rule, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for day := range strings.SplitSeq(strings.ToUpper(val), ",") {
+				wd, err := parseWeekdayNum(day)
+				if err != nil {
+					return rule, err
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for day := range strings.SplitSeq(val, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return rule, fmt.Errorf("invalid month day %q", day)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			// Weeks always start on Monday, which is also the RFC 5545 default
+			if strings.ToUpper(val) != "MO" {
+				return rule, fmt.Errorf("unsupported week start %q", val)
+			}
+		default:
+			return rule, fmt.Errorf("unsupported rule part %q", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, fmt.Errorf("recurrence rule requires FREQ")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return rule, fmt.Errorf("COUNT and UNTIL are mutually exclusive")
+	}
+	if rule.Freq != Monthly {
+		if len(rule.ByMonthDay) > 0 {
+			return rule, fmt.Errorf("BYMONTHDAY requires FREQ=MONTHLY")
+		}
+		for _, wd := range rule.ByDay {
+			if wd.N != 0 {
+				return rule, fmt.Errorf("numbered BYDAY requires FREQ=MONTHLY")
+			}
+		}
+	}
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{dateTimeFormat, localDateTimeFormat, "20060102"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date covers the whole day
+				until = until.Add(24*time.Hour - time.Second)
+			}
+			return until, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid until %q", value)
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	if len(value) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", value)
+	}
+	day, ok := weekdays[value[len(value)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", value)
+	}
+	wd := WeekdayNum{Day: day}
+	if prefix := value[:len(value)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid weekday %q", value)
+		}
+		wd.N = n
+	}
+	return wd, nil
+}
+
+// String renders the rule as an RRULE value, without the "RRULE:" prefix
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(dateTimeFormat))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			day := strings.ToUpper(wd.Day.String()[:2])
+			if wd.N != 0 {
+				day = strconv.Itoa(wd.N) + day
+			}
+			days = append(days, day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, n := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(n))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences yields the start of every occurrence in order, beginning with start itself
+// if it matches the rule. Wall-clock time is kept in start's location, so a 09:00 meeting
+// stays at 09:00 across daylight saving changes.
+func (r Rule) Occurrences(start time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		count, empty := 0, 0
+		for period := 0; empty < maxEmptyPeriods; period++ {
+			candidates := r.period(start, period)
+			if len(candidates) == 0 {
+				empty++
+				continue
+			}
+			empty = 0
+			for _, candidate := range candidates {
+				if candidate.Before(start) {
+					continue
+				}
+				if !r.Until.IsZero() && candidate.After(r.Until) {
+					return
+				}
+				if !yield(candidate) {
+					return
+				}
+				count++
+				if r.Count > 0 && count >= r.Count {
+					return
+				}
+			}
+		}
+	}
+}
+
+// period returns the sorted candidate starts of the nth period after the one holding start
+func (r Rule) period(start time.Time, n int) []time.Time {
+	loc := start.Location()
+	hour, minute, sec := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, sec, 0, loc)
+	}
+
+	var candidates []time.Time
+	switch r.Freq {
+	case Daily:
+		day := at(start.Year(), start.Month(), start.Day()+n*r.Interval)
+		if len(r.ByDay) == 0 || r.matchesWeekday(day.Weekday()) {
+			candidates = append(candidates, day)
+		}
+	case Weekly:
+		// Weeks start on Monday
+		offset := (int(start.Weekday()) + 6) % 7
+		monday := at(start.Year(), start.Month(), start.Day()-offset+7*n*r.Interval)
+		if len(r.ByDay) == 0 {
+			return []time.Time{at(monday.Year(), monday.Month(), monday.Day()+offset)}
+		}
+		for i := range 7 {
+			day := at(monday.Year(), monday.Month(), monday.Day()+i)
+			if r.matchesWeekday(day.Weekday()) {
+				candidates = append(candidates, day)
+			}
+		}
+	case Monthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(n*r.Interval), 1, 0, 0, 0, 0, loc)
+		year, month := first.Year(), first.Month()
+		days := daysIn(year, month)
+		for day := 1; day <= days; day++ {
+			if r.matchesMonthDay(start, year, month, day, days) {
+				candidates = append(candidates, at(year, month, day))
+			}
+		}
+	}
+	return candidates
+}
+
+func (r Rule) matchesWeekday(day time.Weekday) bool {
+	return slices.ContainsFunc(r.ByDay, func(wd WeekdayNum) bool { return wd.Day == day })
+}
+
+// matchesMonthDay reports whether day of a month with days days belongs to a MONTHLY series.
+// When both BYMONTHDAY and BYDAY are given a day must satisfy both.
+func (r Rule) matchesMonthDay(start time.Time, year int, month time.Month, day, days int) bool {
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		return day == start.Day()
+	}
+	if len(r.ByMonthDay) > 0 && !slices.ContainsFunc(r.ByMonthDay, func(n int) bool {
+		return n == day || n < 0 && days+n+1 == day
+	}) {
+		return false
+	}
+	if len(r.ByDay) > 0 {
+		weekday := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()
+		nth, nthLast := (day-1)/7+1, -((days-day)/7 + 1)
+		return slices.ContainsFunc(r.ByDay, func(wd WeekdayNum) bool {
+			return wd.Day == weekday && (wd.N == 0 || wd.N == nth || wd.N == nthLast)
+		})
+	}
+	return true
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
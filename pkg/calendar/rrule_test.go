@@ -0,0 +1,146 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	return loc
+}
+
+// expand returns up to limit occurrences of rule from start, formatted in start's location
+func expand(t *testing.T, rule string, start time.Time, limit int) []string {
+	t.Helper()
+	r, err := ParseRule(rule)
+	if err != nil {
+		t.Fatalf("ParseRule(%q): %v", rule, err)
+	}
+	var got []string
+	for occurrence := range r.Occurrences(start) {
+		got = append(got, occurrence.Format("2006-01-02 15:04 MST"))
+		if len(got) == limit {
+			break
+		}
+	}
+	return got
+}
+
+func assertOccurrences(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d occurrences %v, want %d %v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("occurrence %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestOccurrencesKeepWallClockAcrossDST(t *testing.T) {
+	berlin := mustLocation(t, "Europe/Berlin")
+	// Clocks go forward on Sunday 30 March 2025
+	start := time.Date(2025, time.March, 28, 9, 0, 0, 0, berlin)
+
+	got := expand(t, "FREQ=DAILY;COUNT=4", start, 10)
+	assertOccurrences(t, got, []string{
+		"2025-03-28 09:00 CET",
+		"2025-03-29 09:00 CET",
+		"2025-03-30 09:00 CEST",
+		"2025-03-31 09:00 CEST",
+	})
+}
+
+func TestOccurrencesWeeklyByDay(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 10, 0, 0, 0, time.UTC) // a Wednesday
+
+	got := expand(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR;COUNT=5", start, 10)
+	assertOccurrences(t, got, []string{
+		"2025-01-01 10:00 UTC",
+		"2025-01-03 10:00 UTC",
+		"2025-01-13 10:00 UTC",
+		"2025-01-15 10:00 UTC",
+		"2025-01-17 10:00 UTC",
+	})
+}
+
+func TestOccurrencesMonthlyNumberedByDay(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		rule string
+		want []string
+	}{
+		{
+			name: "second Tuesday",
+			rule: "FREQ=MONTHLY;BYDAY=2TU;COUNT=3",
+			want: []string{"2025-01-14 18:00 UTC", "2025-02-11 18:00 UTC", "2025-03-11 18:00 UTC"},
+		},
+		{
+			name: "last Friday",
+			rule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			want: []string{"2025-01-31 18:00 UTC", "2025-02-28 18:00 UTC", "2025-03-28 18:00 UTC"},
+		},
+		{
+			name: "fifth Monday skips months without one",
+			rule: "FREQ=MONTHLY;BYDAY=5MO;COUNT=2",
+			want: []string{"2025-03-31 18:00 UTC", "2025-06-30 18:00 UTC"},
+		},
+		{
+			name: "last day of the month",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+			want: []string{"2025-01-31 18:00 UTC", "2025-02-28 18:00 UTC", "2025-03-31 18:00 UTC"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertOccurrences(t, expand(t, tt.rule, start, 10), tt.want)
+		})
+	}
+}
+
+func TestOccurrencesUntil(t *testing.T) {
+	newYork := mustLocation(t, "America/New_York")
+	start := time.Date(2025, time.June, 2, 20, 0, 0, 0, newYork)
+
+	tests := []struct {
+		name string
+		rule string
+		want int
+	}{
+		// 20:00 in New York on 5 June is 00:00 UTC on 6 June, so a UTC bound of 5 June ends a day early
+		{name: "UTC", rule: "FREQ=DAILY;UNTIL=20250605T235959Z", want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expand(t, tt.rule, start, 100); len(got) != tt.want {
+				t.Errorf("got %d occurrences %v, want %d", len(got), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRuleRejects(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"COUNT=3",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101T000000Z",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=DAILY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=WEEKLY;WKST=SU",
+	} {
+		if _, err := ParseRule(rule); err == nil {
+			t.Errorf("ParseRule(%q) succeeded, want an error", rule)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,17 +13,74 @@ type Config struct {
 	// Server
 	Port           string
 	AllowedOrigins string
+	PublicURL      string // base URL clients reach the service at, used for links it hands out
+	MeetingURL     string // base of meeting join links in invitations, defaults to PublicURL + "/meetings"
 
 	// Google OAuth
 	GoogleClientID     string
 	GoogleClientSecret string
+	GoogleRedirectURL  string   // OAuth redirect for calendar consent, defaults to PublicURL + "/calendar/google/callback"
+	GoogleEmailDomains []string // when set, Google is trusted only for these email domains
+
+	// Sign-in providers besides Google, each enabled once configured
+	AuthRedirectURL    string // client page providers return the authorization code to
+	GitHubClientID     string
+	GitHubClientSecret string
+	GitHubEmailDomains []string // when set, GitHub is trusted only for these email domains
+	OIDCProviderName   string   // how the OpenID Connect provider is named in routes, defaults to "oidc"
+	OIDCIssuerURL      string   // e.g. a Keycloak realm, Okta org or Azure AD tenant URL
+	OIDCClientID       string
+	OIDCClientSecret   string
+	OIDCEmailDomains   []string // email domains the issuer is trusted for, required with OIDCIssuerURL
+
+	// Sessions
+	SessionSigningKey string        // HMAC key the service derives its token and state keys from; never the LiveKit API secret
+	AccessTokenTTL    time.Duration // lifetime of access tokens, defaults to 15 minutes
+	RefreshTokenTTL   time.Duration // how long a session lasts without a refresh, defaults to 30 days
+
+	// Calendar
+	CalendarDriver string // "google" or "local", which keeps pushed events in memory
 
 	// LiveKit
 	LiveKitServer    string
 	LiveKitAPIKey    string
 	LiveKitAPISecret string
+	LiveKitTokenTTL  time.Duration // lifetime of join tokens, defaults to one hour
+
+	// Host controls
+	HostSuccessionPolicy string // "longest_present", "cohost_first" or "hostless"
+
+	// Recording
+	EgressDriver        string        // "livekit" or "local", which only pretends to record
+	RecordingFilepath   string        // egress output path template, e.g. "recordings/{room_name}-{time}"
+	RecordingDir        string        // local directory egress output paths resolve against, for downloads
+	RecordingSigningKey string        // HMAC key for download links, derived from SessionSigningKey when unset
+	RecordingLinkTTL    time.Duration // lifetime of download links, defaults to 15 minutes
+
+	// Background jobs
+	ReminderLeadTime time.Duration // how long before a meeting starts reminders go out, defaults to 10 minutes
+	RoomIdleTimeout  time.Duration // how long an empty room lives before cleanup, defaults to 15 minutes
+
+	// Notification channels, each enabled once configured
+	SMTPAddr         string // host:port of the mail server
+	SMTPFrom         string
+	SMTPUsername     string
+	SMTPPassword     string
+	NotifyWebhookURL string // receives every notification as JSON
+	SlackWebhookURL  string // Slack-compatible incoming webhook
+
+	// Storage
+	StoreDriver string // "memory", "sqlite" or "postgres"
+	DatabaseURL string
 }
 
+const (
+	// defaultStoreDriver keeps room ownership in process memory
+	defaultStoreDriver = "memory"
+	// defaultSQLiteURL is used when STORE_DRIVER=sqlite and DATABASE_URL is unset
+	defaultSQLiteURL = "file:open-meet.db?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+)
+
 // LoadConfig loads environment variables from .env file and returns Config
 func LoadConfig() (*Config, error) {
 	// Load .env file if it exists
@@ -37,6 +96,7 @@ func LoadConfig() (*Config, error) {
 		"LIVEKIT_API_KEY",
 		"LIVEKIT_API_SECRET",
 		"LIVEKIT_SERVER",
+		"SESSION_SIGNING_KEY",
 	}
 
 	// Check for missing environment variables
@@ -46,13 +106,140 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	tokenTTL, err := durationEnv("LIVEKIT_TOKEN_TTL")
+	if err != nil {
+		return nil, err
+	}
+
+	linkTTL, err := durationEnv("RECORDING_LINK_TTL")
+	if err != nil {
+		return nil, err
+	}
+	signingKey := os.Getenv("RECORDING_SIGNING_KEY")
+	if signingKey != "" && signingKey == os.Getenv("LIVEKIT_API_SECRET") {
+		return nil, fmt.Errorf("RECORDING_SIGNING_KEY must differ from LIVEKIT_API_SECRET")
+	}
+
+	// Whoever holds the LiveKit secret must not be able to sign the service's own tokens
+	sessionKey := os.Getenv("SESSION_SIGNING_KEY")
+	if sessionKey == os.Getenv("LIVEKIT_API_SECRET") {
+		return nil, fmt.Errorf("SESSION_SIGNING_KEY must differ from LIVEKIT_API_SECRET")
+	}
+	accessTTL, err := durationEnv("ACCESS_TOKEN_TTL")
+	if err != nil {
+		return nil, err
+	}
+	refreshTTL, err := durationEnv("REFRESH_TOKEN_TTL")
+	if err != nil {
+		return nil, err
+	}
+
+	reminderLead, err := durationEnv("REMINDER_LEAD_TIME")
+	if err != nil {
+		return nil, err
+	}
+	idleTimeout, err := durationEnv("ROOM_IDLE_TIMEOUT")
+	if err != nil {
+		return nil, err
+	}
+
+	publicURL := strings.TrimSuffix(os.Getenv("PUBLIC_URL"), "/")
+	meetingURL := os.Getenv("MEETING_URL")
+	if meetingURL == "" {
+		meetingURL = publicURL + "/meetings"
+	}
+	redirectURL := os.Getenv("GOOGLE_REDIRECT_URL")
+	if redirectURL == "" {
+		redirectURL = publicURL + "/calendar/google/callback"
+	}
+
+	// Whoever administers the issuer can put any email in its tokens, so it only
+	// speaks for the domains it is listed for
+	oidcDomains := listEnv("OIDC_EMAIL_DOMAINS")
+	if os.Getenv("OIDC_ISSUER_URL") != "" && len(oidcDomains) == 0 {
+		return nil, fmt.Errorf("OIDC_EMAIL_DOMAINS is required when OIDC_ISSUER_URL is set")
+	}
+
+	storeDriver := os.Getenv("STORE_DRIVER")
+	if storeDriver == "" {
+		storeDriver = defaultStoreDriver
+	}
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" && storeDriver == "sqlite" {
+		databaseURL = defaultSQLiteURL
+	}
+	if databaseURL == "" && storeDriver != defaultStoreDriver {
+		return nil, fmt.Errorf("DATABASE_URL is required for store driver %s", storeDriver)
+	}
+
 	return &Config{
-		GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-		GoogleClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-		LiveKitServer:      os.Getenv("LIVEKIT_SERVER"),
-		LiveKitAPIKey:      os.Getenv("LIVEKIT_API_KEY"),
-		LiveKitAPISecret:   os.Getenv("LIVEKIT_API_SECRET"),
-		AllowedOrigins:     os.Getenv("ALLOWED_ORIGINS"),
-		Port:               os.Getenv("PORT"),
+		GoogleClientID:       os.Getenv("GOOGLE_CLIENT_ID"),
+		GoogleClientSecret:   os.Getenv("GOOGLE_CLIENT_SECRET"),
+		GoogleRedirectURL:    redirectURL,
+		GoogleEmailDomains:   listEnv("GOOGLE_EMAIL_DOMAINS"),
+		AuthRedirectURL:      os.Getenv("AUTH_REDIRECT_URL"),
+		GitHubClientID:       os.Getenv("GITHUB_CLIENT_ID"),
+		GitHubClientSecret:   os.Getenv("GITHUB_CLIENT_SECRET"),
+		GitHubEmailDomains:   listEnv("GITHUB_EMAIL_DOMAINS"),
+		OIDCProviderName:     os.Getenv("OIDC_PROVIDER_NAME"),
+		OIDCIssuerURL:        os.Getenv("OIDC_ISSUER_URL"),
+		OIDCClientID:         os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:     os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCEmailDomains:     oidcDomains,
+		SessionSigningKey:    sessionKey,
+		AccessTokenTTL:       accessTTL,
+		RefreshTokenTTL:      refreshTTL,
+		CalendarDriver:       os.Getenv("CALENDAR_DRIVER"),
+		LiveKitServer:        os.Getenv("LIVEKIT_SERVER"),
+		LiveKitAPIKey:        os.Getenv("LIVEKIT_API_KEY"),
+		LiveKitAPISecret:     os.Getenv("LIVEKIT_API_SECRET"),
+		LiveKitTokenTTL:      tokenTTL,
+		AllowedOrigins:       os.Getenv("ALLOWED_ORIGINS"),
+		Port:                 os.Getenv("PORT"),
+		PublicURL:            publicURL,
+		MeetingURL:           meetingURL,
+		HostSuccessionPolicy: os.Getenv("HOST_SUCCESSION_POLICY"),
+		EgressDriver:         os.Getenv("EGRESS_DRIVER"),
+		RecordingFilepath:    os.Getenv("RECORDING_FILEPATH"),
+		RecordingDir:         os.Getenv("RECORDING_DIR"),
+		RecordingSigningKey:  signingKey,
+		RecordingLinkTTL:     linkTTL,
+		ReminderLeadTime:     reminderLead,
+		RoomIdleTimeout:      idleTimeout,
+		SMTPAddr:             os.Getenv("SMTP_ADDR"),
+		SMTPFrom:             os.Getenv("SMTP_FROM"),
+		SMTPUsername:         os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:         os.Getenv("SMTP_PASSWORD"),
+		NotifyWebhookURL:     os.Getenv("NOTIFY_WEBHOOK_URL"),
+		SlackWebhookURL:      os.Getenv("SLACK_WEBHOOK_URL"),
+		StoreDriver:          storeDriver,
+		DatabaseURL:          databaseURL,
 	}, nil
 }
+
+// durationEnv parses an optional duration variable; unset means zero, and a set value must be positive
+func durationEnv(name string) (time.Duration, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid %s: %s is not a positive duration", name, raw)
+	}
+	return d, nil
+}
+
+// listEnv splits an optional comma-separated variable, dropping empty entries
+func listEnv(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,140 @@
+// Package notify delivers notifications to people and chat rooms outside the service
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Message is what a notification says, independent of how it is delivered
+type Message struct {
+	Recipients []string // email addresses; chat channels post to their configured room instead
+	Subject    string
+	Body       string
+	URL        string // where to act on the notification, e.g. a join link
+}
+
+// Channel delivers messages. An error means the delivery may be retried.
+type Channel interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPChannel emails each message to its recipients
+type SMTPChannel struct {
+	Addr     string // host:port of the mail server
+	From     string
+	Username string // optional, enables PLAIN auth
+	Password string
+}
+
+// Send emails the message; messages without recipients are dropped
+func (c *SMTPChannel) Send(ctx context.Context, msg Message) error {
+	if len(msg.Recipients) == 0 {
+		return nil
+	}
+
+	var auth smtp.Auth
+	if c.Username != "" {
+		host, _, err := net.SplitHostPort(c.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address %q: %w", c.Addr, err)
+		}
+		auth = smtp.PlainAuth("", c.Username, c.Password, host)
+	}
+
+	if err := smtp.SendMail(c.Addr, auth, c.From, msg.Recipients, c.compose(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (c *SMTPChannel) compose(msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", c.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.Recipients, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", headerValue(msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(messageText(msg), "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+// headerValue keeps a value from injecting headers of its own
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// WebhookChannel posts each message as JSON to a URL of the operator's choosing
+type WebhookChannel struct {
+	URL    string
+	Client *http.Client // defaults to http.DefaultClient
+}
+
+// Send posts the message as {"subject", "body", "url", "recipients"}
+func (c *WebhookChannel) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, c.Client, c.URL, map[string]any{
+		"subject":    msg.Subject,
+		"body":       msg.Body,
+		"url":        msg.URL,
+		"recipients": msg.Recipients,
+	})
+}
+
+// SlackChannel posts each message to a Slack-compatible incoming webhook
+type SlackChannel struct {
+	WebhookURL string
+	Client     *http.Client // defaults to http.DefaultClient
+}
+
+// Send posts the message as {"text"}, which Slack, Mattermost and Rocket.Chat all accept
+func (c *SlackChannel) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, c.Client, c.WebhookURL, map[string]any{
+		"text": "*" + msg.Subject + "*\n" + messageText(msg),
+	})
+}
+
+// messageText is the body followed by the link, if any
+func messageText(msg Message) string {
+	if msg.URL == "" {
+		return msg.Body
+	}
+	return strings.TrimSpace(msg.Body + "\n\n" + msg.URL)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
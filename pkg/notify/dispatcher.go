@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+
+	"open-meet/pkg/store"
+)
+
+const (
+	// DefaultMaxAttempts is how often delivery is tried before a notification is given up
+	DefaultMaxAttempts = 5
+	// baseBackoff is the delay before the first retry; it doubles with every attempt
+	baseBackoff = 30 * time.Second
+	maxBackoff  = time.Hour
+	// claimLease keeps a notification from being picked up twice while it is being sent.
+	// A process dying mid-send makes it eligible again once the lease runs out.
+	claimLease = 5 * time.Minute
+	// deliverBatch bounds how many notifications one Deliver call sends
+	deliverBatch = 50
+)
+
+// Dispatcher fans notifications out to every channel through a persistent outbox.
+// Enqueue is idempotent per key, and Deliver, run periodically by the scheduler,
+// sends what is due and retries failures with exponential backoff.
+type Dispatcher struct {
+	outbox      store.NotificationRegistry
+	channels    map[string]Channel
+	maxAttempts int
+	log         logr.Logger
+}
+
+// NewDispatcher creates a dispatcher delivering through channels, keyed by name
+func NewDispatcher(outbox store.NotificationRegistry, channels map[string]Channel, log logr.Logger) *Dispatcher {
+	return &Dispatcher{
+		outbox:      outbox,
+		channels:    channels,
+		maxAttempts: DefaultMaxAttempts,
+		log:         log,
+	}
+}
+
+// Enqueue queues msg for every channel. Enqueueing the same key again is a no-op,
+// so jobs may safely re-enqueue after a restart.
+func (d *Dispatcher) Enqueue(ctx context.Context, key string, msg Message, now time.Time) error {
+	for _, name := range slices.Sorted(maps.Keys(d.channels)) {
+		notification := &store.Notification{
+			ID:            uuid.NewString(),
+			Key:           key + "|" + name,
+			Channel:       name,
+			Recipients:    msg.Recipients,
+			Subject:       msg.Subject,
+			Body:          msg.Body,
+			URL:           msg.URL,
+			Status:        store.NotificationPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if _, err := d.outbox.EnqueueNotification(ctx, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliver sends the notifications due at now
+func (d *Dispatcher) Deliver(ctx context.Context, now time.Time) error {
+	due, err := d.outbox.ClaimNotifications(ctx, now, claimLease, deliverBatch)
+	if err != nil {
+		return err
+	}
+
+	for i := range due {
+		notification := &due[i]
+		log := d.log.WithValues("key", notification.Key, "channel", notification.Channel)
+
+		notification.Attempts++
+		if err := d.send(ctx, notification); err != nil {
+			notification.LastError = err.Error()
+			if notification.Attempts >= d.maxAttempts {
+				notification.Status = store.NotificationFailed
+				log.Error(err, "giving up on notification", "attempts", notification.Attempts)
+			} else {
+				notification.NextAttemptAt = now.Add(backoff(notification.Attempts))
+				log.Error(err, "notification failed, will retry", "attempts", notification.Attempts, "retryAt", notification.NextAttemptAt)
+			}
+		} else {
+			notification.Status = store.NotificationSent
+			notification.SentAt = now
+			notification.LastError = ""
+		}
+
+		if err := d.outbox.SaveNotification(ctx, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, notification *store.Notification) error {
+	channel, ok := d.channels[notification.Channel]
+	if !ok {
+		return fmt.Errorf("channel %q is not configured", notification.Channel)
+	}
+	return channel.Send(ctx, Message{
+		Recipients: notification.Recipients,
+		Subject:    notification.Subject,
+		Body:       notification.Body,
+		URL:        notification.URL,
+	})
+}
+
+// backoff is the delay before retrying after the given number of attempts
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"open-meet/pkg/scheduler"
+	"open-meet/pkg/store"
+)
+
+// flakyChannel fails its first failures sends and succeeds after that
+type flakyChannel struct {
+	mu       sync.Mutex
+	failures int
+	sent     []Message
+	attempts int
+}
+
+func (c *flakyChannel) Send(ctx context.Context, msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attempts++
+	if c.attempts <= c.failures {
+		return errors.New("unavailable")
+	}
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func (c *flakyChannel) Attempts() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.attempts
+}
+
+var epoch = time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+
+func newDispatcher(t *testing.T, channel Channel) (*Dispatcher, *scheduler.ManualClock) {
+	t.Helper()
+	d := NewDispatcher(store.NewMemoryNotificationRegistry(), map[string]Channel{"chat": channel}, logr.Discard())
+	return d, scheduler.NewManualClock(epoch)
+}
+
+func deliver(t *testing.T, d *Dispatcher, clock *scheduler.ManualClock) {
+	t.Helper()
+	if err := d.Deliver(context.Background(), clock.Now()); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	channel := &flakyChannel{failures: 2}
+	d, clock := newDispatcher(t, channel)
+	if err := d.Enqueue(context.Background(), "reminder", Message{Subject: "Standup"}, clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	deliver(t, d, clock)
+	if got := channel.Attempts(); got != 1 {
+		t.Fatalf("attempts = %d, want 1", got)
+	}
+
+	// The first retry waits baseBackoff, the second twice that
+	for _, wait := range []time.Duration{baseBackoff, 2 * baseBackoff} {
+		before := channel.Attempts()
+		clock.Advance(wait - time.Second)
+		deliver(t, d, clock)
+		if got := channel.Attempts(); got != before {
+			t.Fatalf("retried before waiting %s", wait)
+		}
+
+		clock.Advance(time.Second)
+		deliver(t, d, clock)
+		if got := channel.Attempts(); got != before+1 {
+			t.Fatalf("attempts = %d after waiting %s, want %d", got, wait, before+1)
+		}
+	}
+
+	if len(channel.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(channel.sent))
+	}
+
+	// Once sent, the notification is never delivered again
+	clock.Advance(24 * time.Hour)
+	deliver(t, d, clock)
+	if got := channel.Attempts(); got != 3 {
+		t.Errorf("attempts = %d after delivery, want 3", got)
+	}
+}
+
+func TestDeliverGivesUpAfterMaxAttempts(t *testing.T) {
+	channel := &flakyChannel{failures: 100}
+	d, clock := newDispatcher(t, channel)
+	if err := d.Enqueue(context.Background(), "reminder", Message{Subject: "Standup"}, clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	for range DefaultMaxAttempts + 3 {
+		deliver(t, d, clock)
+		clock.Advance(maxBackoff)
+	}
+
+	if got := channel.Attempts(); got != DefaultMaxAttempts {
+		t.Errorf("attempts = %d, want %d", got, DefaultMaxAttempts)
+	}
+}
+
+func TestEnqueueIsIdempotentPerKey(t *testing.T) {
+	channel := &flakyChannel{}
+	d, clock := newDispatcher(t, channel)
+	for range 2 {
+		if err := d.Enqueue(context.Background(), "reminder", Message{Subject: "Standup"}, clock.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deliver(t, d, clock)
+	if len(channel.sent) != 1 {
+		t.Errorf("sent %d messages, want 1", len(channel.sent))
+	}
+}
+
+func TestClaimedNotificationIsRetriedAfterLease(t *testing.T) {
+	outbox := store.NewMemoryNotificationRegistry()
+	channel := &flakyChannel{}
+	d := NewDispatcher(outbox, map[string]Channel{"chat": channel}, logr.Discard())
+	clock := scheduler.NewManualClock(epoch)
+	if err := d.Enqueue(context.Background(), "reminder", Message{Subject: "Standup"}, clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A process that claims the notification and dies never saves the outcome
+	if _, err := outbox.ClaimNotifications(context.Background(), clock.Now(), claimLease, deliverBatch); err != nil {
+		t.Fatal(err)
+	}
+
+	deliver(t, d, clock)
+	if got := channel.Attempts(); got != 0 {
+		t.Fatalf("delivered a claimed notification before its lease ran out")
+	}
+
+	clock.Advance(claimLease)
+	deliver(t, d, clock)
+	if got := channel.Attempts(); got != 1 {
+		t.Errorf("attempts = %d after the lease ran out, want 1", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 3, want: 2 * time.Minute},
+		{attempts: 7, want: 32 * time.Minute},
+		{attempts: 8, want: time.Hour},
+		{attempts: 50, want: time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
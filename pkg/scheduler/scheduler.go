@@ -0,0 +1,136 @@
+// Package scheduler runs the service's background jobs
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// maxSleep bounds how long the loop sleeps, so jobs added while it waits
+// never start much later than planned
+const maxSleep = time.Minute
+
+// JobFunc is the work of a job; now is the scheduler clock's time when the run started
+type JobFunc func(ctx context.Context, now time.Time) error
+
+type job struct {
+	name     string
+	run      JobFunc
+	next     time.Time
+	interval time.Duration // zero for a job that runs once
+	running  bool
+}
+
+// Scheduler runs delayed and periodic jobs. A periodic job never overlaps itself:
+// a run that is still going when the next one is due makes the scheduler skip that one.
+type Scheduler struct {
+	clock Clock
+	log   logr.Logger
+
+	mu   sync.Mutex
+	jobs []*job
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a scheduler reading the time from clock
+func New(clock Clock, log logr.Logger) *Scheduler {
+	return &Scheduler{
+		clock: clock,
+		log:   log,
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// Every runs fn every interval, starting one interval from now
+func (s *Scheduler) Every(name string, interval time.Duration, fn JobFunc) {
+	s.add(&job{name: name, run: fn, next: s.clock.Now().Add(interval), interval: interval})
+}
+
+// At runs fn once at the given time, or right away if it has passed
+func (s *Scheduler) At(name string, at time.Time, fn JobFunc) {
+	s.add(&job{name: name, run: fn, next: at})
+}
+
+// After runs fn once after delay
+func (s *Scheduler) After(name string, delay time.Duration, fn JobFunc) {
+	s.At(name, s.clock.Now().Add(delay), fn)
+}
+
+func (s *Scheduler) add(j *job) {
+	s.mu.Lock()
+	s.jobs = append(s.jobs, j)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run runs jobs as they come due until ctx is cancelled, then waits for running jobs to return
+func (s *Scheduler) Run(ctx context.Context) {
+	defer s.wg.Wait()
+	for {
+		sleep := s.runDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-s.clock.After(sleep):
+		}
+	}
+}
+
+// runDue starts every job that came due and returns how long to sleep until the next one
+func (s *Scheduler) runDue(ctx context.Context) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	sleep := maxSleep
+	remaining := s.jobs[:0]
+	for _, j := range s.jobs {
+		if !j.next.After(now) {
+			if !j.running {
+				s.start(ctx, j, now)
+			} else {
+				s.log.Info("job still running, skipping run", "job", j.name)
+			}
+			if j.interval == 0 {
+				continue
+			}
+			for !j.next.After(now) {
+				j.next = j.next.Add(j.interval)
+			}
+		}
+		sleep = min(sleep, j.next.Sub(now))
+		remaining = append(remaining, j)
+	}
+	s.jobs = remaining
+	return sleep
+}
+
+// start runs j in the background; s.mu must be held
+func (s *Scheduler) start(ctx context.Context, j *job, now time.Time) {
+	j.running = true
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				s.log.Error(nil, "job panicked", "job", j.name, "panic", r)
+			}
+			s.mu.Lock()
+			j.running = false
+			s.mu.Unlock()
+		}()
+
+		if err := j.run(ctx, now); err != nil {
+			s.log.Error(err, "job failed", "job", j.name)
+		}
+	}()
+}
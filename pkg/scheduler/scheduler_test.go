@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+var epoch = time.Date(2025, time.January, 6, 9, 0, 0, 0, time.UTC)
+
+// start runs s until the test ends
+func start(t *testing.T, s *Scheduler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitAsleep waits until the scheduler loop is waiting on a timer that has not fired yet
+func waitAsleep(t *testing.T, clock *ManualClock) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		clock.mu.Lock()
+		asleep := false
+		for _, w := range clock.waiters {
+			asleep = asleep || w.at.After(clock.now)
+		}
+		clock.mu.Unlock()
+		if asleep {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("scheduler never went to sleep")
+}
+
+// waitIdle waits until no job is running
+func waitIdle(t *testing.T, s *Scheduler) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		running := false
+		for _, j := range s.jobs {
+			running = running || j.running
+		}
+		s.mu.Unlock()
+		if !running {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("job never finished")
+}
+
+func receive(t *testing.T, runs <-chan time.Time) time.Time {
+	t.Helper()
+	select {
+	case now := <-runs:
+		return now
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not run")
+		return time.Time{}
+	}
+}
+
+func assertNoRun(t *testing.T, runs <-chan time.Time) {
+	t.Helper()
+	select {
+	case now := <-runs:
+		t.Fatalf("unexpected run at %s", now)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestEveryRunsOncePerInterval(t *testing.T) {
+	clock := NewManualClock(epoch)
+	s := New(clock, logr.Discard())
+	runs := make(chan time.Time, 10)
+	s.Every("tick", 10*time.Second, func(ctx context.Context, now time.Time) error {
+		runs <- now
+		return nil
+	})
+	start(t, s)
+
+	for i := 1; i <= 3; i++ {
+		waitAsleep(t, clock)
+		clock.Advance(9 * time.Second)
+		assertNoRun(t, runs)
+
+		waitAsleep(t, clock)
+		clock.Advance(time.Second)
+		if got, want := receive(t, runs), epoch.Add(time.Duration(i)*10*time.Second); !got.Equal(want) {
+			t.Errorf("run %d at %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestAfterRunsOnce(t *testing.T) {
+	clock := NewManualClock(epoch)
+	s := New(clock, logr.Discard())
+	runs := make(chan time.Time, 10)
+	s.After("once", time.Minute, func(ctx context.Context, now time.Time) error {
+		runs <- now
+		return nil
+	})
+	start(t, s)
+
+	waitAsleep(t, clock)
+	clock.Advance(time.Minute)
+	if got, want := receive(t, runs), epoch.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("ran at %s, want %s", got, want)
+	}
+
+	waitAsleep(t, clock)
+	clock.Advance(time.Hour)
+	assertNoRun(t, runs)
+}
+
+func TestAtInThePastRunsRightAway(t *testing.T) {
+	clock := NewManualClock(epoch)
+	s := New(clock, logr.Discard())
+	runs := make(chan time.Time, 10)
+	s.At("late", epoch.Add(-time.Hour), func(ctx context.Context, now time.Time) error {
+		runs <- now
+		return nil
+	})
+	start(t, s)
+
+	if got := receive(t, runs); !got.Equal(epoch) {
+		t.Errorf("ran at %s, want %s", got, epoch)
+	}
+}
+
+func TestEverySkipsRunWhilePreviousIsGoing(t *testing.T) {
+	clock := NewManualClock(epoch)
+	s := New(clock, logr.Discard())
+	runs := make(chan time.Time, 10)
+	release := make(chan struct{})
+	s.Every("slow", 10*time.Second, func(ctx context.Context, now time.Time) error {
+		runs <- now
+		<-release
+		return nil
+	})
+	start(t, s)
+
+	waitAsleep(t, clock)
+	clock.Advance(10 * time.Second)
+	receive(t, runs)
+
+	// Still running when the next run comes due, so that run is skipped
+	waitAsleep(t, clock)
+	clock.Advance(10 * time.Second)
+	assertNoRun(t, runs)
+	release <- struct{}{}
+	waitIdle(t, s)
+
+	waitAsleep(t, clock)
+	clock.Advance(10 * time.Second)
+	if got, want := receive(t, runs), epoch.Add(30*time.Second); !got.Equal(want) {
+		t.Errorf("next run at %s, want %s", got, want)
+	}
+	close(release)
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRoomLocked is returned when a new participant tries to join a locked room
+var ErrRoomLocked = errors.New("room is locked")
+
+// AdmissionStatus is the state of a waiting-room request
+type AdmissionStatus string
+
+const (
+	AdmissionPending  AdmissionStatus = "pending"
+	AdmissionApproved AdmissionStatus = "approved"
+	AdmissionDenied   AdmissionStatus = "denied"
+)
+
+// Admission is a user's request to enter a room with the waiting room enabled.
+// Guests are identified by their server-chosen identity in place of an email.
+type Admission struct {
+	RoomName    string          `json:"room_name"`
+	Email       string          `json:"email"`
+	DisplayName string          `json:"display_name,omitempty"` // name a guest gave; empty for signed-in users
+	Guest       bool            `json:"guest,omitempty"`
+	Status      AdmissionStatus `json:"status"`
+	RequestedAt time.Time       `json:"requested_at"`
+	DecidedAt   time.Time       `json:"decided_at,omitzero"`
+	DecidedBy   string          `json:"decided_by,omitempty"`
+}
+
+// RequestJoin decides whether email may be issued a join token for the room.
+// Owners, co-hosts and moderators always get in. Locked rooms refuse newcomers with
+// ErrRoomLocked, and with the waiting room enabled everybody else is queued until a
+// host approves them.
+func (h *host) RequestJoin(ctx context.Context, roomName, email string) (AdmissionStatus, error) {
+	record, found, err := h.registry.GetRoom(ctx, roomName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get room: %w", err)
+	}
+	if !found {
+		// Rooms the service did not create carry no admission rules
+		return AdmissionApproved, nil
+	}
+
+	role, err := h.registry.GetRole(ctx, roomName, email)
+	if err != nil {
+		return "", fmt.Errorf("failed to check role: %w", err)
+	}
+	if role.Outranks(RoleParticipant) {
+		return AdmissionApproved, nil
+	}
+
+	if err := h.checkLock(ctx, record, email); err != nil {
+		return "", err
+	}
+
+	if !record.Settings.WaitingRoom {
+		return AdmissionApproved, nil
+	}
+
+	status, err := h.registry.RequestAdmission(ctx, roomName, email, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to queue admission: %w", err)
+	}
+	return status, nil
+}
+
+// SetWaitingRoom turns the waiting room on or off
+func (h *host) SetWaitingRoom(ctx context.Context, roomName string, hostEmail string, enabled bool) error {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermAdmitParticipants, "configure the waiting room"); err != nil {
+		return err
+	}
+
+	if err := h.registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		settings.WaitingRoom = enabled
+	}); err != nil {
+		return fmt.Errorf("failed to store waiting room setting: %w", err)
+	}
+
+	if _, err := h.metadata.PublishRoom(ctx, roomName); err != nil {
+		return fmt.Errorf("failed to publish waiting room setting: %w", err)
+	}
+
+	return nil
+}
+
+// ListAdmissions returns the room's waiting-room requests with the given status, or all when status is empty
+func (h *host) ListAdmissions(ctx context.Context, roomName string, hostEmail string, status AdmissionStatus) ([]Admission, error) {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermAdmitParticipants, "view the waiting room"); err != nil {
+		return nil, err
+	}
+
+	admissions, err := h.registry.ListAdmissions(ctx, roomName, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admissions: %w", err)
+	}
+	for i := range admissions {
+		admissions[i].Guest = IsGuestIdentity(admissions[i].Email)
+	}
+	return admissions, nil
+}
+
+// ApproveAdmission lets a waiting user into the room
+func (h *host) ApproveAdmission(ctx context.Context, roomName string, hostEmail string, email string) error {
+	return h.decideAdmission(ctx, roomName, hostEmail, email, AdmissionApproved)
+}
+
+// DenyAdmission turns a waiting user away
+func (h *host) DenyAdmission(ctx context.Context, roomName string, hostEmail string, email string) error {
+	return h.decideAdmission(ctx, roomName, hostEmail, email, AdmissionDenied)
+}
+
+func (h *host) decideAdmission(ctx context.Context, roomName, hostEmail, email string, status AdmissionStatus) error {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermAdmitParticipants, "admit participants"); err != nil {
+		return err
+	}
+
+	if err := h.registry.DecideAdmission(ctx, roomName, email, status, hostEmail); err != nil {
+		return fmt.Errorf("failed to record admission decision: %w", err)
+	}
+	return nil
+}
+
+// checkLock refuses identity with ErrRoomLocked if the room is locked and they are not already inside
+func (h *host) checkLock(ctx context.Context, record *RoomRecord, identity string) error {
+	if !record.Settings.Locked {
+		return nil
+	}
+	present, err := h.isPresent(ctx, record.Name, identity)
+	if err != nil {
+		return err
+	}
+	// Participants already inside may reconnect to a locked room
+	if !present {
+		return fmt.Errorf("%w: %s", ErrRoomLocked, record.Name)
+	}
+	return nil
+}
+
+// isPresent reports whether email is currently in the room according to webhook presence
+func (h *host) isPresent(ctx context.Context, roomName, email string) (bool, error) {
+	presence, err := h.registry.ListPresence(ctx, roomName)
+	if err != nil {
+		return false, fmt.Errorf("failed to list participants: %w", err)
+	}
+	for _, p := range presence {
+		if p.Identity == email && p.Present() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
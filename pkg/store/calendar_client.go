@@ -0,0 +1,241 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	gcalendar "google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+
+	"open-meet/pkg/calendar"
+)
+
+// CalendarClient pushes meetings into a host's external calendar. The host grants access
+// once through OAuth consent; the resulting refresh token authorizes every later call.
+// GoogleCalendarClient talks to Google Calendar; LocalCalendarClient stands in for it.
+type CalendarClient interface {
+	// AuthCodeURL is where the host is sent to grant access; state comes back with the code
+	AuthCodeURL(state string) string
+	// Exchange trades the authorization code for a refresh token
+	Exchange(ctx context.Context, code string) (string, error)
+
+	InsertEvent(ctx context.Context, refreshToken string, event calendar.Event) (string, error)
+	UpdateEvent(ctx context.Context, refreshToken string, eventID string, event calendar.Event) error
+	// DeleteEvent succeeds if the event is already gone
+	DeleteEvent(ctx context.Context, refreshToken string, eventID string) error
+}
+
+// NewCalendarClient returns the calendar client selected by driver: "google" (the default) or "local"
+func NewCalendarClient(driver, clientID, clientSecret, redirectURL string) (CalendarClient, error) {
+	switch driver {
+	case "", "google":
+		return NewGoogleCalendarClient(clientID, clientSecret, redirectURL), nil
+	case "local":
+		return NewLocalCalendarClient(redirectURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported calendar driver %q", driver)
+	}
+}
+
+// GoogleCalendarClient creates events in the host's primary Google calendar.
+// Google emails the invitations, so attendees hear about changes even without the .ics feed.
+type GoogleCalendarClient struct {
+	oauth *oauth2.Config
+}
+
+// NewGoogleCalendarClient creates a client for the OAuth application identified by clientID
+func NewGoogleCalendarClient(clientID, clientSecret, redirectURL string) *GoogleCalendarClient {
+	return &GoogleCalendarClient{
+		oauth: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint:     google.Endpoint,
+			Scopes:       []string{gcalendar.CalendarEventsScope},
+		},
+	}
+}
+
+// AuthCodeURL asks for offline access so Google hands out a refresh token
+func (c *GoogleCalendarClient) AuthCodeURL(state string) string {
+	return c.oauth.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce)
+}
+
+// Exchange trades the authorization code for a refresh token
+func (c *GoogleCalendarClient) Exchange(ctx context.Context, code string) (string, error) {
+	token, err := c.oauth.Exchange(ctx, code)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if token.RefreshToken == "" {
+		return "", fmt.Errorf("google did not return a refresh token")
+	}
+	return token.RefreshToken, nil
+}
+
+// InsertEvent creates the event and invites its attendees
+func (c *GoogleCalendarClient) InsertEvent(ctx context.Context, refreshToken string, event calendar.Event) (string, error) {
+	srv, err := c.service(ctx, refreshToken)
+	if err != nil {
+		return "", err
+	}
+	created, err := srv.Events.Insert("primary", googleEvent(event)).SendUpdates("all").Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to create google calendar event: %w", err)
+	}
+	return created.Id, nil
+}
+
+// UpdateEvent replaces the event and notifies its attendees
+func (c *GoogleCalendarClient) UpdateEvent(ctx context.Context, refreshToken string, eventID string, event calendar.Event) error {
+	srv, err := c.service(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	if _, err := srv.Events.Update("primary", eventID, googleEvent(event)).SendUpdates("all").Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to update google calendar event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// DeleteEvent cancels the event for its attendees
+func (c *GoogleCalendarClient) DeleteEvent(ctx context.Context, refreshToken string, eventID string) error {
+	srv, err := c.service(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	err = srv.Events.Delete("primary", eventID).SendUpdates("all").Context(ctx).Do()
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && (apiErr.Code == http.StatusNotFound || apiErr.Code == http.StatusGone) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete google calendar event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+func (c *GoogleCalendarClient) service(ctx context.Context, refreshToken string) (*gcalendar.Service, error) {
+	tokens := c.oauth.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken})
+	srv, err := gcalendar.NewService(ctx, option.WithTokenSource(tokens))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create google calendar service: %w", err)
+	}
+	return srv, nil
+}
+
+// googleEvent converts a calendar event. Changed occurrences of a series are not pushed;
+// Google shows every occurrence as the series defines it.
+func googleEvent(event calendar.Event) *gcalendar.Event {
+	tz := event.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	out := &gcalendar.Event{
+		Summary:     event.Summary,
+		Description: event.Description,
+		Location:    event.URL,
+		Start:       &gcalendar.EventDateTime{DateTime: event.Start.Format(time.RFC3339), TimeZone: tz},
+		End:         &gcalendar.EventDateTime{DateTime: event.End.Format(time.RFC3339), TimeZone: tz},
+		Source:      &gcalendar.EventSource{Title: event.Summary, Url: event.URL},
+	}
+	if event.URL != "" {
+		out.Description = strings.TrimSpace(event.Description + "\n\nJoin: " + event.URL)
+	}
+	for _, email := range event.Attendees {
+		out.Attendees = append(out.Attendees, &gcalendar.EventAttendee{Email: email})
+	}
+	if event.Rule != "" {
+		out.Recurrence = []string{"RRULE:" + event.Rule}
+		if len(event.ExDates) > 0 {
+			exdates := make([]string, 0, len(event.ExDates))
+			for _, exdate := range event.ExDates {
+				exdates = append(exdates, exdate.UTC().Format("20060102T150405Z"))
+			}
+			out.Recurrence = append(out.Recurrence, "EXDATE:"+strings.Join(exdates, ","))
+		}
+	}
+	return out
+}
+
+// LocalCalendarClient keeps events in memory instead of an external calendar.
+// Consent is granted immediately: AuthCodeURL points straight back at the redirect URL.
+type LocalCalendarClient struct {
+	redirectURL string
+
+	mu     sync.Mutex
+	events map[string]calendar.Event // map[eventID]event
+}
+
+// NewLocalCalendarClient creates an empty LocalCalendarClient
+func NewLocalCalendarClient(redirectURL string) *LocalCalendarClient {
+	return &LocalCalendarClient{
+		redirectURL: redirectURL,
+		events:      make(map[string]calendar.Event),
+	}
+}
+
+// AuthCodeURL returns the redirect URL carrying a made-up code
+func (c *LocalCalendarClient) AuthCodeURL(state string) string {
+	query := url.Values{"code": {uuid.NewString()}, "state": {state}}
+	return c.redirectURL + "?" + query.Encode()
+}
+
+// Exchange accepts any code
+func (c *LocalCalendarClient) Exchange(ctx context.Context, code string) (string, error) {
+	if code == "" {
+		return "", fmt.Errorf("%w: missing authorization code", ErrInvalid)
+	}
+	return "local-" + code, nil
+}
+
+// InsertEvent stores the event
+func (c *LocalCalendarClient) InsertEvent(ctx context.Context, refreshToken string, event calendar.Event) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := uuid.NewString()
+	c.events[id] = event
+	return id, nil
+}
+
+// UpdateEvent replaces a stored event
+func (c *LocalCalendarClient) UpdateEvent(ctx context.Context, refreshToken string, eventID string, event calendar.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.events[eventID]; !exists {
+		return fmt.Errorf("%w: calendar event %s", ErrNotFound, eventID)
+	}
+	c.events[eventID] = event
+	return nil
+}
+
+// DeleteEvent forgets a stored event
+func (c *LocalCalendarClient) DeleteEvent(ctx context.Context, refreshToken string, eventID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.events, eventID)
+	return nil
+}
+
+// Events returns the stored events ordered by start
+func (c *LocalCalendarClient) Events() []calendar.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	events := slices.Collect(maps.Values(c.events))
+	slices.SortFunc(events, func(a, b calendar.Event) int {
+		return a.Start.Compare(b.Start)
+	})
+	return events
+}
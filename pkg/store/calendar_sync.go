@@ -0,0 +1,228 @@
+package store
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"open-meet/pkg/calendar"
+)
+
+const (
+	// connectStateTTL bounds how long a host may take on the consent screen
+	connectStateTTL = 10 * time.Minute
+
+	// sealedTokenPrefix marks refresh tokens stored encrypted; older rows hold them in plaintext
+	sealedTokenPrefix = "v1:"
+)
+
+// CalendarSync keeps hosts' external calendars in line with the meetings they host
+type CalendarSync interface {
+	// ConnectURL starts the OAuth consent flow for email
+	ConnectURL(email string) string
+	// Connect completes the consent flow and returns whose calendar was connected
+	Connect(ctx context.Context, state, code string) (string, error)
+	Disconnect(ctx context.Context, email string) error
+	// Push creates, updates or cancels the external event of a meeting to match it.
+	// Meetings of hosts who have not connected a calendar are skipped.
+	Push(ctx context.Context, code string) error
+}
+
+// calendarSync implements CalendarSync interface
+type calendarSync struct {
+	client     CalendarClient
+	registry   MeetingRegistry
+	meetingURL string
+	stateKey   []byte      // signs the OAuth state so a callback can only connect the account that started it
+	tokens     cipher.AEAD // encrypts refresh tokens at rest
+	locks      roomLocks   // serializes pushes per meeting so concurrent changes never create two events
+}
+
+// NewCalendarSync creates a calendar sync pushing through client. Join links in the
+// events are meetingURL followed by the meeting code. tokenKey is the 32-byte AES key
+// refresh tokens are stored under.
+func NewCalendarSync(client CalendarClient, registry MeetingRegistry, meetingURL string, stateKey, tokenKey []byte) (*calendarSync, error) {
+	block, err := aes.NewCipher(tokenKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar token key: %w", err)
+	}
+	tokens, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar token key: %w", err)
+	}
+	return &calendarSync{
+		client:     client,
+		registry:   registry,
+		meetingURL: strings.TrimSuffix(meetingURL, "/"),
+		stateKey:   stateKey,
+		tokens:     tokens,
+	}, nil
+}
+
+// ConnectURL returns the consent page for email's calendar
+func (s *calendarSync) ConnectURL(email string) string {
+	return s.client.AuthCodeURL(s.signState(email, time.Now().Add(connectStateTTL)))
+}
+
+// Connect verifies the state, exchanges the code and stores the resulting refresh token
+func (s *calendarSync) Connect(ctx context.Context, state, code string) (string, error) {
+	email, err := s.verifyState(state, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	refreshToken, err := s.client.Exchange(ctx, code)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := s.sealToken(refreshToken)
+	if err != nil {
+		return "", err
+	}
+	if err := s.registry.SaveCalendarAccount(ctx, email, sealed); err != nil {
+		return "", fmt.Errorf("failed to save calendar account: %w", err)
+	}
+	return email, nil
+}
+
+// Disconnect forgets email's refresh token; events already pushed stay in the calendar
+func (s *calendarSync) Disconnect(ctx context.Context, email string) error {
+	if err := s.registry.DeleteCalendarAccount(ctx, email); err != nil {
+		return fmt.Errorf("failed to delete calendar account: %w", err)
+	}
+	return nil
+}
+
+// Push makes the external event of the meeting match its current state
+func (s *calendarSync) Push(ctx context.Context, code string) error {
+	unlock := s.locks.lock(code)
+	defer unlock()
+
+	meeting, found, err := s.registry.GetMeeting(ctx, code)
+	if err != nil {
+		return fmt.Errorf("failed to get meeting: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("%w: meeting %s", ErrNotFound, code)
+	}
+
+	sealed, connected, err := s.registry.GetCalendarAccount(ctx, meeting.HostEmail)
+	if err != nil {
+		return fmt.Errorf("failed to get calendar account: %w", err)
+	}
+	if !connected {
+		return nil
+	}
+	refreshToken, err := s.openToken(sealed)
+	if err != nil {
+		return err
+	}
+
+	eventID, pushed, err := s.registry.GetCalendarEvent(ctx, meeting.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get calendar event: %w", err)
+	}
+
+	switch {
+	case meeting.Status == MeetingCancelled:
+		if !pushed {
+			return nil
+		}
+		if err := s.client.DeleteEvent(ctx, refreshToken, eventID); err != nil {
+			return err
+		}
+		return s.registry.DeleteCalendarEvent(ctx, meeting.ID)
+	case pushed:
+		return s.client.UpdateEvent(ctx, refreshToken, eventID, s.event(meeting))
+	default:
+		eventID, err := s.client.InsertEvent(ctx, refreshToken, s.event(meeting))
+		if err != nil {
+			return err
+		}
+		return s.registry.SetCalendarEvent(ctx, meeting.ID, eventID)
+	}
+}
+
+// event is the meeting, or the series as a whole, as one external event
+func (s *calendarSync) event(meeting *Meeting) calendar.Event {
+	return meeting.CalendarEvents(s.meetingURL + "/" + meeting.Code)[0]
+}
+
+// signState binds the consent flow to email until expiry
+func (s *calendarSync) signState(email string, expiry time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(email + "|" + strconv.FormatInt(expiry.Unix(), 10)))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.stateMAC(payload))
+}
+
+// verifyState returns the email a state was issued to
+func (s *calendarSync) verifyState(state string, now time.Time) (string, error) {
+	payload, signature, ok := strings.Cut(state, ".")
+	if !ok {
+		return "", fmt.Errorf("%w: malformed consent state", ErrUnauthorized)
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.stateMAC(payload)) {
+		return "", fmt.Errorf("%w: invalid consent state", ErrUnauthorized)
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed consent state", ErrUnauthorized)
+	}
+	sep := strings.LastIndexByte(string(decoded), '|')
+	if sep < 0 {
+		return "", fmt.Errorf("%w: malformed consent state", ErrUnauthorized)
+	}
+	email := string(decoded[:sep])
+	expiry, err := strconv.ParseInt(string(decoded[sep+1:]), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed consent state", ErrUnauthorized)
+	}
+	if now.After(time.Unix(expiry, 0)) {
+		return "", fmt.Errorf("%w: consent state expired", ErrUnauthorized)
+	}
+	return email, nil
+}
+
+func (s *calendarSync) stateMAC(payload string) []byte {
+	mac := hmac.New(sha256.New, s.stateKey)
+	mac.Write([]byte("calendar-connect|" + payload))
+	return mac.Sum(nil)
+}
+
+// sealToken encrypts a refresh token for storage
+func (s *calendarSync) sealToken(token string) (string, error) {
+	nonce := make([]byte, s.tokens.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := s.tokens.Seal(nonce, nonce, []byte(token), []byte("calendar-refresh-token"))
+	return sealedTokenPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// openToken decrypts a stored refresh token. Tokens saved before encryption are
+// returned as they are until the host reconnects.
+func (s *calendarSync) openToken(stored string) (string, error) {
+	encoded, ok := strings.CutPrefix(stored, sealedTokenPrefix)
+	if !ok {
+		return stored, nil
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < s.tokens.NonceSize() {
+		return "", fmt.Errorf("malformed stored calendar token")
+	}
+	nonce, ciphertext := sealed[:s.tokens.NonceSize()], sealed[s.tokens.NonceSize():]
+	token, err := s.tokens.Open(nil, nonce, ciphertext, []byte("calendar-refresh-token"))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt calendar token: %w", err)
+	}
+	return string(token), nil
+}
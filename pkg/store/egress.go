@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"google.golang.org/protobuf/proto"
+)
+
+// EgressClient is the part of the LiveKit Egress API used for recordings.
+// *lksdk.EgressClient satisfies it; LocalEgressClient stands in for it without a LiveKit server.
+type EgressClient interface {
+	StartRoomCompositeEgress(ctx context.Context, req *livekit.RoomCompositeEgressRequest) (*livekit.EgressInfo, error)
+	StartTrackEgress(ctx context.Context, req *livekit.TrackEgressRequest) (*livekit.EgressInfo, error)
+	StopEgress(ctx context.Context, req *livekit.StopEgressRequest) (*livekit.EgressInfo, error)
+}
+
+// NewEgressClient returns the egress client selected by driver: "livekit" (the default) or "local"
+func NewEgressClient(driver string) (EgressClient, error) {
+	switch driver {
+	case "", "livekit":
+		hostURL := os.Getenv("LIVEKIT_SERVER")
+		apiKey := os.Getenv("LIVEKIT_API_KEY")
+		apiSecret := os.Getenv("LIVEKIT_API_SECRET")
+
+		if hostURL == "" || apiKey == "" || apiSecret == "" {
+			return nil, fmt.Errorf("missing required LiveKit environment variables")
+		}
+		return lksdk.NewEgressClient(hostURL, apiKey, apiSecret), nil
+	case "local":
+		return NewLocalEgressClient(), nil
+	default:
+		return nil, fmt.Errorf("unsupported egress driver %q", driver)
+	}
+}
+
+// LocalEgressClient pretends to record: egresses become active immediately and complete
+// with a file result when stopped. Nothing is written to disk and no webhooks are sent.
+type LocalEgressClient struct {
+	mu       sync.Mutex
+	egresses map[string]*livekit.EgressInfo // map[egressID]info
+}
+
+// NewLocalEgressClient creates an empty LocalEgressClient
+func NewLocalEgressClient() *LocalEgressClient {
+	return &LocalEgressClient{egresses: make(map[string]*livekit.EgressInfo)}
+}
+
+// StartRoomCompositeEgress records the whole room into the first requested file output
+func (c *LocalEgressClient) StartRoomCompositeEgress(ctx context.Context, req *livekit.RoomCompositeEgressRequest) (*livekit.EgressInfo, error) {
+	filepath := "{room_name}-{time}.mp4"
+	if len(req.GetFileOutputs()) > 0 && req.GetFileOutputs()[0].GetFilepath() != "" {
+		filepath = req.GetFileOutputs()[0].GetFilepath()
+	}
+	return c.start(req.GetRoomName(), filepath, &livekit.EgressInfo{
+		SourceType: livekit.EgressSourceType_EGRESS_SOURCE_TYPE_WEB,
+		Request:    &livekit.EgressInfo_RoomComposite{RoomComposite: req},
+	}), nil
+}
+
+// StartTrackEgress records a single track into the requested file
+func (c *LocalEgressClient) StartTrackEgress(ctx context.Context, req *livekit.TrackEgressRequest) (*livekit.EgressInfo, error) {
+	filepath := "{track_id}-{time}"
+	if req.GetFile().GetFilepath() != "" {
+		filepath = req.GetFile().GetFilepath()
+	}
+	filepath = strings.ReplaceAll(filepath, "{track_id}", req.GetTrackId())
+	return c.start(req.GetRoomName(), filepath, &livekit.EgressInfo{
+		SourceType: livekit.EgressSourceType_EGRESS_SOURCE_TYPE_SDK,
+		Request:    &livekit.EgressInfo_Track{Track: req},
+	}), nil
+}
+
+// StopEgress completes an active egress and reports its file
+func (c *LocalEgressClient) StopEgress(ctx context.Context, req *livekit.StopEgressRequest) (*livekit.EgressInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, exists := c.egresses[req.GetEgressId()]
+	if !exists {
+		return nil, fmt.Errorf("%w: egress %s", ErrNotFound, req.GetEgressId())
+	}
+	if info.GetStatus() != livekit.EgressStatus_EGRESS_ACTIVE {
+		return nil, fmt.Errorf("%w: egress %s is %s", ErrConflict, req.GetEgressId(), info.GetStatus())
+	}
+
+	now := time.Now()
+	info.Status = livekit.EgressStatus_EGRESS_COMPLETE
+	info.EndedAt = now.UnixNano()
+	info.UpdatedAt = now.UnixNano()
+	file := info.GetFileResults()[0]
+	file.EndedAt = info.EndedAt
+	file.Duration = info.EndedAt - info.StartedAt
+
+	return cloneEgressInfo(info), nil
+}
+
+func (c *LocalEgressClient) start(roomName, filepath string, info *livekit.EgressInfo) *livekit.EgressInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	filepath = strings.NewReplacer(
+		"{room_name}", roomName,
+		"{time}", now.UTC().Format("2006-01-02T150405"),
+	).Replace(filepath)
+
+	info.EgressId = "EG_" + strings.ReplaceAll(uuid.NewString(), "-", "")[:12]
+	info.RoomName = roomName
+	info.Status = livekit.EgressStatus_EGRESS_ACTIVE
+	info.StartedAt = now.UnixNano()
+	info.UpdatedAt = now.UnixNano()
+	info.FileResults = []*livekit.FileInfo{{
+		Filename:  path.Base(filepath),
+		Location:  "file://" + filepath,
+		StartedAt: info.StartedAt,
+	}}
+	c.egresses[info.EgressId] = info
+
+	return cloneEgressInfo(info)
+}
+
+func cloneEgressInfo(info *livekit.EgressInfo) *livekit.EgressInfo {
+	return proto.Clone(info).(*livekit.EgressInfo)
+}
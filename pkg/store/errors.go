@@ -0,0 +1,28 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/twitchtv/twirp"
+)
+
+var (
+	// ErrUnauthorized is returned when the caller lacks the rights for an operation
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound is returned when a room or participant does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when an operation conflicts with the current room state
+	ErrConflict = errors.New("conflict")
+	// ErrInvalid is returned when an operation is given malformed input
+	ErrInvalid = errors.New("invalid")
+)
+
+// translateError maps LiveKit API errors onto the store's sentinel errors
+func translateError(err error, format string, args ...any) error {
+	var twerr twirp.Error
+	if errors.As(err, &twerr) && twerr.Code() == twirp.NotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, fmt.Sprintf(format, args...))
+	}
+	return fmt.Errorf(format+": %w", append(args, err)...)
+}
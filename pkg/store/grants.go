@@ -0,0 +1,163 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+)
+
+// DefaultTokenTTL is how long a join token stays valid when no TTL is configured
+const DefaultTokenTTL = 1 * time.Hour
+
+// TokenOptions carries what the caller knows about a participant when requesting a join token.
+// Grants themselves are derived from the participant's role and the room policy.
+type TokenOptions struct {
+	Name   string        // display name shown to other participants
+	Avatar string        // picture URL from the identity provider
+	Hidden bool          // join as an invisible observer, e.g. for a recorder; hosts only
+	Guest  bool          // joined without an account; restricted to camera and microphone
+	TTL    time.Duration // overrides the default token lifetime when set
+}
+
+// publishSourceNames maps policy names onto LiveKit track sources
+var publishSourceNames = map[string]livekit.TrackSource{
+	"camera":             livekit.TrackSource_CAMERA,
+	"microphone":         livekit.TrackSource_MICROPHONE,
+	"screen_share":       livekit.TrackSource_SCREEN_SHARE,
+	"screen_share_audio": livekit.TrackSource_SCREEN_SHARE_AUDIO,
+}
+
+// allPublishSources is what participants may publish when the room sets no restriction
+var allPublishSources = []string{"camera", "microphone", "screen_share", "screen_share_audio"}
+
+// ParsePublishSources validates track source names used in room policy
+func ParsePublishSources(names []string) ([]livekit.TrackSource, error) {
+	sources := make([]livekit.TrackSource, 0, len(names))
+	for _, name := range names {
+		source, ok := publishSourceNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown track source %q", name)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// DefaultMuteSource is the track source muted when a request names none
+const DefaultMuteSource = "microphone"
+
+// ParseTrackSource validates a single track source name, defaulting to DefaultMuteSource
+func ParseTrackSource(name string) (livekit.TrackSource, error) {
+	if name == "" {
+		name = DefaultMuteSource
+	}
+	source, ok := publishSourceNames[name]
+	if !ok {
+		return livekit.TrackSource_UNKNOWN, fmt.Errorf("%w: unknown track source %q", ErrInvalid, name)
+	}
+	return source, nil
+}
+
+// videoGrant derives the LiveKit permissions of a participant from their role and the room policy
+func videoGrant(roomName, identity string, role Role, settings RoomSettings, hidden bool) (*auth.VideoGrant, error) {
+	grant := &auth.VideoGrant{
+		RoomJoin: true,
+		Room:     roomName,
+	}
+	grant.SetCanSubscribe(true)
+
+	switch {
+	case hidden:
+		// Observers see everything and are seen by nobody
+		grant.Hidden = true
+		grant.Recorder = true
+		grant.SetCanPublish(false)
+		grant.SetCanPublishData(false)
+	case role.Outranks(RoleParticipant):
+		// Only the owner may administer the room through LiveKit directly; co-hosts and
+		// moderators go through the service, which checks the permission matrix
+		grant.RoomAdmin = role == RoleOwner
+		grant.SetCanPublish(true)
+		grant.SetCanPublishData(true)
+	case role == RoleViewer:
+		grant.SetCanPublish(false)
+		grant.SetCanPublishData(false)
+	default:
+		grant.SetCanPublish(true)
+		grant.SetCanPublishData(true)
+		sources, err := participantPublishSources(settings, identity)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case sources == nil:
+		case len(sources) == 0:
+			// An empty source list would allow everything
+			grant.SetCanPublish(false)
+		default:
+			grant.SetCanPublishSources(sources)
+		}
+	}
+
+	return grant, nil
+}
+
+// restrictGuest limits a guest to their camera and microphone, within what the room allows
+func restrictGuest(grant *auth.VideoGrant) {
+	if !grant.GetCanPublish() {
+		return
+	}
+	sources := slices.DeleteFunc([]livekit.TrackSource{livekit.TrackSource_CAMERA, livekit.TrackSource_MICROPHONE},
+		func(source livekit.TrackSource) bool {
+			return !grant.GetCanPublishSource(source)
+		})
+	if len(sources) == 0 {
+		// An empty source list would allow everything
+		grant.SetCanPublish(false)
+		return
+	}
+	grant.SetCanPublishSources(sources)
+}
+
+// participantPublishSources returns the track sources a participant may publish under the
+// room's media and screen-share policies, or nil when nothing is restricted. The result
+// is empty, not nil, when the policies leave the participant nothing to publish.
+func participantPublishSources(settings RoomSettings, identity string) ([]livekit.TrackSource, error) {
+	canShare := settings.canShareScreen(identity)
+	if len(settings.PublishSources) == 0 && canShare {
+		return nil, nil
+	}
+
+	names := settings.PublishSources
+	if len(names) == 0 {
+		names = allPublishSources
+	}
+	sources, err := ParsePublishSources(names)
+	if err != nil {
+		return nil, err
+	}
+	if !canShare {
+		sources = slices.DeleteFunc(sources, func(source livekit.TrackSource) bool {
+			return source == livekit.TrackSource_SCREEN_SHARE || source == livekit.TrackSource_SCREEN_SHARE_AUDIO
+		})
+	}
+	return sources, nil
+}
+
+// tokenMetadata is the participant metadata embedded in a join token
+func tokenMetadata(role Role, opts TokenOptions) (string, error) {
+	encoded, err := json.Marshal(ParticipantMetadata{
+		DisplayName: opts.Name,
+		Avatar:      opts.Avatar,
+		Role:        role,
+		Guest:       opts.Guest,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode participant metadata: %w", err)
+	}
+	return string(encoded), nil
+}
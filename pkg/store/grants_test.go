@@ -0,0 +1,47 @@
+package store
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestVideoGrantPublishSources(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    RoomSettings
+		wantPublish bool
+		wantSources []livekit.TrackSource // nil for unrestricted
+	}{
+		{
+			name:        "unrestricted",
+			wantPublish: true,
+		},
+		{
+			name:        "hosts share screens",
+			settings:    RoomSettings{ScreenShare: ScreenShareHosts},
+			wantPublish: true,
+			wantSources: []livekit.TrackSource{livekit.TrackSource_CAMERA, livekit.TrackSource_MICROPHONE},
+		},
+		{
+			name:        "only screen sharing, reserved for hosts",
+			settings:    RoomSettings{PublishSources: []string{"screen_share"}, ScreenShare: ScreenShareHosts},
+			wantPublish: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grant, err := videoGrant("standup", "ada@example.com", RoleParticipant, tt.settings, false)
+			if err != nil {
+				t.Fatalf("videoGrant: %v", err)
+			}
+			if got := grant.GetCanPublish(); got != tt.wantPublish {
+				t.Errorf("CanPublish = %v, want %v", got, tt.wantPublish)
+			}
+			if got := grant.GetCanPublishSources(); !slices.Equal(got, tt.wantSources) {
+				t.Errorf("CanPublishSources = %v, want %v", got, tt.wantSources)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// GuestPolicy decides whether people without an account may join a room
+type GuestPolicy string
+
+const (
+	// GuestsOff keeps the room to signed-in users
+	GuestsOff GuestPolicy = "off"
+	// GuestsAllowed lets guests join like participants, through the waiting room when it is on
+	GuestsAllowed GuestPolicy = "allowed"
+	// GuestsApproval makes every guest wait for a host, even with the waiting room off
+	GuestsApproval GuestPolicy = "approval"
+)
+
+const (
+	// DefaultGuestTokenTTL keeps guest join tokens short-lived; LiveKit renews them once connected
+	DefaultGuestTokenTTL = 10 * time.Minute
+	// guestPassTTL bounds how long a guest may wait for approval or reconnect with the same identity
+	guestPassTTL = 4 * time.Hour
+	// guestPassAudience tells guest passes apart from the service's access tokens
+	guestPassAudience = "open-meet-guest"
+
+	guestIdentityPrefix = "guest-"
+	maxGuestNameLength  = 64
+)
+
+// ParseGuestPolicy validates a guest policy name
+func ParseGuestPolicy(name string) (GuestPolicy, error) {
+	switch policy := GuestPolicy(name); policy {
+	case GuestsOff, GuestsAllowed, GuestsApproval:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: unknown guest policy %q", ErrInvalid, name)
+	}
+}
+
+// guestPolicy returns the room's policy, falling back to GuestsOff
+func (s RoomSettings) guestPolicy() GuestPolicy {
+	if s.Guests == "" {
+		return GuestsOff
+	}
+	return s.Guests
+}
+
+// IsGuestIdentity reports whether a participant identity was handed to a guest. Guest
+// identities are never emails, so signed-in users whose address starts with the prefix are not guests.
+func IsGuestIdentity(identity string) bool {
+	return strings.HasPrefix(identity, guestIdentityPrefix) && !strings.Contains(identity, "@")
+}
+
+// SetGuestPolicy changes whether the room admits guests. Guests already inside stay.
+func (h *host) SetGuestPolicy(ctx context.Context, roomName string, hostEmail string, policy GuestPolicy) error {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermAdmitParticipants, "configure guest access"); err != nil {
+		return err
+	}
+
+	if err := h.registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		settings.Guests = policy
+	}); err != nil {
+		return fmt.Errorf("failed to store guest policy: %w", err)
+	}
+
+	if _, err := h.metadata.PublishRoom(ctx, roomName); err != nil {
+		return fmt.Errorf("failed to publish guest policy: %w", err)
+	}
+
+	return nil
+}
+
+// RequestGuestJoin decides whether a guest may be issued a join token, queueing them
+// for a host under the room's guest policy and waiting room
+func (h *host) RequestGuestJoin(ctx context.Context, roomName, identity, displayName string) (AdmissionStatus, error) {
+	record, found, err := h.registry.GetRoom(ctx, roomName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get room: %w", err)
+	}
+	// Unlike signed-in users, guests only get into rooms whose host opted in
+	if !found || record.Settings.guestPolicy() == GuestsOff {
+		return "", fmt.Errorf("%w: room %s does not admit guests", ErrUnauthorized, roomName)
+	}
+
+	if err := h.checkLock(ctx, record, identity); err != nil {
+		return "", err
+	}
+
+	if record.Settings.guestPolicy() == GuestsAllowed && !record.Settings.WaitingRoom {
+		return AdmissionApproved, nil
+	}
+
+	status, err := h.registry.RequestAdmission(ctx, roomName, identity, displayName)
+	if err != nil {
+		return "", fmt.Errorf("failed to queue admission: %w", err)
+	}
+	return status, nil
+}
+
+// GuestAdmission is the outcome of a guest's request to join
+type GuestAdmission struct {
+	Identity string
+	Name     string
+	Pass     string          // presented on the next request to stay the same guest
+	Status   AdmissionStatus // pending or approved; denied guests get ErrUnauthorized
+	Token    string          // LiveKit join token, once approved
+}
+
+// Guests lets people without an account join rooms whose host allows it
+type Guests interface {
+	// Join asks to let a guest into the room. A new guest gives a display name and
+	// is assigned an identity; a returning guest presents the pass from last time.
+	Join(ctx context.Context, roomName, name, pass string) (*GuestAdmission, error)
+}
+
+// guests implements Guests interface
+type guests struct {
+	host        Host
+	participant Participant
+	key         []byte
+}
+
+// guestClaims are the claims of a guest pass
+type guestClaims struct {
+	Name string `json:"name"`
+	Room string `json:"room"`
+	jwt.RegisteredClaims
+}
+
+// NewGuests creates a Guests signing guest passes with key
+func NewGuests(host Host, participant Participant, key []byte) *guests {
+	return &guests{host: host, participant: participant, key: key}
+}
+
+// Join admits the guest or queues them for a host
+func (g *guests) Join(ctx context.Context, roomName, name, pass string) (*GuestAdmission, error) {
+	admission := &GuestAdmission{Pass: pass}
+	if pass != "" {
+		claims, err := g.verifyPass(pass, roomName)
+		if err != nil {
+			return nil, err
+		}
+		admission.Identity, admission.Name = claims.Subject, claims.Name
+	} else {
+		name = strings.TrimSpace(name)
+		if name == "" || utf8.RuneCountInString(name) > maxGuestNameLength {
+			return nil, fmt.Errorf("%w: display name must be 1 to %d characters", ErrInvalid, maxGuestNameLength)
+		}
+		admission.Identity, admission.Name = guestIdentityPrefix+uuid.NewString(), name
+
+		signed, err := g.signPass(admission.Identity, name, roomName)
+		if err != nil {
+			return nil, err
+		}
+		admission.Pass = signed
+	}
+
+	status, err := g.host.RequestGuestJoin(ctx, roomName, admission.Identity, admission.Name)
+	if err != nil {
+		return nil, err
+	}
+	admission.Status = status
+	switch status {
+	case AdmissionDenied:
+		return nil, fmt.Errorf("%w: host denied admission", ErrUnauthorized)
+	case AdmissionPending:
+		return admission, nil
+	}
+
+	token, err := g.participant.GenerateToken(ctx, roomName, admission.Identity, TokenOptions{
+		Name:  admission.Name,
+		Guest: true,
+		TTL:   DefaultGuestTokenTTL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	admission.Token = token
+	return admission, nil
+}
+
+func (g *guests) signPass(identity, name, roomName string) (string, error) {
+	now := time.Now()
+	claims := &guestClaims{
+		Name: name,
+		Room: roomName,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    SessionIssuer,
+			Subject:   identity,
+			Audience:  jwt.ClaimStrings{guestPassAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(guestPassTTL)),
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(g.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign guest pass: %w", err)
+	}
+	return signed, nil
+}
+
+// verifyPass checks a guest pass was issued for roomName
+func (g *guests) verifyPass(pass, roomName string) (*guestClaims, error) {
+	claims := &guestClaims{}
+	_, err := jwt.ParseWithClaims(pass, claims, func(*jwt.Token) (any, error) {
+		return g.key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(SessionIssuer),
+		jwt.WithAudience(guestPassAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid guest pass: %v", ErrUnauthorized, err)
+	}
+	if claims.Room != roomName || !IsGuestIdentity(claims.Subject) {
+		return nil, fmt.Errorf("%w: guest pass was issued for another room", ErrUnauthorized)
+	}
+	return claims, nil
+}
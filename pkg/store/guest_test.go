@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"open-meet/pkg/store/storetest"
+)
+
+func TestIsGuestIdentity(t *testing.T) {
+	tests := []struct {
+		identity string
+		want     bool
+	}{
+		{identity: guestIdentityPrefix + "0b7c6f3e-8d2a-4f7e-9a51-3c2e1d0f9b84", want: true},
+		{identity: "guest-relations@corp.example", want: false},
+		{identity: "ada@example.com", want: false},
+	}
+	for _, tt := range tests {
+		if got := IsGuestIdentity(tt.identity); got != tt.want {
+			t.Errorf("IsGuestIdentity(%q) = %v, want %v", tt.identity, got, tt.want)
+		}
+	}
+}
+
+func TestHandOffToUserNamedLikeGuest(t *testing.T) {
+	ctx := context.Background()
+	const successor = "guest-relations@corp.example"
+	registry := NewMemoryRoomRegistry()
+	if err := registry.CreateRoom(ctx, metadataRoom, metadataHost); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RecordJoin(ctx, metadataRoom, successor, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	server := storetest.NewRoomService()
+	server.Join(metadataRoom, successor)
+	h := &host{
+		client:     server,
+		registry:   registry,
+		metadata:   &metadataEditor{client: server, registry: registry, recordings: NewMemoryRecordingRegistry()},
+		succession: SuccessionLongestPresent,
+	}
+
+	newHost, err := h.HandOff(ctx, metadataRoom, metadataHost)
+	if err != nil {
+		t.Fatalf("HandOff: %v", err)
+	}
+	if newHost != successor {
+		t.Errorf("new host = %q, want %q", newHost, successor)
+	}
+}
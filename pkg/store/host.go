@@ -2,9 +2,10 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/mail"
 	"os"
-	"sync"
 
 	"github.com/livekit/protocol/livekit"
 	lksdk "github.com/livekit/server-sdk-go/v2"
@@ -19,24 +20,52 @@ type Host interface {
 
 	// Participant management
 	KickParticipant(ctx context.Context, roomName string, hostEmail string, participantIdentity string) error
-	MuteParticipant(ctx context.Context, roomName string, hostEmail string, participantIdentity string) error
-	UnmuteParticipant(ctx context.Context, roomName string, hostEmail string, participantIdentity string) error
+	MuteParticipant(ctx context.Context, roomName string, hostEmail string, participantIdentity string, source string) error
+	UnmuteParticipant(ctx context.Context, roomName string, hostEmail string, participantIdentity string, source string) error
+	MuteAll(ctx context.Context, roomName string, hostEmail string, source string) ([]string, error)
 
 	// Host management
 	AssignHost(ctx context.Context, roomName string, currentHostEmail string, newHostEmail string) error
-	IsHost(roomName, email string) bool
-	GetRoomHost(roomName string) (string, bool)
+	IsHost(ctx context.Context, roomName, email string) (bool, error)
+	GetRoomHost(ctx context.Context, roomName string) (string, bool, error)
+
+	// Roles
+	AddCoHost(ctx context.Context, roomName string, hostEmail string, email string, role Role) error
+	RemoveCoHost(ctx context.Context, roomName string, hostEmail string, email string) error
+	GetRole(ctx context.Context, roomName, email string) (Role, error)
+	ListRoles(ctx context.Context, roomName string) (map[string]Role, error)
+
+	// Admission
+	RequestJoin(ctx context.Context, roomName, email string) (AdmissionStatus, error)
+	SetWaitingRoom(ctx context.Context, roomName string, hostEmail string, enabled bool) error
+	ListAdmissions(ctx context.Context, roomName string, hostEmail string, status AdmissionStatus) ([]Admission, error)
+	ApproveAdmission(ctx context.Context, roomName string, hostEmail string, email string) error
+	DenyAdmission(ctx context.Context, roomName string, hostEmail string, email string) error
+	SetGuestPolicy(ctx context.Context, roomName string, hostEmail string, policy GuestPolicy) error
+	RequestGuestJoin(ctx context.Context, roomName, identity, displayName string) (AdmissionStatus, error)
+
+	// Policy
+	SetPublishSources(ctx context.Context, roomName string, hostEmail string, sources []string) error
+	SetScreenSharePolicy(ctx context.Context, roomName string, hostEmail string, policy ScreenSharePolicy) error
+	GrantPresenter(ctx context.Context, roomName string, hostEmail string, identity string) error
+	RevokePresenter(ctx context.Context, roomName string, hostEmail string, identity string) error
+	GetScreenShareState(ctx context.Context, roomName string, hostEmail string) (*ScreenShareState, error)
+
+	// Succession
+	SetSuccessionPolicy(ctx context.Context, roomName string, hostEmail string, policy SuccessionPolicy) error
+	HandOff(ctx context.Context, roomName string, departedEmail string) (string, error)
 }
 
 // host implements Host interface
 type host struct {
-	client *lksdk.RoomServiceClient
-	mu     sync.RWMutex
-	hosts  map[string]string // map[roomName]hostEmail
+	client     RoomServiceClient
+	metadata   *metadataEditor
+	registry   RoomRegistry
+	succession SuccessionPolicy // default when a room has no policy of its own
 }
 
 // NewHost creates a new host instance
-func NewHost() (*host, error) {
+func NewHost(registry RoomRegistry, metadata *metadataEditor, succession SuccessionPolicy) (*host, error) {
 	hostURL := os.Getenv("LIVEKIT_SERVER")
 	apiKey := os.Getenv("LIVEKIT_API_KEY")
 	apiSecret := os.Getenv("LIVEKIT_API_SECRET")
@@ -48,43 +77,47 @@ func NewHost() (*host, error) {
 	client := lksdk.NewRoomServiceClient(hostURL, apiKey, apiSecret)
 
 	return &host{
-		client: client,
-		hosts:  make(map[string]string),
+		client:     client,
+		metadata:   metadata,
+		registry:   registry,
+		succession: succession,
 	}, nil
 }
 
 // EndMeeting terminates the meeting for all participants
 func (h *host) EndMeeting(ctx context.Context, roomName string, hostEmail string) error {
-	if !h.IsHost(roomName, hostEmail) {
-		return fmt.Errorf("unauthorized: only host can end meeting")
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermEndMeeting, "end the meeting"); err != nil {
+		return err
 	}
 
 	_, err := h.client.DeleteRoom(ctx, &livekit.DeleteRoomRequest{
 		Room: roomName,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to end meeting: %w", err)
+		return translateError(err, "failed to end meeting")
 	}
 
 	// Remove host mapping
-	h.mu.Lock()
-	delete(h.hosts, roomName)
-	h.mu.Unlock()
+	if err := h.registry.DeleteRoom(ctx, roomName); err != nil {
+		return fmt.Errorf("failed to forget ended room: %w", err)
+	}
 
 	return nil
 }
 
 // LockRoom prevents new participants from joining
 func (h *host) LockRoom(ctx context.Context, roomName string, hostEmail string) error {
-	if !h.IsHost(roomName, hostEmail) {
-		return fmt.Errorf("unauthorized: only host can lock room")
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermLockRoom, "lock the room"); err != nil {
+		return err
 	}
 
-	_, err := h.client.UpdateRoomMetadata(ctx, &livekit.UpdateRoomMetadataRequest{
-		Room:     roomName,
-		Metadata: `{"locked": true}`,
-	})
-	if err != nil {
+	if err := h.registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		settings.Locked = true
+	}); err != nil {
+		return fmt.Errorf("failed to store lock state: %w", err)
+	}
+
+	if _, err := h.metadata.PublishRoom(ctx, roomName); err != nil {
 		return fmt.Errorf("failed to lock room: %w", err)
 	}
 
@@ -93,15 +126,17 @@ func (h *host) LockRoom(ctx context.Context, roomName string, hostEmail string)
 
 // UnlockRoom allows new participants to join
 func (h *host) UnlockRoom(ctx context.Context, roomName string, hostEmail string) error {
-	if !h.IsHost(roomName, hostEmail) {
-		return fmt.Errorf("unauthorized: only host can unlock room")
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermLockRoom, "unlock the room"); err != nil {
+		return err
 	}
 
-	_, err := h.client.UpdateRoomMetadata(ctx, &livekit.UpdateRoomMetadataRequest{
-		Room:     roomName,
-		Metadata: `{"locked": false}`,
-	})
-	if err != nil {
+	if err := h.registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		settings.Locked = false
+	}); err != nil {
+		return fmt.Errorf("failed to store lock state: %w", err)
+	}
+
+	if _, err := h.metadata.PublishRoom(ctx, roomName); err != nil {
 		return fmt.Errorf("failed to unlock room: %w", err)
 	}
 
@@ -110,13 +145,13 @@ func (h *host) UnlockRoom(ctx context.Context, roomName string, hostEmail string
 
 // KickParticipant removes a participant from the room
 func (h *host) KickParticipant(ctx context.Context, roomName string, hostEmail string, participantIdentity string) error {
-	if !h.IsHost(roomName, hostEmail) {
-		return fmt.Errorf("unauthorized: only host can kick participants")
-	}
-
 	// Prevent host from kicking themselves
 	if participantIdentity == hostEmail {
-		return fmt.Errorf("host cannot kick themselves")
+		return fmt.Errorf("%w: host cannot kick themselves", ErrConflict)
+	}
+
+	if err := h.authorizeOver(ctx, roomName, hostEmail, participantIdentity, PermKickParticipant, "kick participants"); err != nil {
+		return err
 	}
 
 	_, err := h.client.RemoveParticipant(ctx, &livekit.RoomParticipantIdentity{
@@ -124,80 +159,198 @@ func (h *host) KickParticipant(ctx context.Context, roomName string, hostEmail s
 		Identity: participantIdentity,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to kick participant: %w", err)
+		return translateError(err, "failed to kick participant")
 	}
 
 	return nil
 }
 
-// MuteParticipant disables a participant's audio
-func (h *host) MuteParticipant(ctx context.Context, roomName string, hostEmail string, participantIdentity string) error {
-	if !h.IsHost(roomName, hostEmail) {
-		return fmt.Errorf("unauthorized: only host can mute participants")
+// MuteParticipant mutes the participant's published tracks of the given source, e.g. "microphone"
+func (h *host) MuteParticipant(ctx context.Context, roomName string, hostEmail string, participantIdentity string, source string) error {
+	if err := h.authorizeOver(ctx, roomName, hostEmail, participantIdentity, PermMuteParticipant, "mute participants"); err != nil {
+		return err
 	}
+	return h.setTracksMuted(ctx, roomName, participantIdentity, source, true)
+}
 
-	// Get the participant's tracks
-	_, err := h.client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
-		Room:     roomName,
-		Identity: participantIdentity,
-		Metadata: `{"audio": false}`,
-		Permission: &livekit.ParticipantPermission{
-			CanPublish: false,
-		},
+// UnmuteParticipant unmutes the participant's published tracks of the given source.
+// LiveKit only honours remote unmute when the server runs with enable_remote_unmute.
+func (h *host) UnmuteParticipant(ctx context.Context, roomName string, hostEmail string, participantIdentity string, source string) error {
+	if err := h.authorizeOver(ctx, roomName, hostEmail, participantIdentity, PermMuteParticipant, "unmute participants"); err != nil {
+		return err
+	}
+	return h.setTracksMuted(ctx, roomName, participantIdentity, source, false)
+}
+
+func (h *host) setTracksMuted(ctx context.Context, roomName, identity, source string, muted bool) error {
+	trackSource, err := ParseTrackSource(source)
+	if err != nil {
+		return err
+	}
+	return setTracksMuted(ctx, h.client, h.metadata, roomName, identity, trackSource, muted)
+}
+
+// MuteAll mutes the given source for everybody in the room the caller outranks,
+// which always leaves out the host. It returns the identities that were muted.
+func (h *host) MuteAll(ctx context.Context, roomName string, hostEmail string, source string) ([]string, error) {
+	role, err := h.authorize(ctx, roomName, hostEmail, PermMuteParticipant, "mute participants")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.client.ListParticipants(ctx, &livekit.ListParticipantsRequest{
+		Room: roomName,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to mute participant: %w", err)
+		return nil, translateError(err, "failed to list participants")
+	}
+
+	muted := make([]string, 0, len(resp.GetParticipants()))
+	for _, p := range resp.GetParticipants() {
+		targetRole, err := h.registry.GetRole(ctx, roomName, p.GetIdentity())
+		if err != nil {
+			return muted, fmt.Errorf("failed to check role: %w", err)
+		}
+		if !role.Outranks(targetRole) {
+			continue
+		}
+
+		err = h.setTracksMuted(ctx, roomName, p.GetIdentity(), source, true)
+		if errors.Is(err, ErrNotFound) {
+			// Left the room while we were working through the list
+			continue
+		}
+		if err != nil {
+			return muted, err
+		}
+		muted = append(muted, p.GetIdentity())
+	}
+
+	return muted, nil
+}
+
+// AssignHost transfers host privileges to another participant, who must be a signed-in user
+func (h *host) AssignHost(ctx context.Context, roomName string, currentHostEmail string, newHostEmail string) error {
+	if _, err := h.authorize(ctx, roomName, currentHostEmail, PermTransferOwnership, "transfer ownership"); err != nil {
+		return err
+	}
+
+	newHostEmail = NormalizeEmail(newHostEmail)
+	if addr, err := mail.ParseAddress(newHostEmail); err != nil || addr.Address != newHostEmail {
+		return fmt.Errorf("%w: invalid host email %q", ErrInvalid, newHostEmail)
+	}
+
+	if newHostEmail == currentHostEmail {
+		return fmt.Errorf("%w: %s is already the host", ErrConflict, newHostEmail)
+	}
+
+	if err := h.registry.SetHost(ctx, roomName, newHostEmail); err != nil {
+		return fmt.Errorf("failed to assign host: %w", err)
+	}
+
+	// The owner role supersedes any co-host or moderator role the new host held
+	if err := h.registry.RemoveRole(ctx, roomName, newHostEmail); err != nil {
+		return fmt.Errorf("failed to clear previous role: %w", err)
+	}
+
+	return h.publishHost(ctx, roomName)
+}
+
+// AddCoHost grants email the co-host, moderator or viewer role
+func (h *host) AddCoHost(ctx context.Context, roomName string, hostEmail string, email string, role Role) error {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermManageCoHosts, "manage co-hosts"); err != nil {
+		return err
+	}
+
+	if email == hostEmail {
+		return fmt.Errorf("%w: the owner cannot become a %s", ErrConflict, role)
+	}
+
+	if err := h.registry.SetRole(ctx, roomName, email, role); err != nil {
+		return fmt.Errorf("failed to grant role: %w", err)
 	}
 
 	return nil
 }
 
-// UnmuteParticipant enables a participant's audio
-func (h *host) UnmuteParticipant(ctx context.Context, roomName string, hostEmail string, participantIdentity string) error {
-	if !h.IsHost(roomName, hostEmail) {
-		return fmt.Errorf("unauthorized: only host can unmute participants")
+// RemoveCoHost returns email to the participant role
+func (h *host) RemoveCoHost(ctx context.Context, roomName string, hostEmail string, email string) error {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermManageCoHosts, "manage co-hosts"); err != nil {
+		return err
 	}
 
-	_, err := h.client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
-		Room:     roomName,
-		Identity: participantIdentity,
-		Metadata: `{"audio": true}`,
-		Permission: &livekit.ParticipantPermission{
-			CanPublish: true,
-		},
-	})
+	role, err := h.registry.GetRole(ctx, roomName, email)
 	if err != nil {
-		return fmt.Errorf("failed to unmute participant: %w", err)
+		return fmt.Errorf("failed to check role: %w", err)
+	}
+	if role == RoleOwner || role == RoleParticipant {
+		return fmt.Errorf("%w: %s holds no co-host, moderator or viewer role", ErrNotFound, email)
+	}
+
+	if err := h.registry.RemoveRole(ctx, roomName, email); err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
 	}
 
 	return nil
 }
 
-// AssignHost transfers host privileges to another participant
-func (h *host) AssignHost(ctx context.Context, roomName string, currentHostEmail string, newHostEmail string) error {
-	if !h.IsHost(roomName, currentHostEmail) {
-		return fmt.Errorf("unauthorized: only current host can assign new host")
+// GetRole returns the user's role in the room
+func (h *host) GetRole(ctx context.Context, roomName, email string) (Role, error) {
+	return h.registry.GetRole(ctx, roomName, email)
+}
+
+// ListRoles returns the owner, co-hosts and moderators of the room
+func (h *host) ListRoles(ctx context.Context, roomName string) (map[string]Role, error) {
+	return h.registry.ListRoles(ctx, roomName)
+}
+
+// SetPublishSources restricts the track sources participants may publish; an empty list allows all
+func (h *host) SetPublishSources(ctx context.Context, roomName string, hostEmail string, sources []string) error {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermManagePolicy, "change the media policy"); err != nil {
+		return err
 	}
 
-	h.mu.Lock()
-	h.hosts[roomName] = newHostEmail
-	h.mu.Unlock()
+	if _, err := ParsePublishSources(sources); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	if err := h.registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		settings.PublishSources = sources
+	}); err != nil {
+		return fmt.Errorf("failed to store media policy: %w", err)
+	}
 
 	return nil
 }
 
 // IsHost checks if the given email is the host of the room
-func (h *host) IsHost(roomName, email string) bool {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	host, exists := h.hosts[roomName]
-	return exists && host == email
+func (h *host) IsHost(ctx context.Context, roomName, email string) (bool, error) {
+	return h.registry.IsHost(ctx, roomName, email)
 }
 
 // GetRoomHost returns the host email for a room
-func (h *host) GetRoomHost(roomName string) (string, bool) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	host, exists := h.hosts[roomName]
-	return host, exists
+func (h *host) GetRoomHost(ctx context.Context, roomName string) (string, bool, error) {
+	return h.registry.GetRoomHost(ctx, roomName)
+}
+
+// authorize returns ErrUnauthorized unless email's role in the room grants perm
+func (h *host) authorize(ctx context.Context, roomName, email string, perm Permission, action string) (Role, error) {
+	return authorize(ctx, h.registry, roomName, email, perm, action)
+}
+
+// authorizeOver is authorize for actions on another participant, who must rank below the actor
+func (h *host) authorizeOver(ctx context.Context, roomName, email, target string, perm Permission, action string) error {
+	role, err := h.authorize(ctx, roomName, email, perm, action)
+	if err != nil {
+		return err
+	}
+
+	targetRole, err := h.registry.GetRole(ctx, roomName, target)
+	if err != nil {
+		return fmt.Errorf("failed to check role: %w", err)
+	}
+	if !role.Outranks(targetRole) {
+		return fmt.Errorf("%w: %s cannot %s with role %s", ErrUnauthorized, role, action, targetRole)
+	}
+	return nil
 }
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"open-meet/pkg/store/storetest"
+)
+
+func TestAssignHostPublishesHost(t *testing.T) {
+	ctx := context.Background()
+	registry := NewMemoryRoomRegistry()
+	if err := registry.CreateRoom(ctx, metadataRoom, metadataHost); err != nil {
+		t.Fatal(err)
+	}
+	server := storetest.NewRoomService()
+	server.Join(metadataRoom, metadataUser)
+	h := &host{
+		client:   server,
+		registry: registry,
+		metadata: &metadataEditor{client: server, registry: registry, recordings: NewMemoryRecordingRegistry()},
+	}
+
+	if err := h.AssignHost(ctx, metadataRoom, metadataHost, metadataUser); err != nil {
+		t.Fatalf("AssignHost: %v", err)
+	}
+	if got := roomMetadata(t, server).Host; got != metadataUser {
+		t.Errorf("published host = %q, want %q", got, metadataUser)
+	}
+}
+
+func TestAssignHostRejectsInvalidEmail(t *testing.T) {
+	ctx := context.Background()
+	registry := NewMemoryRoomRegistry()
+	if err := registry.CreateRoom(ctx, metadataRoom, metadataHost); err != nil {
+		t.Fatal(err)
+	}
+	h := &host{client: storetest.NewRoomService(), registry: registry}
+
+	for _, email := range []string{"", "   ", "not-an-email", guestIdentityPrefix + "ada", "Ada <ada@example.com>"} {
+		if err := h.AssignHost(ctx, metadataRoom, metadataHost, email); !errors.Is(err, ErrInvalid) {
+			t.Errorf("AssignHost(%q): err = %v, want ErrInvalid", email, err)
+		}
+	}
+	if got, _, _ := registry.GetRoomHost(ctx, metadataRoom); got != metadataHost {
+		t.Errorf("host = %q, want %q", got, metadataHost)
+	}
+}
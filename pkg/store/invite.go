@@ -0,0 +1,386 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultInviteTTL = 7 * 24 * time.Hour
+	MaxInviteTTL     = 30 * 24 * time.Hour
+
+	// inviteAudience tells invite tokens apart from the service's other tokens
+	inviteAudience = "open-meet-invite"
+)
+
+// Invite lets whoever holds its token into a room, skipping the waiting room, with
+// the role the host chose. It may be bound to one email and limited in uses.
+type Invite struct {
+	ID        string    `json:"id"`
+	RoomName  string    `json:"room_name"`
+	Email     string    `json:"email,omitempty"` // only this user may redeem it, when set
+	Role      Role      `json:"role"`
+	MaxUses   int       `json:"max_uses"` // 0 for unlimited
+	Uses      int       `json:"uses"`     // distinct users who redeemed it
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at,omitzero"`
+}
+
+// Usable reports whether the invite can still be redeemed at now by someone new
+func (i *Invite) Usable(now time.Time) bool {
+	return i.RevokedAt.IsZero() && now.Before(i.ExpiresAt) && (i.MaxUses == 0 || i.Uses < i.MaxUses)
+}
+
+// InviteOptions are the constraints a host puts on a new invite
+type InviteOptions struct {
+	Email   string
+	Role    Role          // RoleParticipant when empty
+	MaxUses int           // 0 for unlimited
+	TTL     time.Duration // DefaultInviteTTL when zero, at most MaxInviteTTL
+}
+
+// InviteRegistry persists invites and who redeemed them
+type InviteRegistry interface {
+	CreateInvite(ctx context.Context, invite *Invite) error
+	GetInvite(ctx context.Context, id string) (*Invite, bool, error)
+	ListInvites(ctx context.Context, roomName string) ([]Invite, error)
+	// RedeemInvite records that email used the invite. A user redeeming it again, e.g.
+	// to reconnect, does not count as another use. It fails with ErrConflict once the
+	// invite is revoked, expired or used up.
+	RedeemInvite(ctx context.Context, id, email string, now time.Time) (*Invite, error)
+	// CheckInvite fails like RedeemInvite would for email at now, without recording anything
+	CheckInvite(ctx context.Context, id, email string, now time.Time) error
+	RevokeInvite(ctx context.Context, id string, now time.Time) error
+}
+
+// Invites lets hosts hand out links to their rooms
+type Invites interface {
+	// Create mints an invite and returns it with its signed token
+	Create(ctx context.Context, roomName, hostEmail string, opts InviteOptions) (*Invite, string, error)
+	List(ctx context.Context, roomName, hostEmail string) ([]Invite, error)
+	Revoke(ctx context.Context, roomName, hostEmail, id string) error
+	// Redeem checks the token against the invite's constraints and lets email in
+	// with the invite's role. It returns email's admission status in the room.
+	Redeem(ctx context.Context, roomName, email, token string) (*Invite, AdmissionStatus, error)
+}
+
+// invites implements Invites interface
+type invites struct {
+	registry RoomRegistry
+	host     Host
+	invites  InviteRegistry
+	key      []byte
+}
+
+// inviteClaims are the claims of an invite token; the registry stays authoritative
+type inviteClaims struct {
+	Room  string `json:"room"`
+	Role  Role   `json:"role"`
+	Email string `json:"email,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// NewInvites creates an Invites signing invite tokens with key
+func NewInvites(registry RoomRegistry, host Host, inviteRegistry InviteRegistry, key []byte) *invites {
+	return &invites{registry: registry, host: host, invites: inviteRegistry, key: key}
+}
+
+// Create mints an invite. Handing out co-host invites takes the right to manage co-hosts.
+func (s *invites) Create(ctx context.Context, roomName, hostEmail string, opts InviteOptions) (*Invite, string, error) {
+	role := opts.Role
+	if role == "" {
+		role = RoleParticipant
+	}
+	perm := PermAdmitParticipants
+	switch role {
+	case RoleParticipant, RoleViewer:
+	case RoleCoHost:
+		perm = PermManageCoHosts
+	default:
+		return nil, "", fmt.Errorf("%w: invites cannot grant the %s role", ErrInvalid, role)
+	}
+	if _, err := authorize(ctx, s.registry, roomName, hostEmail, perm, "invite as "+string(role)); err != nil {
+		return nil, "", err
+	}
+
+	if opts.MaxUses < 0 {
+		return nil, "", fmt.Errorf("%w: max uses cannot be negative", ErrInvalid)
+	}
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = DefaultInviteTTL
+	}
+	if ttl < 0 || ttl > MaxInviteTTL {
+		return nil, "", fmt.Errorf("%w: invites expire within %s", ErrInvalid, MaxInviteTTL)
+	}
+
+	now := time.Now().UTC()
+	invite := &Invite{
+		ID:        uuid.NewString(),
+		RoomName:  roomName,
+		Email:     NormalizeEmail(opts.Email),
+		Role:      role,
+		MaxUses:   opts.MaxUses,
+		CreatedBy: hostEmail,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	claims := &inviteClaims{
+		Room:  invite.RoomName,
+		Role:  invite.Role,
+		Email: invite.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        invite.ID,
+			Issuer:    SessionIssuer,
+			Audience:  jwt.ClaimStrings{inviteAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(invite.ExpiresAt),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign invite: %w", err)
+	}
+
+	if err := s.invites.CreateInvite(ctx, invite); err != nil {
+		return nil, "", fmt.Errorf("failed to create invite: %w", err)
+	}
+	return invite, token, nil
+}
+
+// List returns the room's invites, newest first
+func (s *invites) List(ctx context.Context, roomName, hostEmail string) ([]Invite, error) {
+	if _, err := authorize(ctx, s.registry, roomName, hostEmail, PermAdmitParticipants, "view invites"); err != nil {
+		return nil, err
+	}
+
+	list, err := s.invites.ListInvites(ctx, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invites: %w", err)
+	}
+	return list, nil
+}
+
+// Revoke stops the invite from letting anyone else in. Users who already redeemed
+// it keep the role it gave them.
+func (s *invites) Revoke(ctx context.Context, roomName, hostEmail, id string) error {
+	if _, err := authorize(ctx, s.registry, roomName, hostEmail, PermAdmitParticipants, "revoke invites"); err != nil {
+		return err
+	}
+
+	invite, found, err := s.invites.GetInvite(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get invite: %w", err)
+	}
+	if !found || invite.RoomName != roomName {
+		return fmt.Errorf("%w: invite %s", ErrNotFound, id)
+	}
+
+	if err := s.invites.RevokeInvite(ctx, id, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+	return nil
+}
+
+// Redeem verifies the token, counts the use and applies the invite: its role is
+// granted unless email already ranks higher, and the waiting room is skipped.
+// A locked room refuses the invitee with ErrRoomLocked, and a host's earlier denial
+// stands with AdmissionDenied, both before the invite is used.
+func (s *invites) Redeem(ctx context.Context, roomName, email, token string) (*Invite, AdmissionStatus, error) {
+	email = NormalizeEmail(email)
+
+	claims := &inviteClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return s.key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(SessionIssuer),
+		jwt.WithAudience(inviteAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: invalid invite: %v", ErrUnauthorized, err)
+	}
+	if claims.Room != roomName {
+		return nil, "", fmt.Errorf("%w: invite was issued for another room", ErrUnauthorized)
+	}
+	if claims.Email != "" && claims.Email != email {
+		return nil, "", fmt.Errorf("%w: invite was issued to someone else", ErrUnauthorized)
+	}
+
+	// A dead invite must not queue its holder in the waiting room
+	if err := redeemError(s.invites.CheckInvite(ctx, claims.ID, email, time.Now().UTC())); err != nil {
+		return nil, "", err
+	}
+
+	// Only a join that can go ahead uses up the invite; the waiting room is skipped below
+	status, err := s.host.RequestJoin(ctx, roomName, email)
+	if err != nil {
+		return nil, "", err
+	}
+	if status == AdmissionDenied {
+		return nil, status, nil
+	}
+
+	invite, err := s.invites.RedeemInvite(ctx, claims.ID, email, time.Now().UTC())
+	if err := redeemError(err); err != nil {
+		return nil, "", err
+	}
+
+	current, err := s.registry.GetRole(ctx, roomName, email)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to check role: %w", err)
+	}
+	// Viewer invites only hold back users with no role of their own
+	if invite.Role.Outranks(current) || (invite.Role == RoleViewer && current == RoleParticipant) {
+		if err := s.registry.SetRole(ctx, roomName, email, invite.Role); err != nil {
+			return nil, "", fmt.Errorf("failed to grant role: %w", err)
+		}
+	}
+
+	// The host vouched for the invitee when creating the invite, but a denial
+	// recorded since then stands
+	status, err = s.registry.RequestAdmission(ctx, roomName, email, "")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to queue admission: %w", err)
+	}
+	if status == AdmissionPending {
+		if err := s.registry.DecideAdmission(ctx, roomName, email, AdmissionApproved, invite.CreatedBy); err != nil {
+			return nil, "", fmt.Errorf("failed to record admission decision: %w", err)
+		}
+		status = AdmissionApproved
+	}
+
+	return invite, status, nil
+}
+
+// redeemError turns the registry's refusal to redeem an invite into ErrUnauthorized
+func redeemError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return fmt.Errorf("%w: unknown invite", ErrUnauthorized)
+	case errors.Is(err, ErrConflict):
+		return fmt.Errorf("%w: invite was revoked, expired or used up", ErrUnauthorized)
+	case err != nil:
+		return fmt.Errorf("failed to redeem invite: %w", err)
+	}
+	return nil
+}
+
+// memoryInviteRegistry implements InviteRegistry in memory
+type memoryInviteRegistry struct {
+	mu        sync.Mutex
+	invites   map[string]*Invite         // map[id]invite
+	redeemers map[string]map[string]bool // map[id]set of emails
+}
+
+// NewMemoryInviteRegistry creates an empty in-memory invite registry
+func NewMemoryInviteRegistry() *memoryInviteRegistry {
+	return &memoryInviteRegistry{
+		invites:   make(map[string]*Invite),
+		redeemers: make(map[string]map[string]bool),
+	}
+}
+
+// CreateInvite stores the invite
+func (r *memoryInviteRegistry) CreateInvite(ctx context.Context, invite *Invite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *invite
+	r.invites[invite.ID] = &cp
+	return nil
+}
+
+// GetInvite returns the invite with the given ID
+func (r *memoryInviteRegistry) GetInvite(ctx context.Context, id string) (*Invite, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	invite, exists := r.invites[id]
+	if !exists {
+		return nil, false, nil
+	}
+	cp := *invite
+	return &cp, true, nil
+}
+
+// ListInvites returns the room's invites, newest first
+func (r *memoryInviteRegistry) ListInvites(ctx context.Context, roomName string) ([]Invite, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]Invite, 0)
+	for _, invite := range r.invites {
+		if invite.RoomName == roomName {
+			list = append(list, *invite)
+		}
+	}
+	slices.SortFunc(list, func(a, b Invite) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return list, nil
+}
+
+// RedeemInvite counts email's first use of the invite
+func (r *memoryInviteRegistry) RedeemInvite(ctx context.Context, id, email string, now time.Time) (*Invite, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.checkInvite(id, email, now); err != nil {
+		return nil, err
+	}
+	invite := r.invites[id]
+	if !r.redeemers[id][email] {
+		if r.redeemers[id] == nil {
+			r.redeemers[id] = make(map[string]bool)
+		}
+		r.redeemers[id][email] = true
+		invite.Uses++
+	}
+	cp := *invite
+	return &cp, nil
+}
+
+// CheckInvite reports whether email could redeem the invite at now
+func (r *memoryInviteRegistry) CheckInvite(ctx context.Context, id, email string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.checkInvite(id, email, now)
+}
+
+// checkInvite lets earlier redeemers back in until the invite is revoked or expires; callers hold r.mu
+func (r *memoryInviteRegistry) checkInvite(id, email string, now time.Time) error {
+	invite, exists := r.invites[id]
+	if !exists {
+		return ErrNotFound
+	}
+	if r.redeemers[id][email] {
+		if !invite.RevokedAt.IsZero() || !now.Before(invite.ExpiresAt) {
+			return ErrConflict
+		}
+	} else if !invite.Usable(now) {
+		return ErrConflict
+	}
+	return nil
+}
+
+// RevokeInvite marks the invite revoked
+func (r *memoryInviteRegistry) RevokeInvite(ctx context.Context, id string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	invite, exists := r.invites[id]
+	if !exists {
+		return ErrNotFound
+	}
+	if invite.RevokedAt.IsZero() {
+		invite.RevokedAt = now
+	}
+	return nil
+}
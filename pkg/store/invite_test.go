@@ -0,0 +1,289 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	inviteRoom = "standup"
+	inviteHost = "host@example.com"
+)
+
+// eachInvites runs test against a room owned by inviteHost, once per InviteRegistry
+func eachInvites(t *testing.T, test func(t *testing.T, s *invites, rooms RoomRegistry)) {
+	eachRegistry(t,
+		func() InviteRegistry { return NewMemoryInviteRegistry() },
+		func(db *sql.DB) InviteRegistry { return NewSQLInviteRegistry(db) },
+		func(t *testing.T, registry InviteRegistry) {
+			rooms := NewMemoryRoomRegistry()
+			if err := rooms.CreateRoom(context.Background(), inviteRoom, inviteHost); err != nil {
+				t.Fatal(err)
+			}
+			// RequestJoin only needs the registry, so no LiveKit client is required
+			test(t, NewInvites(rooms, &host{registry: rooms}, registry, []byte("test invite key")), rooms)
+		})
+}
+
+func createInvite(t *testing.T, s *invites, opts InviteOptions) (*Invite, string) {
+	t.Helper()
+	invite, token, err := s.Create(context.Background(), inviteRoom, inviteHost, opts)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return invite, token
+}
+
+func uses(t *testing.T, s *invites, id string) int {
+	t.Helper()
+	list, err := s.List(context.Background(), inviteRoom, inviteHost)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	for _, invite := range list {
+		if invite.ID == id {
+			return invite.Uses
+		}
+	}
+	t.Fatalf("invite %s not listed", id)
+	return 0
+}
+
+func TestInviteUsageLimit(t *testing.T) {
+	eachInvites(t, func(t *testing.T, s *invites, rooms RoomRegistry) {
+		ctx := context.Background()
+		invite, token := createInvite(t, s, InviteOptions{MaxUses: 2})
+
+		for _, email := range []string{"alice@example.com", "alice@example.com", "bob@example.com"} {
+			if _, _, err := s.Redeem(ctx, inviteRoom, email, token); err != nil {
+				t.Fatalf("Redeem as %s: %v", email, err)
+			}
+		}
+		// Reconnecting does not count as another use
+		if got := uses(t, s, invite.ID); got != 2 {
+			t.Errorf("uses = %d, want 2", got)
+		}
+
+		if _, _, err := s.Redeem(ctx, inviteRoom, "carol@example.com", token); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Redeem past the limit: err = %v, want ErrUnauthorized", err)
+		}
+		if _, _, err := s.Redeem(ctx, inviteRoom, "bob@example.com", token); err != nil {
+			t.Errorf("earlier redeemer coming back: %v", err)
+		}
+	})
+}
+
+func TestInviteExpiry(t *testing.T) {
+	eachInvites(t, func(t *testing.T, s *invites, rooms RoomRegistry) {
+		ctx := context.Background()
+		invite, token := createInvite(t, s, InviteOptions{})
+		if !invite.ExpiresAt.Equal(invite.CreatedAt.Add(DefaultInviteTTL)) {
+			t.Errorf("expires at %s, want %s after creation", invite.ExpiresAt, DefaultInviteTTL)
+		}
+		if _, _, err := s.Redeem(ctx, inviteRoom, "alice@example.com", token); err != nil {
+			t.Fatalf("Redeem: %v", err)
+		}
+
+		// The registry refuses expired invites even when the token still verifies,
+		// including to users who redeemed them before
+		expired := invite.ExpiresAt.Add(time.Second)
+		for _, email := range []string{"alice@example.com", "bob@example.com"} {
+			if _, err := s.invites.RedeemInvite(ctx, invite.ID, email, expired); !errors.Is(err, ErrConflict) {
+				t.Errorf("RedeemInvite as %s after expiry: err = %v, want ErrConflict", email, err)
+			}
+		}
+
+		_, shortLived := createInvite(t, s, InviteOptions{TTL: time.Nanosecond})
+		time.Sleep(time.Millisecond)
+		if _, _, err := s.Redeem(ctx, inviteRoom, "bob@example.com", shortLived); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Redeem an expired token: err = %v, want ErrUnauthorized", err)
+		}
+	})
+}
+
+func TestInviteBoundEmail(t *testing.T) {
+	eachInvites(t, func(t *testing.T, s *invites, rooms RoomRegistry) {
+		ctx := context.Background()
+		_, token := createInvite(t, s, InviteOptions{Email: " Alice@Example.com"})
+
+		if _, _, err := s.Redeem(ctx, inviteRoom, "bob@example.com", token); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Redeem by someone else: err = %v, want ErrUnauthorized", err)
+		}
+		if _, _, err := s.Redeem(ctx, inviteRoom, "ALICE@example.com", token); err != nil {
+			t.Errorf("Redeem by the invitee: %v", err)
+		}
+	})
+}
+
+func TestInviteRevokeAndRoom(t *testing.T) {
+	eachInvites(t, func(t *testing.T, s *invites, rooms RoomRegistry) {
+		ctx := context.Background()
+		invite, token := createInvite(t, s, InviteOptions{})
+
+		if _, _, err := s.Redeem(ctx, "another-room", "alice@example.com", token); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Redeem for another room: err = %v, want ErrUnauthorized", err)
+		}
+
+		if err := s.Revoke(ctx, inviteRoom, inviteHost, invite.ID); err != nil {
+			t.Fatalf("Revoke: %v", err)
+		}
+		if _, _, err := s.Redeem(ctx, inviteRoom, "alice@example.com", token); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Redeem after Revoke: err = %v, want ErrUnauthorized", err)
+		}
+		if err := s.Revoke(ctx, "another-room", inviteHost, invite.ID); err == nil {
+			t.Error("revoked an invite through another room")
+		}
+	})
+}
+
+func TestInviteLockedRoomKeepsInvite(t *testing.T) {
+	eachInvites(t, func(t *testing.T, s *invites, rooms RoomRegistry) {
+		ctx := context.Background()
+		invite, token := createInvite(t, s, InviteOptions{MaxUses: 1})
+		lock := func(locked bool) {
+			if err := rooms.UpdateSettings(ctx, inviteRoom, func(settings *RoomSettings) { settings.Locked = locked }); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		lock(true)
+		if _, _, err := s.Redeem(ctx, inviteRoom, "alice@example.com", token); !errors.Is(err, ErrRoomLocked) {
+			t.Fatalf("Redeem in a locked room: err = %v, want ErrRoomLocked", err)
+		}
+		if got := uses(t, s, invite.ID); got != 0 {
+			t.Fatalf("a refused join used the invite: uses = %d", got)
+		}
+
+		lock(false)
+		if _, _, err := s.Redeem(ctx, inviteRoom, "alice@example.com", token); err != nil {
+			t.Errorf("Redeem after unlocking: %v", err)
+		}
+	})
+}
+
+func TestInviteGrantsRoleAndSkipsWaitingRoom(t *testing.T) {
+	eachInvites(t, func(t *testing.T, s *invites, rooms RoomRegistry) {
+		ctx := context.Background()
+		if err := rooms.UpdateSettings(ctx, inviteRoom, func(settings *RoomSettings) { settings.WaitingRoom = true }); err != nil {
+			t.Fatal(err)
+		}
+		gate := &host{registry: rooms}
+		_, viewer := createInvite(t, s, InviteOptions{Role: RoleViewer})
+		_, cohost := createInvite(t, s, InviteOptions{Role: RoleCoHost})
+
+		for _, tt := range []struct {
+			email, token string
+			want         Role
+		}{
+			{email: "alice@example.com", token: viewer, want: RoleViewer},
+			{email: "bob@example.com", token: cohost, want: RoleCoHost},
+		} {
+			if _, status, err := s.Redeem(ctx, inviteRoom, tt.email, tt.token); err != nil || status != AdmissionApproved {
+				t.Fatalf("Redeem as %s = %s, %v; want approved", tt.email, status, err)
+			}
+			if role, err := rooms.GetRole(ctx, inviteRoom, tt.email); err != nil || role != tt.want {
+				t.Errorf("role of %s = %s, %v; want %s", tt.email, role, err, tt.want)
+			}
+			if status, err := gate.RequestJoin(ctx, inviteRoom, tt.email); err != nil || status != AdmissionApproved {
+				t.Errorf("RequestJoin as %s = %s, %v; want approved", tt.email, status, err)
+			}
+		}
+
+		// A viewer invite never demotes someone who ranks higher
+		if _, _, err := s.Redeem(ctx, inviteRoom, "bob@example.com", viewer); err != nil {
+			t.Fatalf("Redeem: %v", err)
+		}
+		if role, _ := rooms.GetRole(ctx, inviteRoom, "bob@example.com"); role != RoleCoHost {
+			t.Errorf("co-host redeeming a viewer invite became %s", role)
+		}
+	})
+}
+
+func TestInviteKeepsHostDenial(t *testing.T) {
+	eachInvites(t, func(t *testing.T, s *invites, rooms RoomRegistry) {
+		ctx := context.Background()
+		if err := rooms.UpdateSettings(ctx, inviteRoom, func(settings *RoomSettings) { settings.WaitingRoom = true }); err != nil {
+			t.Fatal(err)
+		}
+		gate := &host{registry: rooms}
+		if _, err := gate.RequestJoin(ctx, inviteRoom, "alice@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		if err := rooms.DecideAdmission(ctx, inviteRoom, "alice@example.com", AdmissionDenied, inviteHost); err != nil {
+			t.Fatal(err)
+		}
+
+		invite, token := createInvite(t, s, InviteOptions{Role: RoleCoHost, MaxUses: 1})
+		_, status, err := s.Redeem(ctx, inviteRoom, "alice@example.com", token)
+		if err != nil || status != AdmissionDenied {
+			t.Fatalf("Redeem after denial = %s, %v; want denied", status, err)
+		}
+		if got := uses(t, s, invite.ID); got != 0 {
+			t.Errorf("a denied join used the invite: uses = %d", got)
+		}
+		if role, _ := rooms.GetRole(ctx, inviteRoom, "alice@example.com"); role != RoleParticipant {
+			t.Errorf("denied invitee became %s", role)
+		}
+		if status, err := gate.RequestJoin(ctx, inviteRoom, "alice@example.com"); err != nil || status != AdmissionDenied {
+			t.Errorf("RequestJoin after Redeem = %s, %v; want denied", status, err)
+		}
+	})
+}
+
+func TestDeadInviteQueuesNothing(t *testing.T) {
+	eachInvites(t, func(t *testing.T, s *invites, rooms RoomRegistry) {
+		ctx := context.Background()
+		if err := rooms.UpdateSettings(ctx, inviteRoom, func(settings *RoomSettings) { settings.WaitingRoom = true }); err != nil {
+			t.Fatal(err)
+		}
+		usedUp, usedUpToken := createInvite(t, s, InviteOptions{MaxUses: 1})
+		if _, _, err := s.Redeem(ctx, inviteRoom, "alice@example.com", usedUpToken); err != nil {
+			t.Fatalf("Redeem: %v", err)
+		}
+		revoked, revokedToken := createInvite(t, s, InviteOptions{})
+		if err := s.Revoke(ctx, inviteRoom, inviteHost, revoked.ID); err != nil {
+			t.Fatalf("Revoke: %v", err)
+		}
+
+		for _, token := range []string{usedUpToken, revokedToken} {
+			if _, _, err := s.Redeem(ctx, inviteRoom, "bob@example.com", token); !errors.Is(err, ErrUnauthorized) {
+				t.Errorf("Redeem a dead invite: err = %v, want ErrUnauthorized", err)
+			}
+		}
+		admissions, err := rooms.ListAdmissions(ctx, inviteRoom, AdmissionPending)
+		if err != nil {
+			t.Fatalf("ListAdmissions: %v", err)
+		}
+		if len(admissions) != 0 {
+			t.Errorf("dead invites queued %v", admissions)
+		}
+		if got := uses(t, s, usedUp.ID); got != 1 {
+			t.Errorf("uses = %d, want 1", got)
+		}
+	})
+}
+
+func TestInviteCreateValidation(t *testing.T) {
+	eachInvites(t, func(t *testing.T, s *invites, rooms RoomRegistry) {
+		ctx := context.Background()
+		tests := []struct {
+			name   string
+			caller string
+			opts   InviteOptions
+			want   error
+		}{
+			{name: "not a host", caller: "alice@example.com", opts: InviteOptions{}, want: ErrUnauthorized},
+			{name: "owner role", caller: inviteHost, opts: InviteOptions{Role: RoleOwner}, want: ErrInvalid},
+			{name: "negative uses", caller: inviteHost, opts: InviteOptions{MaxUses: -1}, want: ErrInvalid},
+			{name: "too long", caller: inviteHost, opts: InviteOptions{TTL: MaxInviteTTL + time.Hour}, want: ErrInvalid},
+		}
+		for _, tt := range tests {
+			if _, _, err := s.Create(ctx, inviteRoom, tt.caller, tt.opts); !errors.Is(err, tt.want) {
+				t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+			}
+		}
+	})
+}
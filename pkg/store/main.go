@@ -1,7 +1,15 @@
 package store
 
 import (
+	"context"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
 	"sync"
+	"time"
+
+	"open-meet/pkg/config"
 )
 
 var (
@@ -14,11 +22,11 @@ var (
 )
 
 // GetHostStore returns the singleton Host instance
-func GetHostStore() (Host, error) {
+func GetHostStore(registry RoomRegistry, metadata *metadataEditor, succession SuccessionPolicy) (Host, error) {
 	var initErr error
 	hostStoreOnce.Do(func() {
 		var store *host
-		store, initErr = NewHost()
+		store, initErr = NewHost(registry, metadata, succession)
 		if initErr == nil {
 			hostStore = store
 		}
@@ -30,11 +38,11 @@ func GetHostStore() (Host, error) {
 }
 
 // GetRoomStore returns the singleton Room instance
-func GetRoomStore() (Room, error) {
+func GetRoomStore(registry RoomRegistry) (Room, error) {
 	var initErr error
 	roomStoreOnce.Do(func() {
 		var store *LiveKitRoom
-		store, initErr = NewLiveKitRoom()
+		store, initErr = NewLiveKitRoom(registry)
 		if initErr == nil {
 			roomStore = store
 		}
@@ -46,11 +54,11 @@ func GetRoomStore() (Room, error) {
 }
 
 // GetParticipantStore returns the singleton Participant instance
-func GetParticipantStore() (Participant, error) {
+func GetParticipantStore(registry RoomRegistry, metadata *metadataEditor, tokenTTL time.Duration) (Participant, error) {
 	var initErr error
 	participantOnce.Do(func() {
 		var store *participant
-		store, initErr = NewParticipant()
+		store, initErr = NewParticipant(registry, metadata, tokenTTL)
 		if initErr == nil {
 			participantStore = store
 		}
@@ -66,6 +74,14 @@ type Store interface {
 	Room() Room
 	Host() Host
 	Participant() Participant
+	Registry() RoomRegistry
+	Recorder() Recorder
+	Meetings() Meetings
+	Calendar() CalendarSync
+	Outbox() NotificationRegistry
+	Sessions() Sessions
+	Guests() Guests
+	Invites() Invites
 }
 
 // memoryStore implements Store interface
@@ -73,20 +89,183 @@ type memoryStore struct {
 	room        Room
 	host        Host
 	participant Participant
+	registry    RoomRegistry
+	recorder    Recorder
+	meetings    Meetings
+	calendar    CalendarSync
+	outbox      NotificationRegistry
+	sessions    Sessions
+	guests      Guests
+	invites     Invites
+}
+
+// sqlStore implements Store interface with room ownership and settings persisted in a SQL database
+type sqlStore struct {
+	memoryStore
+	db *sql.DB
+}
+
+// NewStore creates the Store selected by cfg.StoreDriver
+func NewStore(cfg *config.Config) (Store, error) {
+	succession, err := ParseSuccessionPolicy(cfg.HostSuccessionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.StoreDriver == "" || cfg.StoreDriver == "memory" {
+		registry, recordings := NewMemoryRoomRegistry(), NewMemoryRecordingRegistry()
+		lk, err := connectLiveKit(cfg, registry, recordings, succession)
+		if err != nil {
+			return nil, err
+		}
+		st, err := newMemoryStore(cfg, lk, registry, recordings, NewMemoryMeetingRegistry(),
+			NewMemoryNotificationRegistry(), NewMemorySessionRegistry(), NewMemoryInviteRegistry())
+		if err != nil {
+			return nil, err
+		}
+		return st, nil
+	}
+
+	db, err := OpenDatabase(context.Background(), cfg.StoreDriver, cfg.DatabaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, recordings := NewSQLRoomRegistry(db), NewSQLRecordingRegistry(db)
+	lk, err := connectLiveKit(cfg, registry, recordings, succession)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	st, err := newMemoryStore(cfg, lk, registry, recordings, NewSQLMeetingRegistry(db),
+		NewSQLNotificationRegistry(db), NewSQLSessionRegistry(db), NewSQLInviteRegistry(db))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &sqlStore{
+		memoryStore: *st,
+		db:          db,
+	}, nil
+}
+
+// NewStoreWithRoomService creates an in-memory Store that reaches LiveKit rooms through
+// client instead of the configured server, e.g. a fake one in tests
+func NewStoreWithRoomService(cfg *config.Config, client RoomServiceClient) (Store, error) {
+	succession, err := ParseSuccessionPolicy(cfg.HostSuccessionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenTTL := cfg.LiveKitTokenTTL
+	if tokenTTL <= 0 {
+		tokenTTL = DefaultTokenTTL
+	}
+	registry, recordings := NewMemoryRoomRegistry(), NewMemoryRecordingRegistry()
+	metadata := &metadataEditor{client: client, registry: registry, recordings: recordings}
+	lk := &liveKitStores{
+		room:     &LiveKitRoom{client: client, registry: registry},
+		metadata: metadata,
+		host:     &host{client: client, metadata: metadata, registry: registry, succession: succession},
+		participant: &participant{
+			client:    client,
+			metadata:  metadata,
+			registry:  registry,
+			apiKey:    cfg.LiveKitAPIKey,
+			apiSecret: cfg.LiveKitAPISecret,
+			tokenTTL:  tokenTTL,
+		},
+	}
+
+	return newMemoryStore(cfg, lk, registry, recordings, NewMemoryMeetingRegistry(),
+		NewMemoryNotificationRegistry(), NewMemorySessionRegistry(), NewMemoryInviteRegistry())
+}
+
+// liveKitStores are the stores that call LiveKit's room service
+type liveKitStores struct {
+	room        Room
+	metadata    *metadataEditor
+	host        Host
+	participant Participant
+}
+
+// connectLiveKit creates the stores talking to the LiveKit server from the environment
+func connectLiveKit(cfg *config.Config, registry RoomRegistry, recordings RecordingRegistry, succession SuccessionPolicy) (*liveKitStores, error) {
+	roomSt, err := GetRoomStore(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Host and participant share one editor so their metadata writes are serialized together
+	metadata, err := NewMetadataEditor(registry, recordings)
+	if err != nil {
+		return nil, err
+	}
+
+	hostSt, err := GetHostStore(registry, metadata, succession)
+	if err != nil {
+		return nil, err
+	}
+
+	participantSt, err := GetParticipantStore(registry, metadata, cfg.LiveKitTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &liveKitStores{room: roomSt, metadata: metadata, host: hostSt, participant: participantSt}, nil
 }
 
-func NewStore() (Store, error) {
-	roomSt, err := GetRoomStore()
+func newMemoryStore(cfg *config.Config, lk *liveKitStores, registry RoomRegistry, recordings RecordingRegistry, meetings MeetingRegistry, outbox NotificationRegistry, sessions SessionRegistry, invites InviteRegistry) (*memoryStore, error) {
+	roomSt, metadata, hostSt, participantSt := lk.room, lk.metadata, lk.host, lk.participant
+
+	egress, err := NewEgressClient(cfg.EgressDriver)
 	if err != nil {
 		return nil, err
 	}
 
-	hostSt, err := GetHostStore()
+	recordingKey := []byte(cfg.RecordingSigningKey)
+	if len(recordingKey) == 0 {
+		if recordingKey, err = purposeKey(cfg.SessionSigningKey, "recording-link"); err != nil {
+			return nil, err
+		}
+	}
+
+	recorderSt := NewRecorder(egress, registry, recordings, metadata, cfg.RecordingFilepath, RecordingCatalogue{
+		Dir:         cfg.RecordingDir,
+		SigningKey:  recordingKey,
+		DownloadTTL: cfg.RecordingLinkTTL,
+	})
+
+	calendarClient, err := NewCalendarClient(cfg.CalendarDriver, cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.GoogleRedirectURL)
 	if err != nil {
 		return nil, err
 	}
 
-	participantSt, err := GetParticipantStore()
+	// Google's client secret is a credential, not ours to sign or encrypt with
+	calendarStateKey, err := purposeKey(cfg.SessionSigningKey, "calendar-state")
+	if err != nil {
+		return nil, err
+	}
+	calendarTokenKey, err := purposeKey(cfg.SessionSigningKey, "calendar-token")
+	if err != nil {
+		return nil, err
+	}
+	calendarSt, err := NewCalendarSync(calendarClient, meetings, cfg.MeetingURL, calendarStateKey, calendarTokenKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each kind of token gets its own key, so one can never be passed off as another
+	sessionKey, err := purposeKey(cfg.SessionSigningKey, "session")
+	if err != nil {
+		return nil, err
+	}
+	guestKey, err := purposeKey(cfg.SessionSigningKey, "guest-pass")
+	if err != nil {
+		return nil, err
+	}
+	inviteKey, err := purposeKey(cfg.SessionSigningKey, "invite")
 	if err != nil {
 		return nil, err
 	}
@@ -95,9 +274,29 @@ func NewStore() (Store, error) {
 		room:        roomSt,
 		host:        hostSt,
 		participant: participantSt,
+		registry:    registry,
+		recorder:    recorderSt,
+		meetings:    NewMeetings(meetings, roomSt),
+		calendar:    calendarSt,
+		outbox:      outbox,
+		guests:      NewGuests(hostSt, participantSt, guestKey),
+		invites:     NewInvites(registry, hostSt, invites, inviteKey),
+		sessions:    NewSessions(sessions, sessionKey, cfg.AccessTokenTTL, cfg.RefreshTokenTTL),
 	}, nil
 }
 
+// purposeKey derives the HMAC key for one purpose from the service's signing key
+func purposeKey(secret, purpose string) ([]byte, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("missing signing key for %s tokens", purpose)
+	}
+	key, err := hkdf.Key(sha256.New, []byte(secret), nil, "open-meet "+purpose, sha256.Size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive %s key: %w", purpose, err)
+	}
+	return key, nil
+}
+
 func (s *memoryStore) Room() Room {
 	return s.room
 }
@@ -109,3 +308,35 @@ func (s *memoryStore) Host() Host {
 func (s *memoryStore) Participant() Participant {
 	return s.participant
 }
+
+func (s *memoryStore) Registry() RoomRegistry {
+	return s.registry
+}
+
+func (s *memoryStore) Recorder() Recorder {
+	return s.recorder
+}
+
+func (s *memoryStore) Meetings() Meetings {
+	return s.meetings
+}
+
+func (s *memoryStore) Calendar() CalendarSync {
+	return s.calendar
+}
+
+func (s *memoryStore) Outbox() NotificationRegistry {
+	return s.outbox
+}
+
+func (s *memoryStore) Sessions() Sessions {
+	return s.sessions
+}
+
+func (s *memoryStore) Guests() Guests {
+	return s.guests
+}
+
+func (s *memoryStore) Invites() Invites {
+	return s.invites
+}
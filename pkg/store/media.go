@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// setTracksMuted mutes or unmutes every track of source the participant publishes
+// and records the new state in their metadata
+func setTracksMuted(ctx context.Context, client RoomServiceClient, editor *metadataEditor, roomName, identity string, source livekit.TrackSource, muted bool) error {
+	info, err := client.GetParticipant(ctx, &livekit.RoomParticipantIdentity{
+		Room:     roomName,
+		Identity: identity,
+	})
+	if err != nil {
+		return translateError(err, "failed to get participant %s", identity)
+	}
+
+	for _, track := range info.GetTracks() {
+		if track.GetSource() != source || track.GetMuted() == muted {
+			continue
+		}
+		_, err := client.MutePublishedTrack(ctx, &livekit.MuteRoomTrackRequest{
+			Room:     roomName,
+			Identity: identity,
+			TrackSid: track.GetSid(),
+			Muted:    muted,
+		})
+		if err != nil {
+			return translateError(err, "failed to mute track %s of %s", track.GetSid(), identity)
+		}
+	}
+
+	_, err = editor.UpdateParticipant(ctx, roomName, identity, func(state *ParticipantState) {
+		switch source {
+		case livekit.TrackSource_MICROPHONE:
+			state.Audio = boolPtr(!muted)
+		case livekit.TrackSource_CAMERA:
+			state.Video = boolPtr(!muted)
+		case livekit.TrackSource_SCREEN_SHARE, livekit.TrackSource_SCREEN_SHARE_AUDIO:
+			state.Screen = boolPtr(!muted)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record media state: %w", err)
+	}
+
+	return nil
+}
+
+// canPublishSource reports whether the participant's current permissions allow publishing source
+func canPublishSource(permission *livekit.ParticipantPermission, source livekit.TrackSource) bool {
+	if permission == nil || !permission.GetCanPublish() {
+		return false
+	}
+	sources := permission.GetCanPublishSources()
+	if len(sources) == 0 {
+		return true
+	}
+	return slices.Contains(sources, source)
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
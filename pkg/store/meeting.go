@@ -0,0 +1,546 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/mail"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/livekit/protocol/livekit"
+
+	"open-meet/pkg/calendar"
+)
+
+// meetingCodeAlphabet avoids characters that are easily confused when read out loud
+const meetingCodeAlphabet = "abcdefghijkmnopqrstuvwxyz"
+
+// MeetingStatus says whether a meeting is still going to happen
+type MeetingStatus string
+
+const (
+	MeetingScheduled MeetingStatus = "scheduled"
+	MeetingCancelled MeetingStatus = "cancelled"
+)
+
+// Meeting is a meeting scheduled ahead of time. Its code doubles as the name of the
+// LiveKit room, which is only created when the first person joins.
+type Meeting struct {
+	ID          string        `json:"id"`
+	Code        string        `json:"code"`
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	StartAt     time.Time     `json:"start_at"`
+	EndAt       time.Time     `json:"end_at"`
+	Timezone    string        `json:"timezone"` // IANA name the meeting was scheduled in
+	HostEmail   string        `json:"host_email"`
+	Invitees    []string      `json:"invitees"`
+	Status      MeetingStatus `json:"status"`
+	Recurrence  *Recurrence   `json:"recurrence,omitempty"` // nil for a one-off meeting
+	Sequence    int           `json:"sequence"`             // bumped whenever attendees' calendars need updating
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// Attends reports whether email hosts the meeting or is invited to it
+func (m *Meeting) Attends(email string) bool {
+	return m.HostEmail == email || slices.Contains(m.Invitees, email)
+}
+
+// MeetingInput is what a host provides when scheduling or rescheduling a meeting
+type MeetingInput struct {
+	Title       string
+	Description string
+	StartAt     time.Time
+	EndAt       time.Time
+	Timezone    string // defaults to UTC, recurring meetings repeat at the same local time
+	Invitees    []string
+	Recurrence  string      // RRULE value, empty for a one-off meeting
+	ExDates     []time.Time // starts of occurrences to skip
+}
+
+// MeetingRegistry persists scheduled meetings
+type MeetingRegistry interface {
+	SaveMeeting(ctx context.Context, meeting *Meeting) error
+	GetMeeting(ctx context.Context, code string) (*Meeting, bool, error)
+	ListMeetings(ctx context.Context, email string) ([]Meeting, error)
+	// ListScheduledMeetings returns the meetings that may have an occurrence starting in
+	// [from, to): one-off meetings starting then and every series that was not cancelled
+	ListScheduledMeetings(ctx context.Context, from, to time.Time) ([]Meeting, error)
+	SetFeedToken(ctx context.Context, email, tokenHash string) error
+	GetFeedOwner(ctx context.Context, tokenHash string) (string, bool, error)
+
+	// External calendars; refresh tokens reach the registry already encrypted by CalendarSync
+	SaveCalendarAccount(ctx context.Context, email, refreshToken string) error
+	GetCalendarAccount(ctx context.Context, email string) (string, bool, error)
+	DeleteCalendarAccount(ctx context.Context, email string) error
+	SetCalendarEvent(ctx context.Context, meetingID, eventID string) error
+	GetCalendarEvent(ctx context.Context, meetingID string) (string, bool, error)
+	DeleteCalendarEvent(ctx context.Context, meetingID string) error
+}
+
+// Meetings defines the interface for scheduled meeting operations
+type Meetings interface {
+	Schedule(ctx context.Context, hostEmail string, input MeetingInput) (*Meeting, error)
+	ScheduleRoom(ctx context.Context, roomName string, hostEmail string, input MeetingInput) (*Meeting, error)
+	Get(ctx context.Context, code string) (*Meeting, error)
+	List(ctx context.Context, email string) ([]Meeting, error)
+	Update(ctx context.Context, code string, hostEmail string, input MeetingInput) (*Meeting, error)
+	Cancel(ctx context.Context, code string, hostEmail string) (*Meeting, error)
+	Join(ctx context.Context, code string) (*livekit.Room, error)
+
+	// Recurring series
+	Occurrences(ctx context.Context, code string, after time.Time, limit int) ([]Occurrence, error)
+	OverrideOccurrence(ctx context.Context, code string, hostEmail string, recurrenceID time.Time, input OccurrenceInput) (*Meeting, error)
+	CancelOccurrence(ctx context.Context, code string, hostEmail string, recurrenceID time.Time) (*Meeting, error)
+	Starting(ctx context.Context, from, to time.Time) ([]UpcomingOccurrence, error)
+
+	// Calendar feeds
+	CreateFeedToken(ctx context.Context, email string) (string, error)
+	FeedMeetings(ctx context.Context, token string) (string, []Meeting, error)
+}
+
+// meetings implements Meetings interface
+type meetings struct {
+	registry MeetingRegistry
+	rooms    Room
+	joining  roomLocks // serializes lazy room creation per meeting
+}
+
+// NewMeetings creates a meeting scheduler creating rooms through rooms
+func NewMeetings(registry MeetingRegistry, rooms Room) *meetings {
+	return &meetings{
+		registry: registry,
+		rooms:    rooms,
+	}
+}
+
+// Schedule records a new meeting hosted by hostEmail under a fresh meeting code
+func (m *meetings) Schedule(ctx context.Context, hostEmail string, input MeetingInput) (*Meeting, error) {
+	if err := normalizeMeetingInput(&input); err != nil {
+		return nil, err
+	}
+
+	code, err := newMeetingCode()
+	if err != nil {
+		return nil, err
+	}
+	return m.schedule(ctx, code, hostEmail, input)
+}
+
+// ScheduleRoom attaches a schedule to a room created on the spot; the room name serves as meeting code
+func (m *meetings) ScheduleRoom(ctx context.Context, roomName string, hostEmail string, input MeetingInput) (*Meeting, error) {
+	if err := normalizeMeetingInput(&input); err != nil {
+		return nil, err
+	}
+	if _, found, err := m.registry.GetMeeting(ctx, roomName); err != nil {
+		return nil, fmt.Errorf("failed to get meeting: %w", err)
+	} else if found {
+		return nil, fmt.Errorf("%w: room %s is already scheduled", ErrConflict, roomName)
+	}
+	return m.schedule(ctx, roomName, hostEmail, input)
+}
+
+func (m *meetings) schedule(ctx context.Context, code, hostEmail string, input MeetingInput) (*Meeting, error) {
+	now := time.Now().UTC()
+	meeting := &Meeting{
+		ID:        uuid.NewString(),
+		Code:      code,
+		HostEmail: hostEmail,
+		Status:    MeetingScheduled,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyMeetingInput(meeting, input)
+
+	if err := m.registry.SaveMeeting(ctx, meeting); err != nil {
+		return nil, fmt.Errorf("failed to save meeting: %w", err)
+	}
+	return meeting, nil
+}
+
+// Get returns the meeting with the given code or ErrNotFound
+func (m *meetings) Get(ctx context.Context, code string) (*Meeting, error) {
+	meeting, found, err := m.registry.GetMeeting(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meeting: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: meeting %s", ErrNotFound, code)
+	}
+	return meeting, nil
+}
+
+// List returns the meetings email hosts or is invited to, soonest first
+func (m *meetings) List(ctx context.Context, email string) ([]Meeting, error) {
+	list, err := m.registry.ListMeetings(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meetings: %w", err)
+	}
+	return list, nil
+}
+
+// Update reschedules the meeting; only its host may
+func (m *meetings) Update(ctx context.Context, code string, hostEmail string, input MeetingInput) (*Meeting, error) {
+	meeting, err := m.hostedMeeting(ctx, code, hostEmail)
+	if err != nil {
+		return nil, err
+	}
+	if meeting.Status == MeetingCancelled {
+		return nil, fmt.Errorf("%w: meeting %s is cancelled", ErrConflict, code)
+	}
+	if err := normalizeMeetingInput(&input); err != nil {
+		return nil, err
+	}
+
+	applyMeetingInput(meeting, input)
+	meeting.Sequence++
+	meeting.UpdatedAt = time.Now().UTC()
+
+	if err := m.registry.SaveMeeting(ctx, meeting); err != nil {
+		return nil, fmt.Errorf("failed to save meeting: %w", err)
+	}
+	return meeting, nil
+}
+
+// Cancel calls the meeting off; only its host may. The meeting stays on record so
+// invitees can learn about the cancellation.
+func (m *meetings) Cancel(ctx context.Context, code string, hostEmail string) (*Meeting, error) {
+	meeting, err := m.hostedMeeting(ctx, code, hostEmail)
+	if err != nil {
+		return nil, err
+	}
+	if meeting.Status == MeetingCancelled {
+		return meeting, nil
+	}
+
+	meeting.Status = MeetingCancelled
+	meeting.Sequence++
+	meeting.UpdatedAt = time.Now().UTC()
+
+	if err := m.registry.SaveMeeting(ctx, meeting); err != nil {
+		return nil, fmt.Errorf("failed to save meeting: %w", err)
+	}
+	return meeting, nil
+}
+
+// Join returns the meeting's LiveKit room, creating it with the meeting's host as owner
+// if nobody has joined yet
+func (m *meetings) Join(ctx context.Context, code string) (*livekit.Room, error) {
+	meeting, err := m.Get(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if meeting.Status == MeetingCancelled {
+		return nil, fmt.Errorf("%w: meeting %s is cancelled", ErrConflict, code)
+	}
+
+	unlock := m.joining.lock(code)
+	defer unlock()
+
+	room, found, err := m.rooms.Get(ctx, meeting.Code)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return room, nil
+	}
+
+	return m.rooms.Create(ctx, meeting.Code, meeting.HostEmail)
+}
+
+// CreateFeedToken issues a new calendar feed token for email, replacing any previous one.
+// Only a hash of the token is stored, so it cannot be shown again later.
+func (m *meetings) CreateFeedToken(ctx context.Context, email string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate feed token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	if err := m.registry.SetFeedToken(ctx, email, hashToken(token)); err != nil {
+		return "", fmt.Errorf("failed to save feed token: %w", err)
+	}
+	return token, nil
+}
+
+// FeedMeetings returns the owner of a calendar feed token and their meetings
+func (m *meetings) FeedMeetings(ctx context.Context, token string) (string, []Meeting, error) {
+	email, found, err := m.registry.GetFeedOwner(ctx, hashToken(token))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get feed: %w", err)
+	}
+	if !found {
+		return "", nil, fmt.Errorf("%w: calendar feed", ErrNotFound)
+	}
+
+	list, err := m.List(ctx, email)
+	if err != nil {
+		return "", nil, err
+	}
+	return email, list, nil
+}
+
+// hashToken is how bearer tokens are stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// hostedMeeting returns the meeting if hostEmail hosts it
+func (m *meetings) hostedMeeting(ctx context.Context, code, hostEmail string) (*Meeting, error) {
+	meeting, err := m.Get(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if meeting.HostEmail != hostEmail {
+		return nil, fmt.Errorf("%w: only the host can change meeting %s", ErrUnauthorized, code)
+	}
+	return meeting, nil
+}
+
+// normalizeMeetingInput validates the input and fills in defaults
+func normalizeMeetingInput(input *MeetingInput) error {
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" {
+		input.Title = "Meeting"
+	}
+	if input.Timezone == "" {
+		input.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(input.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalid, input.Timezone)
+	}
+	if input.StartAt.IsZero() || input.EndAt.IsZero() {
+		return fmt.Errorf("%w: start and end time are required", ErrInvalid)
+	}
+	if !input.EndAt.After(input.StartAt) {
+		return fmt.Errorf("%w: meeting must end after it starts", ErrInvalid)
+	}
+
+	invitees := make([]string, 0, len(input.Invitees))
+	for _, raw := range input.Invitees {
+		addr, err := mail.ParseAddress(raw)
+		if err != nil {
+			return fmt.Errorf("%w: invalid invitee %q", ErrInvalid, raw)
+		}
+		email := NormalizeEmail(addr.Address)
+		if !slices.Contains(invitees, email) {
+			invitees = append(invitees, email)
+		}
+	}
+	input.Invitees = invitees
+	return normalizeRecurrence(input)
+}
+
+func applyMeetingInput(meeting *Meeting, input MeetingInput) {
+	meeting.Title = input.Title
+	meeting.Description = input.Description
+	meeting.StartAt = input.StartAt.UTC()
+	meeting.EndAt = input.EndAt.UTC()
+	meeting.Timezone = input.Timezone
+	meeting.Invitees = input.Invitees
+	applyRecurrence(meeting, input)
+}
+
+// CalendarEvents describes the meeting as calendar events: the meeting itself and,
+// for a series, one more event for every occurrence that was changed
+func (m *Meeting) CalendarEvents(joinURL string) []calendar.Event {
+	event := calendar.Event{
+		UID:         m.ID + "@open-meet",
+		Sequence:    m.Sequence,
+		Summary:     m.Title,
+		Description: m.Description,
+		URL:         joinURL,
+		Start:       m.StartAt,
+		End:         m.EndAt,
+		Stamp:       m.UpdatedAt,
+		Organizer:   m.HostEmail,
+		Attendees:   m.Invitees,
+		Cancelled:   m.Status == MeetingCancelled,
+	}
+	if m.Recurrence == nil {
+		return []calendar.Event{event}
+	}
+
+	event.TimeZone = m.Timezone
+	event.Rule = m.Recurrence.Rule
+	event.ExDates = m.Recurrence.ExDates
+	events := []calendar.Event{event}
+	for _, override := range m.Recurrence.Overrides {
+		occurrence := event
+		occurrence.Rule, occurrence.ExDates = "", nil
+		occurrence.RecurrenceID = override.RecurrenceID
+		occurrence.Summary = override.Title
+		occurrence.Description = override.Description
+		occurrence.Start = override.StartAt
+		occurrence.End = override.EndAt
+		events = append(events, occurrence)
+	}
+	return events
+}
+
+func cloneMeeting(meeting *Meeting) *Meeting {
+	cp := *meeting
+	cp.Invitees = slices.Clone(meeting.Invitees)
+	cp.Recurrence = cloneRecurrence(meeting.Recurrence)
+	return &cp
+}
+
+// newMeetingCode returns a code like "abc-defg-hjk"
+func newMeetingCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate meeting code: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = meetingCodeAlphabet[int(b)%len(meetingCodeAlphabet)]
+	}
+	return string(buf[:3]) + "-" + string(buf[3:7]) + "-" + string(buf[7:]), nil
+}
+
+// memoryMeetingRegistry implements MeetingRegistry in process memory
+type memoryMeetingRegistry struct {
+	mu       sync.RWMutex
+	meetings map[string]*Meeting // map[code]meeting
+	feeds    map[string]string   // map[email]tokenHash
+	accounts map[string]string   // map[email]refreshToken
+	events   map[string]string   // map[meetingID]eventID
+}
+
+// NewMemoryMeetingRegistry creates an empty in-memory meeting registry
+func NewMemoryMeetingRegistry() *memoryMeetingRegistry {
+	return &memoryMeetingRegistry{
+		meetings: make(map[string]*Meeting),
+		feeds:    make(map[string]string),
+		accounts: make(map[string]string),
+		events:   make(map[string]string),
+	}
+}
+
+// SaveMeeting inserts or replaces the meeting
+func (r *memoryMeetingRegistry) SaveMeeting(ctx context.Context, meeting *Meeting) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.meetings[meeting.Code] = cloneMeeting(meeting)
+	return nil
+}
+
+// GetMeeting returns the meeting with the given code
+func (r *memoryMeetingRegistry) GetMeeting(ctx context.Context, code string) (*Meeting, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meeting, exists := r.meetings[code]
+	if !exists {
+		return nil, false, nil
+	}
+	return cloneMeeting(meeting), true, nil
+}
+
+// ListMeetings returns the meetings email hosts or is invited to, soonest first
+func (r *memoryMeetingRegistry) ListMeetings(ctx context.Context, email string) ([]Meeting, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var list []Meeting
+	for meeting := range maps.Values(r.meetings) {
+		if meeting.Attends(email) {
+			list = append(list, *cloneMeeting(meeting))
+		}
+	}
+	slices.SortFunc(list, func(a, b Meeting) int {
+		return a.StartAt.Compare(b.StartAt)
+	})
+	return list, nil
+}
+
+// ListScheduledMeetings returns the meetings that may have an occurrence starting in [from, to)
+func (r *memoryMeetingRegistry) ListScheduledMeetings(ctx context.Context, from, to time.Time) ([]Meeting, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var list []Meeting
+	for meeting := range maps.Values(r.meetings) {
+		if meeting.Status != MeetingScheduled {
+			continue
+		}
+		if meeting.Recurrence != nil || !meeting.StartAt.Before(from) && meeting.StartAt.Before(to) {
+			list = append(list, *cloneMeeting(meeting))
+		}
+	}
+	return list, nil
+}
+
+// SetFeedToken replaces email's calendar feed token
+func (r *memoryMeetingRegistry) SetFeedToken(ctx context.Context, email, tokenHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.feeds[email] = tokenHash
+	return nil
+}
+
+// GetFeedOwner returns whose calendar feed the token opens
+func (r *memoryMeetingRegistry) GetFeedOwner(ctx context.Context, tokenHash string) (string, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for email, hash := range r.feeds {
+		if hash == tokenHash {
+			return email, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// SaveCalendarAccount stores the refresh token for email's external calendar
+func (r *memoryMeetingRegistry) SaveCalendarAccount(ctx context.Context, email, refreshToken string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accounts[email] = refreshToken
+	return nil
+}
+
+// GetCalendarAccount returns the refresh token for email's external calendar
+func (r *memoryMeetingRegistry) GetCalendarAccount(ctx context.Context, email string) (string, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	refreshToken, exists := r.accounts[email]
+	return refreshToken, exists, nil
+}
+
+// DeleteCalendarAccount forgets email's external calendar
+func (r *memoryMeetingRegistry) DeleteCalendarAccount(ctx context.Context, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.accounts, email)
+	return nil
+}
+
+// SetCalendarEvent records the external event a meeting was pushed as
+func (r *memoryMeetingRegistry) SetCalendarEvent(ctx context.Context, meetingID, eventID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[meetingID] = eventID
+	return nil
+}
+
+// GetCalendarEvent returns the external event a meeting was pushed as
+func (r *memoryMeetingRegistry) GetCalendarEvent(ctx context.Context, meetingID string) (string, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	eventID, exists := r.events[meetingID]
+	return eventID, exists, nil
+}
+
+// DeleteCalendarEvent forgets the external event of a meeting
+func (r *memoryMeetingRegistry) DeleteCalendarEvent(ctx context.Context, meetingID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.events, meetingID)
+	return nil
+}
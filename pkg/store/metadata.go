@@ -0,0 +1,264 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// maxMetadataRetries bounds how often a publish is repeated after a newer state was published concurrently
+const maxMetadataRetries = 5
+
+// RoomMetadata is the JSON document stored in a LiveKit room's metadata.
+// It is rendered from the room registry, which stays authoritative.
+type RoomMetadata struct {
+	Version     int64       `json:"version"` // grows with every publish
+	Locked      bool        `json:"locked"`
+	Host        string      `json:"host,omitempty"`
+	WaitingRoom bool        `json:"waiting_room,omitempty"`
+	Guests      GuestPolicy `json:"guests,omitempty"`
+	Recording   bool        `json:"recording,omitempty"`
+}
+
+// ParticipantMetadata is the JSON document stored in a LiveKit participant's metadata:
+// the profile from their join token and the state the service keeps for them
+type ParticipantMetadata struct {
+	DisplayName string `json:"display_name,omitempty"`
+	Avatar      string `json:"avatar,omitempty"`
+	Role        Role   `json:"role,omitempty"`
+	Guest       bool   `json:"guest,omitempty"` // joined without an account under a server-chosen identity
+	ParticipantState
+}
+
+// ParticipantState is the part of a participant's metadata the service maintains
+// in the room registry while they are in the room
+type ParticipantState struct {
+	Version           int64      `json:"version"` // grows with every change
+	JoinedAt          *time.Time `json:"joined_at,omitempty"`
+	HandRaised        bool       `json:"hand_raised,omitempty"`
+	Audio             *bool      `json:"audio,omitempty"`
+	Video             *bool      `json:"video,omitempty"`
+	Screen            *bool      `json:"screen,omitempty"`
+	ConnectionQuality string     `json:"connection_quality,omitempty"` // as last reported by the client
+}
+
+// DecodeRoomMetadata parses room metadata. Metadata written by older versions of the
+// service, or by other tools, that does not fit the schema yields an empty document.
+func DecodeRoomMetadata(raw string) RoomMetadata {
+	var metadata RoomMetadata
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+			return RoomMetadata{}
+		}
+	}
+	return metadata
+}
+
+// DecodeParticipantMetadata parses participant metadata, falling back to an empty document like DecodeRoomMetadata
+func DecodeParticipantMetadata(raw string) ParticipantMetadata {
+	var metadata ParticipantMetadata
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+			return ParticipantMetadata{}
+		}
+	}
+	return metadata
+}
+
+// metadataEditor publishes room and participant state to LiveKit metadata.
+// LiveKit has no conditional metadata write, so the state lives in the room registry,
+// where changes are compare-and-swapped, and LiveKit only ever receives renders of it.
+// Each publish bumps the state's version; after writing, a publisher that finds a newer
+// version in the registry publishes again, since the newer render may have landed first
+// and been overwritten by its own. The last write therefore always carries the latest state.
+type metadataEditor struct {
+	client     RoomServiceClient
+	registry   RoomRegistry
+	recordings RecordingRegistry
+}
+
+// NewMetadataEditor creates a metadata editor publishing to the configured LiveKit server
+func NewMetadataEditor(registry RoomRegistry, recordings RecordingRegistry) (*metadataEditor, error) {
+	hostURL := os.Getenv("LIVEKIT_SERVER")
+	apiKey := os.Getenv("LIVEKIT_API_KEY")
+	apiSecret := os.Getenv("LIVEKIT_API_SECRET")
+
+	if hostURL == "" || apiKey == "" || apiSecret == "" {
+		return nil, fmt.Errorf("missing required LiveKit environment variables")
+	}
+
+	return &metadataEditor{
+		client:     lksdk.NewRoomServiceClient(hostURL, apiKey, apiSecret),
+		registry:   registry,
+		recordings: recordings,
+	}, nil
+}
+
+// PublishRoom renders the room's registry record and recordings into its LiveKit metadata
+// and returns the stored result. Call it after every change to state the document shows.
+func (e *metadataEditor) PublishRoom(ctx context.Context, roomName string) (*RoomMetadata, error) {
+	if err := e.registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		settings.MetadataVersion++
+	}); err != nil {
+		return nil, fmt.Errorf("failed to version room metadata: %w", err)
+	}
+
+	for attempt := 0; attempt < maxMetadataRetries; attempt++ {
+		metadata, err := e.renderRoom(ctx, roomName)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode room metadata: %w", err)
+		}
+		_, err = e.client.UpdateRoomMetadata(ctx, &livekit.UpdateRoomMetadataRequest{
+			Room:     roomName,
+			Metadata: string(encoded),
+		})
+		if err != nil {
+			return nil, translateError(err, "failed to update room metadata")
+		}
+
+		// Compare: a newer render may have been written before ours and overwritten
+		record, found, err := e.registry.GetRoom(ctx, roomName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get room: %w", err)
+		}
+		if !found || record.Settings.MetadataVersion == metadata.Version {
+			return metadata, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: metadata of room %s changed concurrently", ErrConflict, roomName)
+}
+
+// renderRoom builds the room metadata document from the registries
+func (e *metadataEditor) renderRoom(ctx context.Context, roomName string) (*RoomMetadata, error) {
+	record, found, err := e.registry.GetRoom(ctx, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: room %s is not registered", ErrNotFound, roomName)
+	}
+
+	recordings, err := e.recordings.ListRecordings(ctx, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+
+	return &RoomMetadata{
+		Version:     record.Settings.MetadataVersion,
+		Locked:      record.Settings.Locked,
+		Host:        record.HostEmail,
+		WaitingRoom: record.Settings.WaitingRoom,
+		Guests:      record.Settings.Guests,
+		Recording: slices.ContainsFunc(recordings, func(recording Recording) bool {
+			return !recording.Status.Done()
+		}),
+	}, nil
+}
+
+// UpdateParticipant applies mutate to the participant's state in the registry, publishes
+// it to their LiveKit metadata and returns the stored result
+func (e *metadataEditor) UpdateParticipant(ctx context.Context, roomName, identity string, mutate func(*ParticipantState)) (*ParticipantMetadata, error) {
+	state, err := e.registry.UpdateParticipantState(ctx, roomName, identity, func(state *ParticipantState) {
+		mutate(state)
+		state.Version++
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store participant state: %w", err)
+	}
+
+	for attempt := 0; attempt < maxMetadataRetries; attempt++ {
+		// The profile comes from the join token and is never changed by the service
+		metadata, err := e.readParticipant(ctx, roomName, identity)
+		if err != nil {
+			return nil, err
+		}
+		metadata.ParticipantState = *state
+
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode participant metadata: %w", err)
+		}
+		_, err = e.client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
+			Room:     roomName,
+			Identity: identity,
+			Metadata: string(encoded),
+		})
+		if err != nil {
+			return nil, translateError(err, "failed to update participant metadata")
+		}
+
+		// Compare: a newer state may have been written before ours and overwritten
+		latest, found, err := e.registry.GetParticipantState(ctx, roomName, identity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get participant state: %w", err)
+		}
+		if !found || latest.Version == state.Version {
+			return &metadata, nil
+		}
+		state = latest
+	}
+
+	return nil, fmt.Errorf("%w: metadata of %s in room %s changed concurrently", ErrConflict, identity, roomName)
+}
+
+func (e *metadataEditor) readParticipant(ctx context.Context, roomName, identity string) (ParticipantMetadata, error) {
+	info, err := e.client.GetParticipant(ctx, &livekit.RoomParticipantIdentity{
+		Room:     roomName,
+		Identity: identity,
+	})
+	if err != nil {
+		return ParticipantMetadata{}, translateError(err, "failed to get participant %s", identity)
+	}
+	return DecodeParticipantMetadata(info.GetMetadata()), nil
+}
+
+// roomLocks hands out one mutex per room, dropping it when nobody holds or waits for it
+type roomLocks struct {
+	mu    sync.Mutex
+	locks map[string]*roomLock // map[roomName]lock
+}
+
+// roomLock is a mutex that counts its holders and waiters
+type roomLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the room's mutex and returns the matching unlock
+func (l *roomLocks) lock(roomName string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*roomLock)
+	}
+	rl, exists := l.locks[roomName]
+	if !exists {
+		rl = &roomLock{}
+		l.locks[roomName] = rl
+	}
+	rl.refs++
+	l.mu.Unlock()
+
+	rl.Lock()
+	return func() {
+		rl.Unlock()
+		l.mu.Lock()
+		rl.refs--
+		if rl.refs == 0 {
+			delete(l.locks, roomName)
+		}
+		l.mu.Unlock()
+	}
+}
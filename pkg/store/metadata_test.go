@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"open-meet/pkg/store/storetest"
+)
+
+const (
+	metadataRoom = "standup"
+	metadataHost = "host@example.com"
+	metadataUser = "ada@example.com"
+)
+
+// eachReplicas runs test with two metadata editors standing for two service replicas
+// that share one LiveKit server and one room registry
+func eachReplicas(t *testing.T, test func(t *testing.T, a, b *metadataEditor, registry RoomRegistry, server *storetest.RoomService)) {
+	eachRegistry(t,
+		func() RoomRegistry { return NewMemoryRoomRegistry() },
+		func(db *sql.DB) RoomRegistry { return NewSQLRoomRegistry(db) },
+		func(t *testing.T, registry RoomRegistry) {
+			if err := registry.CreateRoom(context.Background(), metadataRoom, metadataHost); err != nil {
+				t.Fatal(err)
+			}
+			server := storetest.NewRoomService()
+			server.Join(metadataRoom, metadataUser)
+
+			recordings := NewMemoryRecordingRegistry()
+			a := &metadataEditor{client: server, registry: registry, recordings: recordings}
+			b := &metadataEditor{client: server, registry: registry, recordings: recordings}
+			test(t, a, b, registry, server)
+		})
+}
+
+func roomMetadata(t *testing.T, server *storetest.RoomService) RoomMetadata {
+	t.Helper()
+	resp, err := server.ListRooms(context.Background(), &livekit.ListRoomsRequest{Names: []string{metadataRoom}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return DecodeRoomMetadata(resp.GetRooms()[0].GetMetadata())
+}
+
+func participantMetadata(t *testing.T, server *storetest.RoomService) ParticipantMetadata {
+	t.Helper()
+	info, err := server.GetParticipant(context.Background(), &livekit.RoomParticipantIdentity{Room: metadataRoom, Identity: metadataUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return DecodeParticipantMetadata(info.GetMetadata())
+}
+
+func TestPublishRoomConcurrentWriters(t *testing.T) {
+	eachReplicas(t, func(t *testing.T, a, b *metadataEditor, registry RoomRegistry, server *storetest.RoomService) {
+		ctx := context.Background()
+
+		// Replica a locks the room; before its render reaches LiveKit, replica b turns
+		// on the waiting room and publishes first
+		if err := registry.UpdateSettings(ctx, metadataRoom, func(s *RoomSettings) { s.Locked = true }); err != nil {
+			t.Fatal(err)
+		}
+		server.OnNextWrite(func() {
+			if err := registry.UpdateSettings(ctx, metadataRoom, func(s *RoomSettings) { s.WaitingRoom = true }); err != nil {
+				t.Error(err)
+			}
+			if _, err := b.PublishRoom(ctx, metadataRoom); err != nil {
+				t.Errorf("PublishRoom on b: %v", err)
+			}
+		})
+		if _, err := a.PublishRoom(ctx, metadataRoom); err != nil {
+			t.Fatalf("PublishRoom on a: %v", err)
+		}
+
+		got := roomMetadata(t, server)
+		if !got.Locked || !got.WaitingRoom || got.Host != metadataHost {
+			t.Errorf("metadata = %+v, want the room locked with the waiting room on", got)
+		}
+		if got.Version != 2 {
+			t.Errorf("version = %d, want 2", got.Version)
+		}
+	})
+}
+
+func TestUpdateParticipantConcurrentWriters(t *testing.T) {
+	eachReplicas(t, func(t *testing.T, a, b *metadataEditor, registry RoomRegistry, server *storetest.RoomService) {
+		ctx := context.Background()
+
+		server.OnNextWrite(func() {
+			if _, err := b.UpdateParticipant(ctx, metadataRoom, metadataUser, func(s *ParticipantState) {
+				s.ConnectionQuality = "GOOD"
+			}); err != nil {
+				t.Errorf("UpdateParticipant on b: %v", err)
+			}
+		})
+		if _, err := a.UpdateParticipant(ctx, metadataRoom, metadataUser, func(s *ParticipantState) {
+			s.Audio = boolPtr(false)
+		}); err != nil {
+			t.Fatalf("UpdateParticipant on a: %v", err)
+		}
+
+		got := participantMetadata(t, server)
+		if got.Audio == nil || *got.Audio || got.ConnectionQuality != "GOOD" {
+			t.Errorf("metadata = %+v, want audio off and quality GOOD", got)
+		}
+		if got.Version != 2 {
+			t.Errorf("version = %d, want 2", got.Version)
+		}
+	})
+}
+
+func TestRecordJoinResetsParticipantState(t *testing.T) {
+	eachReplicas(t, func(t *testing.T, a, b *metadataEditor, registry RoomRegistry, server *storetest.RoomService) {
+		ctx := context.Background()
+		if _, err := a.UpdateParticipant(ctx, metadataRoom, metadataUser, func(s *ParticipantState) {
+			s.HandRaised = true
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := registry.RecordJoin(ctx, metadataRoom, metadataUser, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if _, found, err := registry.GetParticipantState(ctx, metadataRoom, metadataUser); err != nil || found {
+			t.Errorf("state after rejoining: found = %v, err = %v, want none", found, err)
+		}
+	})
+}
@@ -0,0 +1,14 @@
+-- Rooms known to the service, their creators and settings
+CREATE TABLE IF NOT EXISTS rooms (
+    name          TEXT PRIMARY KEY,
+    creator_email TEXT NOT NULL,
+    settings      TEXT NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMP NOT NULL
+);
+
+-- Current host of each room
+CREATE TABLE IF NOT EXISTS room_hosts (
+    room_name   TEXT PRIMARY KEY REFERENCES rooms (name) ON DELETE CASCADE,
+    host_email  TEXT NOT NULL,
+    assigned_at TIMESTAMP NOT NULL
+);
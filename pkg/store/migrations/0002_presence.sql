@@ -0,0 +1,8 @@
+-- Join and leave times of participants, fed by LiveKit webhooks
+CREATE TABLE IF NOT EXISTS room_presence (
+    room_name TEXT NOT NULL,
+    identity  TEXT NOT NULL,
+    joined_at TIMESTAMP NOT NULL,
+    left_at   TIMESTAMP NULL,
+    PRIMARY KEY (room_name, identity)
+);
@@ -0,0 +1,8 @@
+-- Co-hosts and moderators of each room; the owner lives in room_hosts
+CREATE TABLE IF NOT EXISTS room_roles (
+    room_name  TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    role       TEXT NOT NULL,
+    granted_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (room_name, email)
+);
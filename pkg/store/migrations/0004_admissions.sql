@@ -0,0 +1,10 @@
+-- Waiting-room requests and the host's decision on each
+CREATE TABLE IF NOT EXISTS room_admissions (
+    room_name    TEXT NOT NULL,
+    email        TEXT NOT NULL,
+    status       TEXT NOT NULL,
+    requested_at TIMESTAMP NOT NULL,
+    decided_at   TIMESTAMP NULL,
+    decided_by   TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (room_name, email)
+);
@@ -0,0 +1,19 @@
+-- Recordings started through LiveKit Egress and the files they produced
+CREATE TABLE IF NOT EXISTS recordings (
+    id          TEXT PRIMARY KEY,
+    room_name   TEXT NOT NULL,
+    egress_id   TEXT NOT NULL UNIQUE,
+    kind        TEXT NOT NULL,
+    track_sid   TEXT NOT NULL DEFAULT '',
+    status      TEXT NOT NULL,
+    started_by  TEXT NOT NULL,
+    file_name   TEXT NOT NULL DEFAULT '',
+    location    TEXT NOT NULL DEFAULT '',
+    size        BIGINT NOT NULL DEFAULT 0,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    error       TEXT NOT NULL DEFAULT '',
+    started_at  TIMESTAMP NOT NULL,
+    ended_at    TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS recordings_room_name ON recordings (room_name);
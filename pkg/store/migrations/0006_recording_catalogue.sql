@@ -0,0 +1,5 @@
+-- Remember who created the room so recordings stay reachable after the room is gone
+ALTER TABLE recordings ADD COLUMN room_creator TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS recordings_room_creator ON recordings (room_creator);
+CREATE INDEX IF NOT EXISTS recordings_started_by ON recordings (started_by);
@@ -0,0 +1,24 @@
+-- Meetings scheduled ahead of time; the code is also the LiveKit room name
+CREATE TABLE IF NOT EXISTS meetings (
+    id          TEXT PRIMARY KEY,
+    code        TEXT NOT NULL UNIQUE,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    start_at    TIMESTAMP NOT NULL,
+    end_at      TIMESTAMP NOT NULL,
+    timezone    TEXT NOT NULL,
+    host_email  TEXT NOT NULL,
+    status      TEXT NOT NULL,
+    created_at  TIMESTAMP NOT NULL,
+    updated_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS meetings_host_email ON meetings (host_email);
+
+CREATE TABLE IF NOT EXISTS meeting_invitees (
+    meeting_id TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    PRIMARY KEY (meeting_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS meeting_invitees_email ON meeting_invitees (email);
@@ -0,0 +1,8 @@
+-- iCalendar SEQUENCE of each meeting and per-user subscribable feeds
+ALTER TABLE meetings ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    email      TEXT PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
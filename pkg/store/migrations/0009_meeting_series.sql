@@ -0,0 +1,2 @@
+-- Recurrence rule, exception dates and overrides of recurring meetings, as JSON; empty for one-off meetings
+ALTER TABLE meetings ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
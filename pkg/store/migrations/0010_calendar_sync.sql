@@ -0,0 +1,11 @@
+-- Hosts' connected external calendars and the events their meetings were pushed as
+CREATE TABLE IF NOT EXISTS calendar_accounts (
+    email         TEXT PRIMARY KEY,
+    refresh_token TEXT NOT NULL,
+    created_at    TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS calendar_events (
+    meeting_id TEXT PRIMARY KEY,
+    event_id   TEXT NOT NULL
+);
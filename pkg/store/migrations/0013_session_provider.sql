@@ -0,0 +1,2 @@
+-- Identity provider each session was signed in with; empty for sessions started before providers were pluggable
+ALTER TABLE sessions ADD COLUMN provider TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Display name guests give when they ask to join; signed-in users are shown by their email
+ALTER TABLE room_admissions ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,22 @@
+-- Invite links hosts hand out, and the users who redeemed each one
+CREATE TABLE IF NOT EXISTS invites (
+    id         TEXT PRIMARY KEY,
+    room_name  TEXT NOT NULL,
+    email      TEXT NOT NULL DEFAULT '',
+    role       TEXT NOT NULL,
+    max_uses   INTEGER NOT NULL DEFAULT 0,
+    uses       INTEGER NOT NULL DEFAULT 0,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS invites_room ON invites (room_name);
+
+CREATE TABLE IF NOT EXISTS invite_redemptions (
+    invite_id   TEXT NOT NULL REFERENCES invites (id) ON DELETE CASCADE,
+    email       TEXT NOT NULL,
+    redeemed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (invite_id, email)
+);
@@ -0,0 +1,7 @@
+-- State the service publishes in the LiveKit metadata of participants in a room
+CREATE TABLE IF NOT EXISTS participant_states (
+    room_name TEXT NOT NULL,
+    identity  TEXT NOT NULL,
+    state     TEXT NOT NULL,
+    PRIMARY KEY (room_name, identity)
+);
@@ -0,0 +1,16 @@
+-- Emails are compared in lower case; hosts' typed-in emails used to be stored as typed.
+-- A mixed-case row that duplicates a lower-case one is dropped in favour of the latter.
+UPDATE rooms SET creator_email = LOWER(creator_email);
+UPDATE room_hosts SET host_email = LOWER(host_email);
+
+DELETE FROM room_roles WHERE email <> LOWER(email) AND EXISTS (
+    SELECT 1 FROM room_roles lower_roles
+    WHERE lower_roles.room_name = room_roles.room_name AND lower_roles.email = LOWER(room_roles.email)
+);
+UPDATE room_roles SET email = LOWER(email) WHERE email <> LOWER(email);
+
+DELETE FROM room_admissions WHERE email <> LOWER(email) AND EXISTS (
+    SELECT 1 FROM room_admissions lower_admissions
+    WHERE lower_admissions.room_name = room_admissions.room_name AND lower_admissions.email = LOWER(room_admissions.email)
+);
+UPDATE room_admissions SET email = LOWER(email) WHERE email <> LOWER(email);
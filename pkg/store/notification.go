@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// NotificationStatus tracks a notification through delivery
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed" // gave up after the last retry
+)
+
+// Notification is one message queued for delivery through one channel. Its key is unique,
+// so a notification enqueued again, e.g. by a job re-run after a restart, is delivered once.
+type Notification struct {
+	ID            string
+	Key           string
+	Channel       string
+	Recipients    []string
+	Subject       string
+	Body          string
+	URL           string
+	Status        NotificationStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+	SentAt        time.Time
+}
+
+// NotificationRegistry is the outbox of notifications waiting for delivery
+type NotificationRegistry interface {
+	// EnqueueNotification stores the notification unless one with its key exists,
+	// and reports whether it was stored
+	EnqueueNotification(ctx context.Context, notification *Notification) (bool, error)
+	// ClaimNotifications returns up to limit pending notifications due at now, and
+	// postpones them until lease runs out so a concurrent claim skips them
+	ClaimNotifications(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Notification, error)
+	SaveNotification(ctx context.Context, notification *Notification) error
+}
+
+// memoryNotificationRegistry implements NotificationRegistry in process memory
+type memoryNotificationRegistry struct {
+	mu            sync.Mutex
+	notifications map[string]*Notification // map[key]notification
+}
+
+// NewMemoryNotificationRegistry creates an empty in-memory outbox
+func NewMemoryNotificationRegistry() *memoryNotificationRegistry {
+	return &memoryNotificationRegistry{notifications: make(map[string]*Notification)}
+}
+
+// EnqueueNotification stores the notification unless one with its key exists
+func (r *memoryNotificationRegistry) EnqueueNotification(ctx context.Context, notification *Notification) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.notifications[notification.Key]; exists {
+		return false, nil
+	}
+	cp := *notification
+	cp.Recipients = slices.Clone(notification.Recipients)
+	r.notifications[notification.Key] = &cp
+	return true, nil
+}
+
+// ClaimNotifications returns the pending notifications due at now, oldest first
+func (r *memoryNotificationRegistry) ClaimNotifications(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*Notification
+	for notification := range maps.Values(r.notifications) {
+		if notification.Status == NotificationPending && !notification.NextAttemptAt.After(now) {
+			due = append(due, notification)
+		}
+	}
+	slices.SortFunc(due, func(a, b *Notification) int {
+		return a.NextAttemptAt.Compare(b.NextAttemptAt)
+	})
+
+	claimed := make([]Notification, 0, min(limit, len(due)))
+	for _, notification := range due[:min(limit, len(due))] {
+		notification.NextAttemptAt = now.Add(lease)
+		cp := *notification
+		cp.Recipients = slices.Clone(notification.Recipients)
+		claimed = append(claimed, cp)
+	}
+	return claimed, nil
+}
+
+// SaveNotification records the outcome of a delivery attempt
+func (r *memoryNotificationRegistry) SaveNotification(ctx context.Context, notification *Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *notification
+	cp.Recipients = slices.Clone(notification.Recipients)
+	r.notifications[notification.Key] = &cp
+	return nil
+}
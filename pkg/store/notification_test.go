@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestClaimNotificationsLease(t *testing.T) {
+	eachRegistry(t,
+		func() NotificationRegistry { return NewMemoryNotificationRegistry() },
+		func(db *sql.DB) NotificationRegistry { return NewSQLNotificationRegistry(db) },
+		func(t *testing.T, outbox NotificationRegistry) {
+			ctx := context.Background()
+			const lease = time.Minute
+			now := time.Now()
+			notification := &Notification{
+				ID:            "1",
+				Key:           "reminder/standup",
+				Channel:       "email",
+				Recipients:    []string{"ada@example.com"},
+				Subject:       "Standup",
+				Status:        NotificationPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+			}
+			for i, want := range []bool{true, false} {
+				if stored, err := outbox.EnqueueNotification(ctx, notification); err != nil || stored != want {
+					t.Fatalf("EnqueueNotification #%d = %v, %v; want %v", i+1, stored, err, want)
+				}
+			}
+
+			claim := func(at time.Time) []Notification {
+				t.Helper()
+				claimed, err := outbox.ClaimNotifications(ctx, at, lease, 10)
+				if err != nil {
+					t.Fatalf("ClaimNotifications: %v", err)
+				}
+				return claimed
+			}
+
+			claimed := claim(now)
+			if len(claimed) != 1 || claimed[0].Key != notification.Key {
+				t.Fatalf("claimed %v, want %s", claimed, notification.Key)
+			}
+			if got := claim(now.Add(lease / 2)); len(got) != 0 {
+				t.Errorf("claimed %d notifications within the lease, want none", len(got))
+			}
+
+			// A sender that never reported back loses the claim once the lease runs out, more than once
+			for _, at := range []time.Time{now.Add(lease + time.Second), now.Add(2*lease + 2*time.Second)} {
+				if got := claim(at); len(got) != 1 {
+					t.Fatalf("claimed %d notifications after the lease, want 1", len(got))
+				}
+			}
+		})
+}
@@ -14,7 +14,7 @@ import (
 // Participant defines the interface for participant operations
 type Participant interface {
 	// Token management
-	GenerateToken(ctx context.Context, roomName, identity string) (string, error)
+	GenerateToken(ctx context.Context, roomName, identity string, opts TokenOptions) (string, error)
 	ValidateToken(ctx context.Context, token string) (*auth.ClaimGrants, error)
 
 	// Participant operations
@@ -22,6 +22,8 @@ type Participant interface {
 	LeaveRoom(ctx context.Context, roomName, identity string) error
 	GetParticipantInfo(ctx context.Context, roomName, identity string) (*livekit.ParticipantInfo, error)
 	ListParticipants(ctx context.Context, roomName string) ([]*livekit.ParticipantInfo, error)
+	Roster(ctx context.Context, roomName string) ([]RosterEntry, error)
+	ReportConnectionQuality(ctx context.Context, roomName, identity, quality string) error
 
 	// Media controls
 	MuteSelf(ctx context.Context, roomName, identity string) error
@@ -34,13 +36,16 @@ type Participant interface {
 
 // participant implements Participant interface
 type participant struct {
-	client    *lksdk.RoomServiceClient
+	client    RoomServiceClient
+	metadata  *metadataEditor
+	registry  RoomRegistry
 	apiKey    string
 	apiSecret string
+	tokenTTL  time.Duration
 }
 
 // NewParticipant creates a new participant instance
-func NewParticipant() (*participant, error) {
+func NewParticipant(registry RoomRegistry, metadata *metadataEditor, tokenTTL time.Duration) (*participant, error) {
 	hostURL := os.Getenv("LIVEKIT_SERVER")
 	apiKey := os.Getenv("LIVEKIT_API_KEY")
 	apiSecret := os.Getenv("LIVEKIT_API_SECRET")
@@ -51,23 +56,70 @@ func NewParticipant() (*participant, error) {
 
 	client := lksdk.NewRoomServiceClient(hostURL, apiKey, apiSecret)
 
+	if tokenTTL <= 0 {
+		tokenTTL = DefaultTokenTTL
+	}
+
 	return &participant{
 		client:    client,
+		metadata:  metadata,
+		registry:  registry,
 		apiKey:    apiKey,
 		apiSecret: apiSecret,
+		tokenTTL:  tokenTTL,
 	}, nil
 }
 
-// GenerateToken creates a token for room access
-func (p *participant) GenerateToken(ctx context.Context, roomName, identity string) (string, error) {
-	at := auth.NewAccessToken(p.apiKey, p.apiSecret)
-	grant := &auth.VideoGrant{
-		RoomJoin: true,
-		Room:     roomName,
+// GenerateToken creates a token for room access with grants derived from the
+// participant's role in the room and the room's media policy
+func (p *participant) GenerateToken(ctx context.Context, roomName, identity string, opts TokenOptions) (string, error) {
+	role, err := p.registry.GetRole(ctx, roomName, identity)
+	if err != nil {
+		return "", fmt.Errorf("failed to get role: %w", err)
+	}
+
+	var settings RoomSettings
+	record, found, err := p.registry.GetRoom(ctx, roomName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get room policy: %w", err)
+	}
+	if found {
+		settings = record.Settings
+	}
+
+	if opts.Guest && role != RoleViewer {
+		// Guests never hold room roles, whatever was assigned to their identity
+		role = RoleParticipant
+	}
+
+	if opts.Hidden && !role.Outranks(RoleParticipant) {
+		return "", fmt.Errorf("%w: %s cannot join as a hidden observer", ErrUnauthorized, role)
+	}
+
+	grant, err := videoGrant(roomName, identity, role, settings, opts.Hidden)
+	if err != nil {
+		return "", fmt.Errorf("failed to build grant: %w", err)
+	}
+	if opts.Guest {
+		restrictGuest(grant)
+	}
+
+	metadata, err := tokenMetadata(role, opts)
+	if err != nil {
+		return "", err
 	}
+
+	ttl := p.tokenTTL
+	if opts.TTL > 0 {
+		ttl = opts.TTL
+	}
+
+	at := auth.NewAccessToken(p.apiKey, p.apiSecret)
 	at.SetVideoGrant(grant).
 		SetIdentity(identity).
-		SetValidFor(1 * time.Hour)
+		SetName(opts.Name).
+		SetMetadata(metadata).
+		SetValidFor(ttl)
 
 	return at.ToJWT()
 }
@@ -95,16 +147,15 @@ func (p *participant) JoinRoom(ctx context.Context, roomName, identity string) e
 	}
 
 	// Generate token for joining
-	_, err = p.GenerateToken(ctx, roomName, identity)
+	_, err = p.GenerateToken(ctx, roomName, identity, TokenOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to generate token: %w", err)
 	}
 
 	// Update participant metadata
-	_, err = p.client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
-		Room:     roomName,
-		Identity: identity,
-		Metadata: `{"joinTime": "` + fmt.Sprint(ctx.Value("timestamp")) + `"}`,
+	joinedAt := time.Now().UTC()
+	_, err = p.metadata.UpdateParticipant(ctx, roomName, identity, func(state *ParticipantState) {
+		state.JoinedAt = &joinedAt
 	})
 	if err != nil {
 		return fmt.Errorf("failed to update participant metadata: %w", err)
@@ -127,18 +178,14 @@ func (p *participant) LeaveRoom(ctx context.Context, roomName, identity string)
 
 // GetParticipantInfo gets information about a specific participant
 func (p *participant) GetParticipantInfo(ctx context.Context, roomName, identity string) (*livekit.ParticipantInfo, error) {
-	participants, err := p.ListParticipants(ctx, roomName)
+	info, err := p.client.GetParticipant(ctx, &livekit.RoomParticipantIdentity{
+		Room:     roomName,
+		Identity: identity,
+	})
 	if err != nil {
-		return nil, err
-	}
-
-	for _, participant := range participants {
-		if participant.Identity == identity {
-			return participant, nil
-		}
+		return nil, translateError(err, "participant %s is not in room %s", identity, roomName)
 	}
-
-	return nil, fmt.Errorf("participant not found")
+	return info, nil
 }
 
 // ListParticipants lists all participants in a room
@@ -147,105 +194,57 @@ func (p *participant) ListParticipants(ctx context.Context, roomName string) ([]
 		Room: roomName,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list participants: %w", err)
+		return nil, translateError(err, "failed to list participants")
 	}
 	return resp.GetParticipants(), nil
 }
 
-// MuteSelf mutes the participant's audio
+// MuteSelf mutes the participant's microphone
 func (p *participant) MuteSelf(ctx context.Context, roomName, identity string) error {
-	_, err := p.client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
-		Room:     roomName,
-		Identity: identity,
-		Metadata: `{"audio": false}`,
-		Permission: &livekit.ParticipantPermission{
-			CanPublish: true,
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to mute self: %w", err)
-	}
-	return nil
+	return p.setOwnTracksMuted(ctx, roomName, identity, livekit.TrackSource_MICROPHONE, true)
 }
 
-// UnmuteSelf unmutes the participant's audio
+// UnmuteSelf unmutes the participant's microphone
 func (p *participant) UnmuteSelf(ctx context.Context, roomName, identity string) error {
-	_, err := p.client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
-		Room:     roomName,
-		Identity: identity,
-		Metadata: `{"audio": true}`,
-		Permission: &livekit.ParticipantPermission{
-			CanPublish: true,
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to unmute self: %w", err)
-	}
-	return nil
+	return p.setOwnTracksMuted(ctx, roomName, identity, livekit.TrackSource_MICROPHONE, false)
 }
 
-// EnableVideo enables the participant's video
+// EnableVideo resumes the participant's camera
 func (p *participant) EnableVideo(ctx context.Context, roomName, identity string) error {
-	_, err := p.client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
-		Room:     roomName,
-		Identity: identity,
-		Metadata: `{"video": true}`,
-		Permission: &livekit.ParticipantPermission{
-			CanPublish: true,
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to enable video: %w", err)
-	}
-	return nil
+	return p.setOwnTracksMuted(ctx, roomName, identity, livekit.TrackSource_CAMERA, false)
 }
 
-// DisableVideo disables the participant's video
+// DisableVideo pauses the participant's camera
 func (p *participant) DisableVideo(ctx context.Context, roomName, identity string) error {
-	_, err := p.client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
-		Room:     roomName,
-		Identity: identity,
-		Metadata: `{"video": false}`,
-		Permission: &livekit.ParticipantPermission{
-			CanPublish: true,
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to disable video: %w", err)
-	}
-	return nil
+	return p.setOwnTracksMuted(ctx, roomName, identity, livekit.TrackSource_CAMERA, true)
 }
 
-// ShareScreen enables screen sharing for the participant
+// ShareScreen resumes the participant's screen share. The client publishes the track
+// itself; this only checks that the room lets them and announces it in their metadata.
+// In rooms that approve presenters it files a request and returns ErrPresenterRequested.
 func (p *participant) ShareScreen(ctx context.Context, roomName, identity string) error {
-	_, err := p.client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
-		Room:     roomName,
-		Identity: identity,
-		Metadata: `{"screen": true}`,
-		Permission: &livekit.ParticipantPermission{
-			CanPublish:     true,
-			CanPublishData: true,
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to enable screen sharing: %w", err)
+	if err := requestPresenter(ctx, p.registry, roomName, identity); err != nil {
+		return err
 	}
-	return nil
+	return p.setOwnTracksMuted(ctx, roomName, identity, livekit.TrackSource_SCREEN_SHARE, false)
 }
 
-// StopScreenShare disables screen sharing for the participant
+// StopScreenShare pauses the participant's screen share
 func (p *participant) StopScreenShare(ctx context.Context, roomName, identity string) error {
-	_, err := p.client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
-		Room:     roomName,
-		Identity: identity,
-		Metadata: `{"screen": false}`,
-		Permission: &livekit.ParticipantPermission{
-			CanPublish:     true,
-			CanPublishData: false,
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to disable screen sharing: %w", err)
+	return p.setOwnTracksMuted(ctx, roomName, identity, livekit.TrackSource_SCREEN_SHARE, true)
+}
+
+// setOwnTracksMuted changes the participant's own tracks of source. Turning a source on
+// requires the participant to be allowed to publish it in the first place.
+func (p *participant) setOwnTracksMuted(ctx context.Context, roomName, identity string, source livekit.TrackSource, muted bool) error {
+	if !muted {
+		info, err := p.GetParticipantInfo(ctx, roomName, identity)
+		if err != nil {
+			return err
+		}
+		if !canPublishSource(info.GetPermission(), source) {
+			return fmt.Errorf("%w: %s may not publish %s in room %s", ErrUnauthorized, identity, source, roomName)
+		}
 	}
-	return nil
+	return setTracksMuted(ctx, p.client, p.metadata, roomName, identity, source, muted)
 }
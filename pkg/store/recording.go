@@ -0,0 +1,377 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/livekit/protocol/livekit"
+)
+
+// DefaultRecordingFilepath is the egress output path template used when none is configured
+const DefaultRecordingFilepath = "recordings/{room_name}-{time}"
+
+// RecordingKind says what a recording captures
+type RecordingKind string
+
+const (
+	// RecordingRoomComposite mixes every participant into one video, as seen in the default layout
+	RecordingRoomComposite RecordingKind = "room_composite"
+	// RecordingTrack captures a single published track as is
+	RecordingTrack RecordingKind = "track"
+)
+
+// RecordingStatus is where a recording stands in its lifecycle
+type RecordingStatus string
+
+const (
+	RecordingStarting  RecordingStatus = "starting"
+	RecordingActive    RecordingStatus = "active"
+	RecordingStopping  RecordingStatus = "stopping"
+	RecordingCompleted RecordingStatus = "completed"
+	RecordingFailed    RecordingStatus = "failed"
+)
+
+// Done reports whether the recording has stopped for good
+func (s RecordingStatus) Done() bool {
+	return s == RecordingCompleted || s == RecordingFailed
+}
+
+// Recording is a LiveKit egress started by the service
+type Recording struct {
+	ID        string          `json:"id"`
+	RoomName  string          `json:"room_name"`
+	EgressID  string          `json:"egress_id"`
+	Kind      RecordingKind   `json:"kind"`
+	TrackSid  string          `json:"track_sid,omitempty"`
+	Status    RecordingStatus `json:"status"`
+	StartedBy string          `json:"started_by"`
+	// RoomCreator keeps access with the room's creator after the room itself is gone
+	RoomCreator string        `json:"room_creator,omitempty"`
+	FileName    string        `json:"file_name,omitempty"`
+	Location    string        `json:"location,omitempty"` // where egress uploaded the file
+	Size        int64         `json:"size,omitempty"`     // in bytes
+	Duration    time.Duration `json:"duration,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	StartedAt   time.Time     `json:"started_at"`
+	EndedAt     time.Time     `json:"ended_at,omitzero"`
+}
+
+// RecordingOptions selects what StartRecording captures
+type RecordingOptions struct {
+	TrackSid string // record only this track; empty records the whole room
+}
+
+// RecordingRegistry persists recordings
+type RecordingRegistry interface {
+	SaveRecording(ctx context.Context, recording *Recording) error
+	GetRecording(ctx context.Context, id string) (*Recording, bool, error)
+	GetRecordingByEgress(ctx context.Context, egressID string) (*Recording, bool, error)
+	ListRecordings(ctx context.Context, roomName string) ([]Recording, error)
+	ListUserRecordings(ctx context.Context, email string) ([]Recording, error)
+	DeleteRecording(ctx context.Context, id string) error
+}
+
+// Recorder defines the interface for recording operations
+type Recorder interface {
+	StartRecording(ctx context.Context, roomName string, hostEmail string, opts RecordingOptions) (*Recording, error)
+	StopRecording(ctx context.Context, roomName string, hostEmail string, recordingID string) (*Recording, error)
+	HandleEgressUpdate(ctx context.Context, info *livekit.EgressInfo) (*Recording, error)
+
+	// Catalogue, open to the room's creator and hosts
+	GetRecording(ctx context.Context, recordingID string, email string) (*Recording, error)
+	ListRoomRecordings(ctx context.Context, roomName string, email string) ([]Recording, error)
+	ListUserRecordings(ctx context.Context, email string) ([]Recording, error)
+	DeleteRecording(ctx context.Context, recordingID string, email string) error
+	DownloadLink(ctx context.Context, recordingID string, email string) (*DownloadLink, error)
+	ResolveDownload(ctx context.Context, recordingID, expires, signature string) (*RecordingDownload, error)
+}
+
+// recorder implements Recorder interface
+type recorder struct {
+	egress     EgressClient
+	registry   RoomRegistry
+	recordings RecordingRegistry
+	metadata   *metadataEditor
+	filepath   string // egress output path template
+	catalogue  RecordingCatalogue
+}
+
+// NewRecorder creates a recorder writing files to the egress output path template filepath
+func NewRecorder(egress EgressClient, registry RoomRegistry, recordings RecordingRegistry, metadata *metadataEditor, filepath string, catalogue RecordingCatalogue) *recorder {
+	if filepath == "" {
+		filepath = DefaultRecordingFilepath
+	}
+	if catalogue.DownloadTTL <= 0 {
+		catalogue.DownloadTTL = DefaultDownloadTTL
+	}
+	return &recorder{
+		egress:     egress,
+		registry:   registry,
+		recordings: recordings,
+		metadata:   metadata,
+		filepath:   filepath,
+		catalogue:  catalogue,
+	}
+}
+
+// StartRecording starts a room composite recording, or a track recording when opts names a track
+func (r *recorder) StartRecording(ctx context.Context, roomName string, hostEmail string, opts RecordingOptions) (*Recording, error) {
+	if _, err := authorize(ctx, r.registry, roomName, hostEmail, PermRecord, "record the meeting"); err != nil {
+		return nil, err
+	}
+
+	record, found, err := r.registry.GetRoom(ctx, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	recording := &Recording{
+		ID:        uuid.NewString(),
+		RoomName:  roomName,
+		Kind:      RecordingRoomComposite,
+		TrackSid:  opts.TrackSid,
+		StartedBy: hostEmail,
+		StartedAt: time.Now().UTC(),
+	}
+
+	if found {
+		recording.RoomCreator = record.CreatorEmail
+	}
+
+	var info *livekit.EgressInfo
+	if opts.TrackSid == "" {
+		info, err = r.egress.StartRoomCompositeEgress(ctx, &livekit.RoomCompositeEgressRequest{
+			RoomName: roomName,
+			FileOutputs: []*livekit.EncodedFileOutput{{
+				FileType: livekit.EncodedFileType_MP4,
+				Filepath: r.filepath + ".mp4",
+			}},
+		})
+	} else {
+		recording.Kind = RecordingTrack
+		info, err = r.egress.StartTrackEgress(ctx, &livekit.TrackEgressRequest{
+			RoomName: roomName,
+			TrackId:  opts.TrackSid,
+			Output: &livekit.TrackEgressRequest_File{
+				File: &livekit.DirectFileOutput{Filepath: r.filepath + "-{track_id}"},
+			},
+		})
+	}
+	if err != nil {
+		return nil, translateError(err, "failed to start recording")
+	}
+
+	recording.EgressID = info.GetEgressId()
+	applyEgressInfo(recording, info)
+	if err := r.recordings.SaveRecording(ctx, recording); err != nil {
+		return nil, fmt.Errorf("failed to save recording: %w", err)
+	}
+
+	if err := r.publishRecording(ctx, roomName); err != nil {
+		return nil, err
+	}
+
+	return recording, nil
+}
+
+// StopRecording asks egress to stop; the final state arrives through HandleEgressUpdate
+func (r *recorder) StopRecording(ctx context.Context, roomName string, hostEmail string, recordingID string) (*Recording, error) {
+	if _, err := authorize(ctx, r.registry, roomName, hostEmail, PermRecord, "stop recordings"); err != nil {
+		return nil, err
+	}
+
+	recording, err := r.getRecording(ctx, recordingID)
+	if err != nil {
+		return nil, err
+	}
+	if recording.RoomName != roomName {
+		return nil, fmt.Errorf("%w: recording %s in room %s", ErrNotFound, recordingID, roomName)
+	}
+	if recording.Status.Done() {
+		return nil, fmt.Errorf("%w: recording %s is already %s", ErrConflict, recordingID, recording.Status)
+	}
+
+	info, err := r.egress.StopEgress(ctx, &livekit.StopEgressRequest{EgressId: recording.EgressID})
+	if err != nil {
+		return nil, translateError(err, "failed to stop recording %s", recordingID)
+	}
+
+	return r.HandleEgressUpdate(ctx, info)
+}
+
+// getRecording returns the recording or ErrNotFound, without access checks
+func (r *recorder) getRecording(ctx context.Context, recordingID string) (*Recording, error) {
+	recording, found, err := r.recordings.GetRecording(ctx, recordingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recording: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: recording %s", ErrNotFound, recordingID)
+	}
+	return recording, nil
+}
+
+// HandleEgressUpdate records the state LiveKit reports for an egress.
+// Egresses the service did not start are ignored and yield a nil recording.
+func (r *recorder) HandleEgressUpdate(ctx context.Context, info *livekit.EgressInfo) (*Recording, error) {
+	recording, found, err := r.recordings.GetRecordingByEgress(ctx, info.GetEgressId())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recording: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	if recording.Status.Done() {
+		// Updates may arrive out of order; never reopen a finished recording
+		return recording, nil
+	}
+
+	applyEgressInfo(recording, info)
+	if err := r.recordings.SaveRecording(ctx, recording); err != nil {
+		return nil, fmt.Errorf("failed to save recording: %w", err)
+	}
+
+	if recording.Status.Done() {
+		if err := r.publishRecording(ctx, recording.RoomName); err != nil {
+			return nil, err
+		}
+	}
+
+	return recording, nil
+}
+
+// publishRecording tells clients through the room metadata whether the room is being recorded
+func (r *recorder) publishRecording(ctx context.Context, roomName string) error {
+	_, err := r.metadata.PublishRoom(ctx, roomName)
+	if errors.Is(err, ErrNotFound) {
+		// The room is already over
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish recording state: %w", err)
+	}
+	return nil
+}
+
+// applyEgressInfo copies status and file details reported by egress onto the recording
+func applyEgressInfo(recording *Recording, info *livekit.EgressInfo) {
+	switch info.GetStatus() {
+	case livekit.EgressStatus_EGRESS_STARTING:
+		recording.Status = RecordingStarting
+	case livekit.EgressStatus_EGRESS_ACTIVE:
+		recording.Status = RecordingActive
+	case livekit.EgressStatus_EGRESS_ENDING:
+		recording.Status = RecordingStopping
+	case livekit.EgressStatus_EGRESS_COMPLETE, livekit.EgressStatus_EGRESS_LIMIT_REACHED:
+		// Hitting a limit stops the egress but still leaves a usable file
+		recording.Status = RecordingCompleted
+	case livekit.EgressStatus_EGRESS_FAILED, livekit.EgressStatus_EGRESS_ABORTED:
+		recording.Status = RecordingFailed
+	}
+
+	if info.GetError() != "" {
+		recording.Error = info.GetError()
+	}
+	if info.GetEndedAt() > 0 {
+		recording.EndedAt = time.Unix(0, info.GetEndedAt()).UTC()
+	}
+
+	if files := info.GetFileResults(); len(files) > 0 {
+		file := files[0]
+		recording.FileName = file.GetFilename()
+		recording.Location = file.GetLocation()
+		recording.Size = file.GetSize()
+		recording.Duration = time.Duration(file.GetDuration())
+	}
+}
+
+// memoryRecordingRegistry implements RecordingRegistry in process memory
+type memoryRecordingRegistry struct {
+	mu         sync.RWMutex
+	recordings map[string]*Recording // map[recordingID]recording
+}
+
+// NewMemoryRecordingRegistry creates an empty in-memory recording registry
+func NewMemoryRecordingRegistry() *memoryRecordingRegistry {
+	return &memoryRecordingRegistry{recordings: make(map[string]*Recording)}
+}
+
+// SaveRecording inserts or replaces the recording
+func (r *memoryRecordingRegistry) SaveRecording(ctx context.Context, recording *Recording) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *recording
+	r.recordings[recording.ID] = &cp
+	return nil
+}
+
+// GetRecording returns the recording with the given ID
+func (r *memoryRecordingRegistry) GetRecording(ctx context.Context, id string) (*Recording, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	recording, exists := r.recordings[id]
+	if !exists {
+		return nil, false, nil
+	}
+	cp := *recording
+	return &cp, true, nil
+}
+
+// GetRecordingByEgress returns the recording produced by the egress
+func (r *memoryRecordingRegistry) GetRecordingByEgress(ctx context.Context, egressID string) (*Recording, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, recording := range r.recordings {
+		if recording.EgressID == egressID {
+			cp := *recording
+			return &cp, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// ListRecordings returns the room's recordings, newest first
+func (r *memoryRecordingRegistry) ListRecordings(ctx context.Context, roomName string) ([]Recording, error) {
+	return r.list(func(recording *Recording) bool {
+		return recording.RoomName == roomName
+	}), nil
+}
+
+// ListUserRecordings returns the recordings email started or made in rooms they created, newest first
+func (r *memoryRecordingRegistry) ListUserRecordings(ctx context.Context, email string) ([]Recording, error) {
+	return r.list(func(recording *Recording) bool {
+		return recording.StartedBy == email || recording.RoomCreator == email
+	}), nil
+}
+
+// DeleteRecording forgets the recording
+func (r *memoryRecordingRegistry) DeleteRecording(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.recordings[id]; !exists {
+		return fmt.Errorf("%w: recording %s", ErrNotFound, id)
+	}
+	delete(r.recordings, id)
+	return nil
+}
+
+func (r *memoryRecordingRegistry) list(match func(*Recording) bool) []Recording {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var recordings []Recording
+	for recording := range maps.Values(r.recordings) {
+		if match(recording) {
+			recordings = append(recordings, *recording)
+		}
+	}
+	slices.SortFunc(recordings, func(a, b Recording) int {
+		return b.StartedAt.Compare(a.StartedAt)
+	})
+	return recordings
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDownloadTTL is how long a signed recording download link stays valid
+const DefaultDownloadTTL = 15 * time.Minute
+
+// RecordingDownload says where to fetch a recording's file from: a remote URL, or a
+// file under the local recording directory
+type RecordingDownload struct {
+	Recording *Recording
+	URL       string
+	Path      string
+}
+
+// DownloadLink is a signed, time-limited link to a recording's file
+type DownloadLink struct {
+	Path      string    `json:"path"` // relative to the service's public URL
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RecordingCatalogue configures access to recording files
+type RecordingCatalogue struct {
+	Dir         string        // local directory egress writes files into; empty if files only live remotely
+	SigningKey  []byte        // HMAC key for download links
+	DownloadTTL time.Duration // lifetime of download links
+}
+
+// GetRecording returns the recording if email may access it
+func (r *recorder) GetRecording(ctx context.Context, recordingID string, email string) (*Recording, error) {
+	recording, err := r.getRecording(ctx, recordingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.authorizeRecording(ctx, recording, email); err != nil {
+		return nil, err
+	}
+	return recording, nil
+}
+
+// ListRoomRecordings returns the room's recordings that email may access, newest first
+func (r *recorder) ListRoomRecordings(ctx context.Context, roomName string, email string) ([]Recording, error) {
+	recordings, err := r.recordings.ListRecordings(ctx, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+	return r.accessible(ctx, recordings, email)
+}
+
+// ListUserRecordings returns the recordings email started or made in rooms they created
+// and may still access, newest first
+func (r *recorder) ListUserRecordings(ctx context.Context, email string) ([]Recording, error) {
+	recordings, err := r.recordings.ListUserRecordings(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+	return r.accessible(ctx, recordings, email)
+}
+
+// DeleteRecording removes a finished recording from the catalogue together with its local file
+func (r *recorder) DeleteRecording(ctx context.Context, recordingID string, email string) error {
+	recording, err := r.GetRecording(ctx, recordingID, email)
+	if err != nil {
+		return err
+	}
+	if !recording.Status.Done() {
+		return fmt.Errorf("%w: recording %s is still %s", ErrConflict, recordingID, recording.Status)
+	}
+
+	if path, ok := r.localPath(recording); ok {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete file of recording %s: %w", recordingID, err)
+		}
+	}
+
+	if err := r.recordings.DeleteRecording(ctx, recordingID); err != nil {
+		return fmt.Errorf("failed to delete recording: %w", err)
+	}
+	return nil
+}
+
+// DownloadLink signs a link to the recording's file for email
+func (r *recorder) DownloadLink(ctx context.Context, recordingID string, email string) (*DownloadLink, error) {
+	recording, err := r.GetRecording(ctx, recordingID, email)
+	if err != nil {
+		return nil, err
+	}
+	if recording.Status != RecordingCompleted || recording.Location == "" {
+		return nil, fmt.Errorf("%w: recording %s has no file yet", ErrConflict, recordingID)
+	}
+
+	expiresAt := time.Now().Add(r.catalogue.DownloadTTL).UTC().Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", r.sign(recordingID, expires))
+
+	return &DownloadLink{
+		Path:      "/recordings/" + url.PathEscape(recordingID) + "/download?" + query.Encode(),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ResolveDownload checks a download link's signature and expiry and says where the file is
+func (r *recorder) ResolveDownload(ctx context.Context, recordingID, expires, signature string) (*RecordingDownload, error) {
+	if !hmac.Equal([]byte(signature), []byte(r.sign(recordingID, expires))) {
+		return nil, fmt.Errorf("%w: invalid download signature", ErrUnauthorized)
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return nil, fmt.Errorf("%w: download link expired", ErrUnauthorized)
+	}
+
+	recording, err := r.getRecording(ctx, recordingID)
+	if err != nil {
+		return nil, err
+	}
+
+	download := &RecordingDownload{Recording: recording}
+	switch {
+	case strings.HasPrefix(recording.Location, "https://"), strings.HasPrefix(recording.Location, "http://"):
+		download.URL = recording.Location
+	default:
+		path, ok := r.localPath(recording)
+		if !ok {
+			return nil, fmt.Errorf("%w: file of recording %s is not reachable by the service", ErrNotFound, recordingID)
+		}
+		download.Path = path
+	}
+	return download, nil
+}
+
+// sign computes the download signature of a recording and expiry
+func (r *recorder) sign(recordingID, expires string) string {
+	mac := hmac.New(sha256.New, r.catalogue.SigningKey)
+	mac.Write([]byte(recordingID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// localPath maps the recording's location onto a file under the recording directory.
+// Locations outside the directory are refused.
+func (r *recorder) localPath(recording *Recording) (string, bool) {
+	if r.catalogue.Dir == "" || recording.Location == "" {
+		return "", false
+	}
+	if strings.Contains(recording.Location, "://") && !strings.HasPrefix(recording.Location, "file://") {
+		return "", false
+	}
+
+	location := strings.TrimPrefix(recording.Location, "file://")
+	rel := location
+	if filepath.IsAbs(location) {
+		var err error
+		rel, err = filepath.Rel(r.catalogue.Dir, location)
+		if err != nil {
+			return "", false
+		}
+	}
+	if !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return filepath.Join(r.catalogue.Dir, rel), true
+}
+
+// authorizeRecording lets the room's creator and the room's hosts access it. Starting a
+// recording gives no access of its own: a host who loses the role loses it too.
+// Once the room is gone only the creator remains.
+func (r *recorder) authorizeRecording(ctx context.Context, recording *Recording, email string) error {
+	if email != "" && email == recording.RoomCreator {
+		return nil
+	}
+	if _, err := authorize(ctx, r.registry, recording.RoomName, email, PermRecord, "access recordings"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// accessible filters recordings down to the ones email may access
+func (r *recorder) accessible(ctx context.Context, recordings []Recording, email string) ([]Recording, error) {
+	visible := make([]Recording, 0, len(recordings))
+	for _, recording := range recordings {
+		err := r.authorizeRecording(ctx, &recording, email)
+		if errors.Is(err, ErrUnauthorized) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		visible = append(visible, recording)
+	}
+	return visible, nil
+}
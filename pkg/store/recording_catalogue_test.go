@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"open-meet/pkg/store/storetest"
+)
+
+// completedRecording starts and stops a room composite recording as recordingHost
+func completedRecording(t *testing.T, r *recorder) *Recording {
+	t.Helper()
+	ctx := context.Background()
+	started, err := r.StartRecording(ctx, recordingRoom, recordingHost, RecordingOptions{})
+	if err != nil {
+		t.Fatalf("StartRecording: %v", err)
+	}
+	stopped, err := r.StopRecording(ctx, recordingRoom, recordingHost, started.ID)
+	if err != nil {
+		t.Fatalf("StopRecording: %v", err)
+	}
+	return stopped
+}
+
+// linkQuery returns the expiry and signature of a download link
+func linkQuery(t *testing.T, link *DownloadLink) (string, string) {
+	t.Helper()
+	parsed, err := url.Parse(link.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Query().Get("expires"), parsed.Query().Get("signature")
+}
+
+func TestDownloadLinkSignature(t *testing.T) {
+	dir := t.TempDir()
+	eachRecorder(t, RecordingCatalogue{Dir: dir, SigningKey: []byte("test signing key")}, func(t *testing.T, r *recorder, server *storetest.RoomService) {
+		ctx := context.Background()
+		recording := completedRecording(t, r)
+
+		link, err := r.DownloadLink(ctx, recording.ID, recordingHost)
+		if err != nil {
+			t.Fatalf("DownloadLink: %v", err)
+		}
+		expires, signature := linkQuery(t, link)
+
+		download, err := r.ResolveDownload(ctx, recording.ID, expires, signature)
+		if err != nil {
+			t.Fatalf("ResolveDownload: %v", err)
+		}
+		if !strings.HasPrefix(download.Path, dir+string(filepath.Separator)) || filepath.Base(download.Path) != recording.FileName {
+			t.Errorf("download path = %q, want %s under %s", download.Path, recording.FileName, dir)
+		}
+
+		later := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+		tampered := []byte(signature)
+		tampered[0] ^= 1
+		tests := []struct {
+			name                 string
+			id, expires, signing string
+		}{
+			{name: "tampered signature", id: recording.ID, expires: expires, signing: string(tampered)},
+			{name: "extended expiry", id: recording.ID, expires: later, signing: signature},
+			{name: "other recording", id: "another-recording", expires: expires, signing: signature},
+			{name: "missing signature", id: recording.ID, expires: expires},
+		}
+		for _, tt := range tests {
+			if _, err := r.ResolveDownload(ctx, tt.id, tt.expires, tt.signing); !errors.Is(err, ErrUnauthorized) {
+				t.Errorf("%s: err = %v, want ErrUnauthorized", tt.name, err)
+			}
+		}
+	})
+}
+
+func TestDownloadLinkExpiry(t *testing.T) {
+	eachRecorder(t, RecordingCatalogue{Dir: t.TempDir(), SigningKey: []byte("test signing key")}, func(t *testing.T, r *recorder, server *storetest.RoomService) {
+		recording := completedRecording(t, r)
+
+		// A correctly signed link whose time has passed
+		expired := strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10)
+		_, err := r.ResolveDownload(context.Background(), recording.ID, expired, r.sign(recording.ID, expired))
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("ResolveDownload of an expired link: err = %v, want ErrUnauthorized", err)
+		}
+	})
+}
+
+func TestDownloadStaysInRecordingDir(t *testing.T) {
+	dir := t.TempDir()
+	eachRecorder(t, RecordingCatalogue{Dir: dir, SigningKey: []byte("test signing key")}, func(t *testing.T, r *recorder, server *storetest.RoomService) {
+		ctx := context.Background()
+		expires := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+
+		tests := []struct {
+			location string
+			want     string // path under dir, empty when the file must be refused
+		}{
+			{location: "file://recordings/standup.mp4", want: filepath.Join(dir, "recordings", "standup.mp4")},
+			{location: "file://" + filepath.Join(dir, "standup.mp4"), want: filepath.Join(dir, "standup.mp4")},
+			{location: "recordings/../standup.mp4", want: filepath.Join(dir, "standup.mp4")},
+			{location: "file://../standup.mp4"},
+			{location: "file://recordings/../../standup.mp4"},
+			{location: "../../etc/passwd"},
+			{location: "file:///etc/passwd"},
+			{location: "/etc/passwd"},
+			{location: "file://" + filepath.Join(dir, "..", "standup.mp4")},
+			{location: "s3://bucket/standup.mp4"},
+		}
+		for i, tt := range tests {
+			recording := &Recording{
+				ID:       "recording-" + strconv.Itoa(i),
+				EgressID: "EG_" + strconv.Itoa(i),
+				RoomName: recordingRoom,
+				Status:   RecordingCompleted,
+				Location: tt.location,
+			}
+			if err := r.recordings.SaveRecording(ctx, recording); err != nil {
+				t.Fatal(err)
+			}
+
+			download, err := r.ResolveDownload(ctx, recording.ID, expires, r.sign(recording.ID, expires))
+			if tt.want == "" {
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("%s: err = %v, want ErrNotFound", tt.location, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: %v", tt.location, err)
+				continue
+			}
+			if download.Path != tt.want {
+				t.Errorf("%s: path = %q, want %q", tt.location, download.Path, tt.want)
+			}
+		}
+	})
+}
+
+func TestRecordingCatalogueAccess(t *testing.T) {
+	eachRecorder(t, RecordingCatalogue{Dir: t.TempDir(), SigningKey: []byte("test signing key")}, func(t *testing.T, r *recorder, server *storetest.RoomService) {
+		ctx := context.Background()
+		recording := completedRecording(t, r)
+
+		if _, err := r.GetRecording(ctx, recording.ID, recordingGuest); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("GetRecording by a participant: err = %v, want ErrUnauthorized", err)
+		}
+		if _, err := r.DownloadLink(ctx, recording.ID, recordingGuest); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("DownloadLink by a participant: err = %v, want ErrUnauthorized", err)
+		}
+		if err := r.DeleteRecording(ctx, recording.ID, recordingGuest); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("DeleteRecording by a participant: err = %v, want ErrUnauthorized", err)
+		}
+		if list, err := r.ListRoomRecordings(ctx, recordingRoom, recordingGuest); err != nil || len(list) != 0 {
+			t.Errorf("ListRoomRecordings by a participant = %v, %v; want none", list, err)
+		}
+
+		// A co-host may record, but keeps no access to the recording once demoted
+		if err := r.registry.SetRole(ctx, recordingRoom, recordingGuest, RoleCoHost); err != nil {
+			t.Fatal(err)
+		}
+		started, err := r.StartRecording(ctx, recordingRoom, recordingGuest, RecordingOptions{})
+		if err != nil {
+			t.Fatalf("StartRecording by a co-host: %v", err)
+		}
+		if _, err := r.GetRecording(ctx, started.ID, recordingGuest); err != nil {
+			t.Errorf("GetRecording by a co-host: %v", err)
+		}
+		if err := r.registry.RemoveRole(ctx, recordingRoom, recordingGuest); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.GetRecording(ctx, started.ID, recordingGuest); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("GetRecording by a demoted starter: err = %v, want ErrUnauthorized", err)
+		}
+		if list, err := r.ListUserRecordings(ctx, recordingGuest); err != nil || len(list) != 0 {
+			t.Errorf("ListUserRecordings of a demoted starter = %v, %v; want none", list, err)
+		}
+		if _, err := r.GetRecording(ctx, started.ID, recordingHost); err != nil {
+			t.Errorf("GetRecording by the room's creator: %v", err)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+
+	"open-meet/pkg/store/storetest"
+)
+
+const (
+	recordingRoom  = "standup"
+	recordingHost  = "host@example.com"
+	recordingGuest = "ada@example.com"
+)
+
+// eachRecorder runs test against a recorder for a room owned by recordingHost, with
+// recordingGuest connected, once per RecordingRegistry. Egress is the local stand-in.
+func eachRecorder(t *testing.T, catalogue RecordingCatalogue, test func(t *testing.T, r *recorder, server *storetest.RoomService)) {
+	eachRegistry(t,
+		func() RecordingRegistry { return NewMemoryRecordingRegistry() },
+		func(db *sql.DB) RecordingRegistry { return NewSQLRecordingRegistry(db) },
+		func(t *testing.T, recordings RecordingRegistry) {
+			rooms := NewMemoryRoomRegistry()
+			if err := rooms.CreateRoom(context.Background(), recordingRoom, recordingHost); err != nil {
+				t.Fatal(err)
+			}
+			server := storetest.NewRoomService()
+			server.Join(recordingRoom, recordingGuest)
+
+			metadata := &metadataEditor{client: server, registry: rooms, recordings: recordings}
+			test(t, NewRecorder(NewLocalEgressClient(), rooms, recordings, metadata, "", catalogue), server)
+		})
+}
+
+// recordingAnnounced reports whether the room metadata says the room is being recorded
+func recordingAnnounced(t *testing.T, server *storetest.RoomService) bool {
+	t.Helper()
+	resp, err := server.ListRooms(context.Background(), &livekit.ListRoomsRequest{Names: []string{recordingRoom}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return DecodeRoomMetadata(resp.GetRooms()[0].GetMetadata()).Recording
+}
+
+func TestRecordingStartStop(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     RecordingOptions
+		kind     RecordingKind
+		fileName string // part of the file name the output template leads to
+	}{
+		{name: "room composite", kind: RecordingRoomComposite, fileName: ".mp4"},
+		{name: "track", opts: RecordingOptions{TrackSid: "TR_camera"}, kind: RecordingTrack, fileName: "TR_camera"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eachRecorder(t, RecordingCatalogue{}, func(t *testing.T, r *recorder, server *storetest.RoomService) {
+				ctx := context.Background()
+
+				started, err := r.StartRecording(ctx, recordingRoom, recordingHost, tt.opts)
+				if err != nil {
+					t.Fatalf("StartRecording: %v", err)
+				}
+				if started.Kind != tt.kind || started.Status != RecordingActive || started.EgressID == "" {
+					t.Errorf("started recording = %+v, want an active %s recording", started, tt.kind)
+				}
+				if started.StartedBy != recordingHost || started.RoomCreator != recordingHost {
+					t.Errorf("started by %q in a room created by %q, want %q", started.StartedBy, started.RoomCreator, recordingHost)
+				}
+				if !recordingAnnounced(t, server) {
+					t.Error("room metadata does not announce the recording")
+				}
+
+				stopped, err := r.StopRecording(ctx, recordingRoom, recordingHost, started.ID)
+				if err != nil {
+					t.Fatalf("StopRecording: %v", err)
+				}
+				if stopped.Status != RecordingCompleted || stopped.EndedAt.IsZero() {
+					t.Errorf("stopped recording = %+v, want completed", stopped)
+				}
+				if !strings.Contains(stopped.FileName, tt.fileName) {
+					t.Errorf("file name = %q, want it to contain %q", stopped.FileName, tt.fileName)
+				}
+				if recordingAnnounced(t, server) {
+					t.Error("room metadata still announces the recording")
+				}
+
+				if _, err := r.StopRecording(ctx, recordingRoom, recordingHost, started.ID); !errors.Is(err, ErrConflict) {
+					t.Errorf("StopRecording twice: err = %v, want ErrConflict", err)
+				}
+			})
+		})
+	}
+}
+
+func TestRecordingEgressUpdates(t *testing.T) {
+	tests := []struct {
+		name   string
+		status livekit.EgressStatus
+		error  string
+		want   RecordingStatus
+	}{
+		{name: "complete", status: livekit.EgressStatus_EGRESS_COMPLETE, want: RecordingCompleted},
+		{name: "limit reached", status: livekit.EgressStatus_EGRESS_LIMIT_REACHED, want: RecordingCompleted},
+		{name: "failed", status: livekit.EgressStatus_EGRESS_FAILED, error: "out of disk space", want: RecordingFailed},
+		{name: "aborted", status: livekit.EgressStatus_EGRESS_ABORTED, want: RecordingFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eachRecorder(t, RecordingCatalogue{}, func(t *testing.T, r *recorder, server *storetest.RoomService) {
+				ctx := context.Background()
+				started, err := r.StartRecording(ctx, recordingRoom, recordingHost, RecordingOptions{})
+				if err != nil {
+					t.Fatalf("StartRecording: %v", err)
+				}
+
+				got, err := r.HandleEgressUpdate(ctx, &livekit.EgressInfo{
+					EgressId: started.EgressID,
+					RoomName: recordingRoom,
+					Status:   tt.status,
+					Error:    tt.error,
+				})
+				if err != nil {
+					t.Fatalf("HandleEgressUpdate: %v", err)
+				}
+				if got.Status != tt.want || got.Error != tt.error {
+					t.Errorf("recording = %s with error %q, want %s with %q", got.Status, got.Error, tt.want, tt.error)
+				}
+				if recordingAnnounced(t, server) {
+					t.Error("room metadata still announces the recording")
+				}
+
+				// A late update from before the end never reopens the recording
+				late, err := r.HandleEgressUpdate(ctx, &livekit.EgressInfo{
+					EgressId: started.EgressID,
+					Status:   livekit.EgressStatus_EGRESS_ACTIVE,
+				})
+				if err != nil || late.Status != tt.want {
+					t.Errorf("late update = %v, %v; want %s", late, err, tt.want)
+				}
+			})
+		})
+	}
+}
+
+func TestRecordingIgnoresForeignEgress(t *testing.T) {
+	eachRecorder(t, RecordingCatalogue{}, func(t *testing.T, r *recorder, server *storetest.RoomService) {
+		got, err := r.HandleEgressUpdate(context.Background(), &livekit.EgressInfo{
+			EgressId: "EG_elsewhere",
+			Status:   livekit.EgressStatus_EGRESS_COMPLETE,
+		})
+		if err != nil || got != nil {
+			t.Errorf("HandleEgressUpdate = %v, %v; want nil", got, err)
+		}
+	})
+}
+
+func TestRecordingRequiresHost(t *testing.T) {
+	eachRecorder(t, RecordingCatalogue{}, func(t *testing.T, r *recorder, server *storetest.RoomService) {
+		ctx := context.Background()
+		if _, err := r.StartRecording(ctx, recordingRoom, recordingGuest, RecordingOptions{}); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("StartRecording by a participant: err = %v, want ErrUnauthorized", err)
+		}
+
+		started, err := r.StartRecording(ctx, recordingRoom, recordingHost, RecordingOptions{})
+		if err != nil {
+			t.Fatalf("StartRecording: %v", err)
+		}
+		if _, err := r.StopRecording(ctx, recordingRoom, recordingGuest, started.ID); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("StopRecording by a participant: err = %v, want ErrUnauthorized", err)
+		}
+	})
+}
@@ -0,0 +1,385 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NormalizeEmail returns email in the form the service stores and compares it in.
+// Emails identify users in rooms, roles, admissions and invites, so every email that
+// reaches the stores, whether a caller's own or one a host typed in, goes through it.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// RoomSettings holds per-room configuration owned by the service rather than LiveKit
+type RoomSettings struct {
+	Locked           bool             `json:"locked"`
+	SuccessionPolicy SuccessionPolicy `json:"succession_policy,omitempty"`
+	WaitingRoom      bool             `json:"waiting_room,omitempty"`
+	Guests           GuestPolicy      `json:"guests,omitempty"`          // empty keeps guests out
+	PublishSources   []string         `json:"publish_sources,omitempty"` // empty allows every source
+
+	ScreenShare       ScreenSharePolicy `json:"screen_share,omitempty"`
+	Presenters        []string          `json:"presenters,omitempty"`         // identities granted screen sharing
+	PresenterRequests []string          `json:"presenter_requests,omitempty"` // identities waiting for approval
+
+	MetadataVersion int64 `json:"metadata_version,omitempty"` // version of the last room metadata published
+}
+
+// RoomRecord is the service's own record of a room, kept independently of LiveKit
+type RoomRecord struct {
+	Name         string
+	CreatorEmail string
+	HostEmail    string
+	Settings     RoomSettings
+	CreatedAt    time.Time
+}
+
+// PresenceRecord tracks when a participant joined and left a room
+type PresenceRecord struct {
+	RoomName string
+	Identity string
+	JoinedAt time.Time
+	LeftAt   time.Time // zero while the participant is still in the room
+}
+
+// Present reports whether the participant is still in the room
+func (p PresenceRecord) Present() bool {
+	return p.LeftAt.IsZero()
+}
+
+// RoomRegistry is the single source of truth for room ownership and settings.
+// Both the Room and Host stores read and write it.
+type RoomRegistry interface {
+	CreateRoom(ctx context.Context, roomName, creatorEmail string) error
+	GetRoom(ctx context.Context, roomName string) (*RoomRecord, bool, error)
+	DeleteRoom(ctx context.Context, roomName string) error
+
+	SetHost(ctx context.Context, roomName, hostEmail string) error
+	GetRoomHost(ctx context.Context, roomName string) (string, bool, error)
+	IsHost(ctx context.Context, roomName, email string) (bool, error)
+	ClearHost(ctx context.Context, roomName string) error
+
+	SetRole(ctx context.Context, roomName, email string, role Role) error
+	RemoveRole(ctx context.Context, roomName, email string) error
+	GetRole(ctx context.Context, roomName, email string) (Role, error)
+	ListRoles(ctx context.Context, roomName string) (map[string]Role, error)
+
+	UpdateSettings(ctx context.Context, roomName string, update func(*RoomSettings)) error
+
+	RecordJoin(ctx context.Context, roomName, identity string, joinedAt time.Time) error
+	RecordLeave(ctx context.Context, roomName, identity string, leftAt time.Time) error
+	ListPresence(ctx context.Context, roomName string) ([]PresenceRecord, error)
+	// ClearPresence forgets who was in the room, keeping the room, its settings and roles
+	ClearPresence(ctx context.Context, roomName string) error
+
+	UpdateParticipantState(ctx context.Context, roomName, identity string, update func(*ParticipantState)) (*ParticipantState, error)
+	GetParticipantState(ctx context.Context, roomName, identity string) (*ParticipantState, bool, error)
+
+	RequestAdmission(ctx context.Context, roomName, email, displayName string) (AdmissionStatus, error)
+	DecideAdmission(ctx context.Context, roomName, email string, status AdmissionStatus, decidedBy string) error
+	ListAdmissions(ctx context.Context, roomName string, status AdmissionStatus) ([]Admission, error)
+}
+
+// memoryRoomRegistry implements RoomRegistry in process memory
+type memoryRoomRegistry struct {
+	mu         sync.RWMutex
+	rooms      map[string]*RoomRecord                 // map[roomName]record
+	presence   map[string]map[string]*PresenceRecord  // map[roomName]map[identity]record
+	roles      map[string]map[string]Role             // map[roomName]map[email]role, owner excluded
+	admissions map[string]map[string]*Admission       // map[roomName]map[email]admission
+	states     map[string]map[string]ParticipantState // map[roomName]map[identity]state
+}
+
+// NewMemoryRoomRegistry creates an empty in-memory room registry
+func NewMemoryRoomRegistry() *memoryRoomRegistry {
+	return &memoryRoomRegistry{
+		rooms:      make(map[string]*RoomRecord),
+		presence:   make(map[string]map[string]*PresenceRecord),
+		roles:      make(map[string]map[string]Role),
+		admissions: make(map[string]map[string]*Admission),
+		states:     make(map[string]map[string]ParticipantState),
+	}
+}
+
+// CreateRoom records a room with its creator as the initial host. Creating a room that is
+// already recorded, like a scheduled meeting's room opened again, keeps its settings.
+func (r *memoryRoomRegistry) CreateRoom(ctx context.Context, roomName, creatorEmail string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, exists := r.rooms[roomName]
+	if !exists {
+		record = &RoomRecord{Name: roomName}
+		r.rooms[roomName] = record
+	}
+	record.CreatorEmail = creatorEmail
+	record.HostEmail = creatorEmail
+	record.CreatedAt = time.Now().UTC()
+	return nil
+}
+
+// GetRoom returns a copy of the room record
+func (r *memoryRoomRegistry) GetRoom(ctx context.Context, roomName string) (*RoomRecord, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	record, exists := r.rooms[roomName]
+	if !exists {
+		return nil, false, nil
+	}
+	cp := *record
+	cp.Settings.PublishSources = slices.Clone(record.Settings.PublishSources)
+	cp.Settings.Presenters = slices.Clone(record.Settings.Presenters)
+	cp.Settings.PresenterRequests = slices.Clone(record.Settings.PresenterRequests)
+	return &cp, true, nil
+}
+
+// DeleteRoom forgets the room, its host and who was present
+func (r *memoryRoomRegistry) DeleteRoom(ctx context.Context, roomName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rooms, roomName)
+	delete(r.presence, roomName)
+	delete(r.roles, roomName)
+	delete(r.admissions, roomName)
+	delete(r.states, roomName)
+	return nil
+}
+
+// SetHost records hostEmail as the host of the room
+func (r *memoryRoomRegistry) SetHost(ctx context.Context, roomName, hostEmail string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, exists := r.rooms[roomName]
+	if !exists {
+		// Rooms created before the registry knew about them are adopted by their first host
+		record = &RoomRecord{
+			Name:         roomName,
+			CreatorEmail: hostEmail,
+			CreatedAt:    time.Now().UTC(),
+		}
+		r.rooms[roomName] = record
+	}
+	record.HostEmail = hostEmail
+	return nil
+}
+
+// GetRoomHost returns the host email for a room
+func (r *memoryRoomRegistry) GetRoomHost(ctx context.Context, roomName string) (string, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	record, exists := r.rooms[roomName]
+	if !exists || record.HostEmail == "" {
+		return "", false, nil
+	}
+	return record.HostEmail, true, nil
+}
+
+// IsHost checks if the given email is the host of the room
+func (r *memoryRoomRegistry) IsHost(ctx context.Context, roomName, email string) (bool, error) {
+	host, exists, err := r.GetRoomHost(ctx, roomName)
+	if err != nil {
+		return false, err
+	}
+	return exists && host == email, nil
+}
+
+// ClearHost leaves the room without a host
+func (r *memoryRoomRegistry) ClearHost(ctx context.Context, roomName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if record, exists := r.rooms[roomName]; exists {
+		record.HostEmail = ""
+	}
+	return nil
+}
+
+// SetRole grants a co-host or moderator role in the room
+func (r *memoryRoomRegistry) SetRole(ctx context.Context, roomName, email string, role Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.roles[roomName] == nil {
+		r.roles[roomName] = make(map[string]Role)
+	}
+	r.roles[roomName][email] = role
+	return nil
+}
+
+// RemoveRole returns the user to the participant role
+func (r *memoryRoomRegistry) RemoveRole(ctx context.Context, roomName, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.roles[roomName], email)
+	return nil
+}
+
+// GetRole returns the user's role in the room; the host is always the owner
+func (r *memoryRoomRegistry) GetRole(ctx context.Context, roomName, email string) (Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if record, exists := r.rooms[roomName]; exists && record.HostEmail != "" && record.HostEmail == email {
+		return RoleOwner, nil
+	}
+	if role, exists := r.roles[roomName][email]; exists {
+		return role, nil
+	}
+	return RoleParticipant, nil
+}
+
+// ListRoles returns every user holding a role above participant, including the owner
+func (r *memoryRoomRegistry) ListRoles(ctx context.Context, roomName string) (map[string]Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	roles := maps.Clone(r.roles[roomName])
+	if roles == nil {
+		roles = make(map[string]Role)
+	}
+	if record, exists := r.rooms[roomName]; exists && record.HostEmail != "" {
+		roles[record.HostEmail] = RoleOwner
+	}
+	return roles, nil
+}
+
+// UpdateSettings applies update to the room's settings
+func (r *memoryRoomRegistry) UpdateSettings(ctx context.Context, roomName string, update func(*RoomSettings)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, exists := r.rooms[roomName]
+	if !exists {
+		return fmt.Errorf("%w: room %s is not registered", ErrNotFound, roomName)
+	}
+	update(&record.Settings)
+	return nil
+}
+
+// RecordJoin marks the participant as present since joinedAt, starting them with a fresh state
+func (r *memoryRoomRegistry) RecordJoin(ctx context.Context, roomName, identity string, joinedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.states[roomName], identity)
+	if r.presence[roomName] == nil {
+		r.presence[roomName] = make(map[string]*PresenceRecord)
+	}
+	r.presence[roomName][identity] = &PresenceRecord{
+		RoomName: roomName,
+		Identity: identity,
+		JoinedAt: joinedAt.UTC(),
+	}
+	return nil
+}
+
+// RecordLeave marks the participant as gone since leftAt
+func (r *memoryRoomRegistry) RecordLeave(ctx context.Context, roomName, identity string, leftAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, exists := r.presence[roomName][identity]
+	if !exists {
+		return nil
+	}
+	record.LeftAt = leftAt.UTC()
+	return nil
+}
+
+// ListPresence returns the room's presence records, earliest join first
+func (r *memoryRoomRegistry) ListPresence(ctx context.Context, roomName string) ([]PresenceRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	records := make([]PresenceRecord, 0, len(r.presence[roomName]))
+	for _, record := range r.presence[roomName] {
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].JoinedAt.Before(records[j].JoinedAt)
+	})
+	return records, nil
+}
+
+// ClearPresence forgets who was in the room and their participant states
+func (r *memoryRoomRegistry) ClearPresence(ctx context.Context, roomName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.presence, roomName)
+	delete(r.states, roomName)
+	return nil
+}
+
+// UpdateParticipantState applies update to the participant's state and returns the result
+func (r *memoryRoomRegistry) UpdateParticipantState(ctx context.Context, roomName, identity string, update func(*ParticipantState)) (*ParticipantState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.states[roomName] == nil {
+		r.states[roomName] = make(map[string]ParticipantState)
+	}
+	state := r.states[roomName][identity]
+	update(&state)
+	r.states[roomName][identity] = state
+	return &state, nil
+}
+
+// GetParticipantState returns the participant's state
+func (r *memoryRoomRegistry) GetParticipantState(ctx context.Context, roomName, identity string) (*ParticipantState, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	state, exists := r.states[roomName][identity]
+	if !exists {
+		return nil, false, nil
+	}
+	return &state, true, nil
+}
+
+// RequestAdmission queues email for the waiting room unless a decision already exists, and returns its status
+func (r *memoryRoomRegistry) RequestAdmission(ctx context.Context, roomName, email, displayName string) (AdmissionStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.admissions[roomName] == nil {
+		r.admissions[roomName] = make(map[string]*Admission)
+	}
+	admission, exists := r.admissions[roomName][email]
+	if !exists {
+		admission = &Admission{
+			RoomName:    roomName,
+			Email:       email,
+			DisplayName: displayName,
+			Status:      AdmissionPending,
+			RequestedAt: time.Now().UTC(),
+		}
+		r.admissions[roomName][email] = admission
+	}
+	return admission.Status, nil
+}
+
+// DecideAdmission records a host's decision on a waiting-room request
+func (r *memoryRoomRegistry) DecideAdmission(ctx context.Context, roomName, email string, status AdmissionStatus, decidedBy string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	admission, exists := r.admissions[roomName][email]
+	if !exists {
+		return fmt.Errorf("%w: %s is not waiting to join room %s", ErrNotFound, email, roomName)
+	}
+	admission.Status = status
+	admission.DecidedAt = time.Now().UTC()
+	admission.DecidedBy = decidedBy
+	return nil
+}
+
+// ListAdmissions returns waiting-room requests with the given status, or all when status is empty, oldest first
+func (r *memoryRoomRegistry) ListAdmissions(ctx context.Context, roomName string, status AdmissionStatus) ([]Admission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	admissions := make([]Admission, 0, len(r.admissions[roomName]))
+	for _, admission := range r.admissions[roomName] {
+		if status == "" || admission.Status == status {
+			admissions = append(admissions, *admission)
+		}
+	}
+	sort.Slice(admissions, func(i, j int) bool {
+		return admissions[i].RequestedAt.Before(admissions[j].RequestedAt)
+	})
+	return admissions, nil
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+const (
+	registryRoom = "standup"
+	registryHost = "host@example.com"
+)
+
+// eachRooms runs test against a room owned by registryHost, once per RoomRegistry
+func eachRooms(t *testing.T, test func(t *testing.T, registry RoomRegistry)) {
+	eachRegistry(t,
+		func() RoomRegistry { return NewMemoryRoomRegistry() },
+		func(db *sql.DB) RoomRegistry { return NewSQLRoomRegistry(db) },
+		func(t *testing.T, registry RoomRegistry) {
+			if err := registry.CreateRoom(context.Background(), registryRoom, registryHost); err != nil {
+				t.Fatal(err)
+			}
+			test(t, registry)
+		})
+}
+
+func TestClearPresenceKeepsRoom(t *testing.T) {
+	eachRooms(t, func(t *testing.T, registry RoomRegistry) {
+		ctx := context.Background()
+		if err := registry.UpdateSettings(ctx, registryRoom, func(s *RoomSettings) { s.WaitingRoom = true }); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.SetRole(ctx, registryRoom, "ada@example.com", RoleCoHost); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.RecordJoin(ctx, registryRoom, "ada@example.com", time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := registry.UpdateParticipantState(ctx, registryRoom, "ada@example.com", func(s *ParticipantState) { s.HandRaised = true }); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := registry.ClearPresence(ctx, registryRoom); err != nil {
+			t.Fatalf("ClearPresence: %v", err)
+		}
+
+		if presence, err := registry.ListPresence(ctx, registryRoom); err != nil || len(presence) != 0 {
+			t.Errorf("presence = %v, %v; want none", presence, err)
+		}
+		if _, found, err := registry.GetParticipantState(ctx, registryRoom, "ada@example.com"); err != nil || found {
+			t.Errorf("participant state found = %v, %v; want none", found, err)
+		}
+		record, found, err := registry.GetRoom(ctx, registryRoom)
+		if err != nil || !found {
+			t.Fatalf("GetRoom = %v, %v; want the room kept", found, err)
+		}
+		if !record.Settings.WaitingRoom {
+			t.Error("settings were lost")
+		}
+		if role, err := registry.GetRole(ctx, registryRoom, "ada@example.com"); err != nil || role != RoleCoHost {
+			t.Errorf("role = %s, %v; want %s", role, err, RoleCoHost)
+		}
+	})
+}
+
+func TestDeleteRoomForgetsEverything(t *testing.T) {
+	eachRooms(t, func(t *testing.T, registry RoomRegistry) {
+		ctx := context.Background()
+		if err := registry.UpdateSettings(ctx, registryRoom, func(s *RoomSettings) { s.Locked = true }); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.SetRole(ctx, registryRoom, "ada@example.com", RoleModerator); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := registry.RequestAdmission(ctx, registryRoom, "bob@example.com", ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.RecordJoin(ctx, registryRoom, "ada@example.com", time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := registry.UpdateParticipantState(ctx, registryRoom, "ada@example.com", func(s *ParticipantState) { s.HandRaised = true }); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := registry.DeleteRoom(ctx, registryRoom); err != nil {
+			t.Fatalf("DeleteRoom: %v", err)
+		}
+
+		if _, found, err := registry.GetRoom(ctx, registryRoom); err != nil || found {
+			t.Errorf("GetRoom after DeleteRoom: found = %v, %v", found, err)
+		}
+		if roles, err := registry.ListRoles(ctx, registryRoom); err != nil || len(roles) != 0 {
+			t.Errorf("roles = %v, %v; want none", roles, err)
+		}
+		if admissions, err := registry.ListAdmissions(ctx, registryRoom, ""); err != nil || len(admissions) != 0 {
+			t.Errorf("admissions = %v, %v; want none", admissions, err)
+		}
+		if presence, err := registry.ListPresence(ctx, registryRoom); err != nil || len(presence) != 0 {
+			t.Errorf("presence = %v, %v; want none", presence, err)
+		}
+		if _, found, err := registry.GetParticipantState(ctx, registryRoom, "ada@example.com"); err != nil || found {
+			t.Errorf("participant state found = %v, %v; want none", found, err)
+		}
+
+		// A room created again under the same name starts from scratch
+		if err := registry.CreateRoom(ctx, registryRoom, "ada@example.com"); err != nil {
+			t.Fatalf("CreateRoom: %v", err)
+		}
+		record, _, err := registry.GetRoom(ctx, registryRoom)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Settings.Locked || record.HostEmail != "ada@example.com" {
+			t.Errorf("recreated room = %+v, want fresh settings hosted by ada@example.com", record)
+		}
+		if role, err := registry.GetRole(ctx, registryRoom, "bob@example.com"); err != nil || role != RoleParticipant {
+			t.Errorf("role of bob = %s, %v; want %s", role, err, RoleParticipant)
+		}
+	})
+}
+
+func TestSQLUpdateSettingsRetriesOnConcurrentChange(t *testing.T) {
+	ctx := context.Background()
+	registry := NewSQLRoomRegistry(openTestDatabase(t))
+	if err := registry.CreateRoom(ctx, registryRoom, registryHost); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another writer locks the room between this update's read and its write
+	interleaved := false
+	err := registry.UpdateSettings(ctx, registryRoom, func(s *RoomSettings) {
+		if !interleaved {
+			interleaved = true
+			if err := registry.UpdateSettings(ctx, registryRoom, func(s *RoomSettings) { s.Locked = true }); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s.WaitingRoom = true
+	})
+	if err != nil {
+		t.Fatalf("UpdateSettings: %v", err)
+	}
+
+	record, _, err := registry.GetRoom(ctx, registryRoom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !record.Settings.Locked || !record.Settings.WaitingRoom {
+		t.Errorf("settings = %+v, want both changes kept", record.Settings)
+	}
+}
+
+func TestSQLUpdateSettingsGivesUpAfterRetries(t *testing.T) {
+	ctx := context.Background()
+	registry := NewSQLRoomRegistry(openTestDatabase(t))
+	if err := registry.CreateRoom(ctx, registryRoom, registryHost); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every attempt loses to another writer
+	attempts := 0
+	err := registry.UpdateSettings(ctx, registryRoom, func(s *RoomSettings) {
+		attempts++
+		if err := registry.UpdateSettings(ctx, registryRoom, func(s *RoomSettings) { s.MetadataVersion++ }); err != nil {
+			t.Fatal(err)
+		}
+		s.Locked = true
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("UpdateSettings: err = %v, want ErrConflict", err)
+	}
+	if attempts != maxSettingsRetries {
+		t.Errorf("attempts = %d, want %d", attempts, maxSettingsRetries)
+	}
+}
+
+func TestSQLMigrationsAreIdempotent(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+	registry := NewSQLRoomRegistry(db)
+	if err := registry.CreateRoom(ctx, registryRoom, registryHost); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrate(ctx, db); err != nil {
+		t.Fatalf("migrate again: %v", err)
+	}
+
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		t.Fatal(err)
+	}
+	if applied != len(names) {
+		t.Errorf("applied migrations = %d, want %d", applied, len(names))
+	}
+	if _, found, err := registry.GetRoom(ctx, registryRoom); err != nil || !found {
+		t.Errorf("GetRoom after migrating again: found = %v, %v", found, err)
+	}
+}
+
+func TestCreateRoomAgainKeepsSettings(t *testing.T) {
+	eachRooms(t, func(t *testing.T, registry RoomRegistry) {
+		ctx := context.Background()
+		if err := registry.UpdateSettings(ctx, registryRoom, func(s *RoomSettings) {
+			s.Locked = true
+			s.WaitingRoom = true
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// The session ends, as on room_finished, and the scheduled room is opened again
+		if err := registry.ClearPresence(ctx, registryRoom); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.ClearHost(ctx, registryRoom); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.CreateRoom(ctx, registryRoom, registryHost); err != nil {
+			t.Fatalf("CreateRoom again: %v", err)
+		}
+
+		record, found, err := registry.GetRoom(ctx, registryRoom)
+		if err != nil || !found {
+			t.Fatalf("GetRoom = %v, %v", found, err)
+		}
+		if !record.Settings.Locked || !record.Settings.WaitingRoom {
+			t.Errorf("settings = %+v, want the lock and waiting room kept", record.Settings)
+		}
+		if record.HostEmail != registryHost {
+			t.Errorf("host = %q, want %q", record.HostEmail, registryHost)
+		}
+	})
+}
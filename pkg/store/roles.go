@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Role is a user's standing in a room
+type Role string
+
+const (
+	// RoleOwner is the room's host; there is exactly one per room
+	RoleOwner Role = "owner"
+	// RoleCoHost helps run the meeting but cannot end it or give it away
+	RoleCoHost Role = "co-host"
+	// RoleModerator keeps order by muting participants
+	RoleModerator Role = "moderator"
+	// RoleParticipant is everybody else
+	RoleParticipant Role = "participant"
+	// RoleViewer may watch and listen but not publish
+	RoleViewer Role = "viewer"
+)
+
+// Permission is a host control action guarded by role
+type Permission string
+
+const (
+	PermEndMeeting        Permission = "end_meeting"
+	PermLockRoom          Permission = "lock_room"
+	PermKickParticipant   Permission = "kick_participant"
+	PermMuteParticipant   Permission = "mute_participant"
+	PermTransferOwnership Permission = "transfer_ownership"
+	PermManageCoHosts     Permission = "manage_co_hosts"
+	PermManageSuccession  Permission = "manage_succession"
+	PermAdmitParticipants Permission = "admit_participants"
+	PermManagePolicy      Permission = "manage_policy"
+	PermRecord            Permission = "record"
+)
+
+// rolePermissions is the permission matrix checked by every Host method
+var rolePermissions = map[Role][]Permission{
+	RoleOwner: {
+		PermEndMeeting,
+		PermLockRoom,
+		PermKickParticipant,
+		PermMuteParticipant,
+		PermTransferOwnership,
+		PermManageCoHosts,
+		PermManageSuccession,
+		PermAdmitParticipants,
+		PermManagePolicy,
+		PermRecord,
+	},
+	RoleCoHost: {
+		PermLockRoom,
+		PermAdmitParticipants,
+		PermManagePolicy,
+		PermKickParticipant,
+		PermMuteParticipant,
+		PermRecord,
+	},
+	RoleModerator: {
+		PermMuteParticipant,
+	},
+}
+
+// authorize returns ErrUnauthorized unless email's role in the room grants perm
+func authorize(ctx context.Context, registry RoomRegistry, roomName, email string, perm Permission, action string) (Role, error) {
+	role, err := registry.GetRole(ctx, roomName, email)
+	if err != nil {
+		return "", fmt.Errorf("failed to check role: %w", err)
+	}
+	if !role.Can(perm) {
+		return "", fmt.Errorf("%w: %s cannot %s", ErrUnauthorized, role, action)
+	}
+	return role, nil
+}
+
+// roleRank orders roles so nobody can act on someone at or above their own level
+var roleRank = map[Role]int{
+	RoleViewer:      -1,
+	RoleParticipant: 0,
+	RoleModerator:   1,
+	RoleCoHost:      2,
+	RoleOwner:       3,
+}
+
+// ParseRole validates a role name that can be granted through AddCoHost
+func ParseRole(name string) (Role, error) {
+	switch role := Role(name); role {
+	case RoleCoHost, RoleModerator, RoleViewer:
+		return role, nil
+	default:
+		return "", fmt.Errorf("role %q cannot be granted, expected %q, %q or %q", name, RoleCoHost, RoleModerator, RoleViewer)
+	}
+}
+
+// Can reports whether the role grants the permission
+func (r Role) Can(p Permission) bool {
+	return slices.Contains(rolePermissions[r], p)
+}
+
+// Outranks reports whether r sits strictly above other
+func (r Role) Outranks(other Role) bool {
+	return roleRank[r] > roleRank[other]
+}
@@ -4,31 +4,43 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"sync"
 
 	"github.com/livekit/protocol/livekit"
 	lksdk "github.com/livekit/server-sdk-go/v2"
 )
 
+// RoomServiceClient is the part of the LiveKit room service API the stores use.
+// *lksdk.RoomServiceClient satisfies it.
+type RoomServiceClient interface {
+	CreateRoom(ctx context.Context, req *livekit.CreateRoomRequest) (*livekit.Room, error)
+	ListRooms(ctx context.Context, req *livekit.ListRoomsRequest) (*livekit.ListRoomsResponse, error)
+	DeleteRoom(ctx context.Context, req *livekit.DeleteRoomRequest) (*livekit.DeleteRoomResponse, error)
+	UpdateRoomMetadata(ctx context.Context, req *livekit.UpdateRoomMetadataRequest) (*livekit.Room, error)
+	ListParticipants(ctx context.Context, req *livekit.ListParticipantsRequest) (*livekit.ListParticipantsResponse, error)
+	GetParticipant(ctx context.Context, req *livekit.RoomParticipantIdentity) (*livekit.ParticipantInfo, error)
+	UpdateParticipant(ctx context.Context, req *livekit.UpdateParticipantRequest) (*livekit.ParticipantInfo, error)
+	RemoveParticipant(ctx context.Context, req *livekit.RoomParticipantIdentity) (*livekit.RemoveParticipantResponse, error)
+	MutePublishedTrack(ctx context.Context, req *livekit.MuteRoomTrackRequest) (*livekit.MuteRoomTrackResponse, error)
+}
+
 // Room defines the interface for room operations
 type Room interface {
 	Create(ctx context.Context, name, creatorEmail string) (*livekit.Room, error)
 	Get(ctx context.Context, name string) (*livekit.Room, bool, error)
 	List(ctx context.Context) ([]*livekit.Room, error)
 	Delete(ctx context.Context, name string) error
-	SetHost(roomName, hostEmail string)
-	GetRoomHost(roomName string) (string, bool)
-	IsHost(roomName, email string) bool
+	SetHost(ctx context.Context, roomName, hostEmail string) error
+	GetRoomHost(ctx context.Context, roomName string) (string, bool, error)
+	IsHost(ctx context.Context, roomName, email string) (bool, error)
 }
 
 // LiveKitRoom implements Room interface
 type LiveKitRoom struct {
-	client *lksdk.RoomServiceClient
-	mu     sync.RWMutex
-	hosts  map[string]string // map[roomName]hostEmail
+	client   RoomServiceClient
+	registry RoomRegistry
 }
 
-func NewLiveKitRoom() (*LiveKitRoom, error) {
+func NewLiveKitRoom(registry RoomRegistry) (*LiveKitRoom, error) {
 	hostURL := os.Getenv("LIVEKIT_SERVER")
 	apiKey := os.Getenv("LIVEKIT_API_KEY")
 	apiSecret := os.Getenv("LIVEKIT_API_SECRET")
@@ -40,8 +52,8 @@ func NewLiveKitRoom() (*LiveKitRoom, error) {
 	client := lksdk.NewRoomServiceClient(hostURL, apiKey, apiSecret)
 
 	return &LiveKitRoom{
-		client: client,
-		hosts:  make(map[string]string),
+		client:   client,
+		registry: registry,
 	}, nil
 }
 
@@ -56,8 +68,10 @@ func (r *LiveKitRoom) Create(ctx context.Context, name, creatorEmail string) (*l
 		return nil, fmt.Errorf("failed to create room: %w", err)
 	}
 
-	// Set creator as host
-	r.SetHost(name, creatorEmail)
+	// Record creator as owner and host
+	if err := r.registry.CreateRoom(ctx, name, creatorEmail); err != nil {
+		return nil, fmt.Errorf("failed to register room: %w", err)
+	}
 	return room, nil
 }
 
@@ -96,32 +110,24 @@ func (r *LiveKitRoom) Delete(ctx context.Context, name string) error {
 	}
 
 	// Remove host mapping
-	r.mu.Lock()
-	delete(r.hosts, name)
-	r.mu.Unlock()
+	if err := r.registry.DeleteRoom(ctx, name); err != nil {
+		return fmt.Errorf("failed to forget room %s: %w", name, err)
+	}
 
 	return nil
 }
 
 // SetHost sets the host for a room
-func (r *LiveKitRoom) SetHost(roomName, hostEmail string) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.hosts[roomName] = hostEmail
+func (r *LiveKitRoom) SetHost(ctx context.Context, roomName, hostEmail string) error {
+	return r.registry.SetHost(ctx, roomName, hostEmail)
 }
 
 // GetRoomHost returns the host email for a room
-func (r *LiveKitRoom) GetRoomHost(roomName string) (string, bool) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	host, exists := r.hosts[roomName]
-	return host, exists
+func (r *LiveKitRoom) GetRoomHost(ctx context.Context, roomName string) (string, bool, error) {
+	return r.registry.GetRoomHost(ctx, roomName)
 }
 
 // IsHost checks if the given email is the host of the room
-func (r *LiveKitRoom) IsHost(roomName, email string) bool {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	host, exists := r.hosts[roomName]
-	return exists && host == email
+func (r *LiveKitRoom) IsHost(ctx context.Context, roomName, email string) (bool, error) {
+	return r.registry.IsHost(ctx, roomName, email)
 }
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// ConnectionQualityUnknown is reported for participants whose client never sent a quality report
+const ConnectionQualityUnknown = "unknown"
+
+// RosterEntry describes a participant as shown in the room's participant list
+type RosterEntry struct {
+	Identity          string         `json:"identity"`
+	Name              string         `json:"name"`
+	Avatar            string         `json:"avatar,omitempty"`
+	Role              Role           `json:"role"`
+	Guest             bool           `json:"guest,omitempty"`
+	JoinedAt          time.Time      `json:"joined_at"`
+	Tracks            []TrackSummary `json:"tracks"`
+	ConnectionQuality string         `json:"connection_quality"`
+}
+
+// TrackSummary describes one published track
+type TrackSummary struct {
+	Sid    string `json:"sid"`
+	Source string `json:"source"`
+	Muted  bool   `json:"muted"`
+}
+
+// Roster lists the visible participants of a room with their profile and role, earliest join first.
+// Hidden observers such as recorders are left out.
+func (p *participant) Roster(ctx context.Context, roomName string) ([]RosterEntry, error) {
+	participants, err := p.ListParticipants(ctx, roomName)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := p.registry.ListRoles(ctx, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	roster := make([]RosterEntry, 0, len(participants))
+	for _, info := range participants {
+		if info.GetPermission().GetHidden() {
+			continue
+		}
+		roster = append(roster, newRosterEntry(info, roles))
+	}
+
+	sort.SliceStable(roster, func(i, j int) bool {
+		if !roster[i].JoinedAt.Equal(roster[j].JoinedAt) {
+			return roster[i].JoinedAt.Before(roster[j].JoinedAt)
+		}
+		return roster[i].Identity < roster[j].Identity
+	})
+
+	return roster, nil
+}
+
+// ReportConnectionQuality records the connection quality the participant's client measured
+func (p *participant) ReportConnectionQuality(ctx context.Context, roomName, identity, quality string) error {
+	if _, ok := livekit.ConnectionQuality_value[quality]; !ok {
+		return fmt.Errorf("%w: unknown connection quality %q", ErrInvalid, quality)
+	}
+
+	_, err := p.metadata.UpdateParticipant(ctx, roomName, identity, func(state *ParticipantState) {
+		state.ConnectionQuality = quality
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record connection quality: %w", err)
+	}
+	return nil
+}
+
+func newRosterEntry(info *livekit.ParticipantInfo, roles map[string]Role) RosterEntry {
+	metadata := DecodeParticipantMetadata(info.GetMetadata())
+
+	entry := RosterEntry{
+		Identity:          info.GetIdentity(),
+		Name:              info.GetName(),
+		Avatar:            metadata.Avatar,
+		Role:              RoleParticipant,
+		JoinedAt:          time.Unix(info.GetJoinedAt(), 0).UTC(),
+		Tracks:            make([]TrackSummary, 0, len(info.GetTracks())),
+		ConnectionQuality: metadata.ConnectionQuality,
+		Guest:             metadata.Guest,
+	}
+	if entry.Name == "" {
+		entry.Name = metadata.DisplayName
+	}
+	if role, ok := roles[info.GetIdentity()]; ok {
+		entry.Role = role
+	}
+	if entry.ConnectionQuality == "" {
+		entry.ConnectionQuality = ConnectionQualityUnknown
+	}
+
+	for _, track := range info.GetTracks() {
+		entry.Tracks = append(entry.Tracks, TrackSummary{
+			Sid:    track.GetSid(),
+			Source: track.GetSource().String(),
+			Muted:  track.GetMuted(),
+		})
+	}
+
+	return entry
+}
@@ -0,0 +1,288 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrPresenterRequested is returned when a participant asks to share their screen
+// in a room where a host has to approve presenters first
+var ErrPresenterRequested = errors.New("waiting for a host to approve screen sharing")
+
+// ScreenSharePolicy decides who may share their screen in a room.
+// Owners, co-hosts and moderators may always share; viewers never can.
+type ScreenSharePolicy string
+
+const (
+	// ScreenShareAnyone lets every participant share their screen
+	ScreenShareAnyone ScreenSharePolicy = "anyone"
+	// ScreenShareHosts reserves screen sharing for hosts
+	ScreenShareHosts ScreenSharePolicy = "hosts"
+	// ScreenShareOnePresenter lets a single participant, chosen by a host, present at a time
+	ScreenShareOnePresenter ScreenSharePolicy = "one_presenter"
+	// ScreenShareApproval lets participants ask to present and hosts approve them
+	ScreenShareApproval ScreenSharePolicy = "approval"
+)
+
+// ParseScreenSharePolicy validates a screen-share policy name
+func ParseScreenSharePolicy(name string) (ScreenSharePolicy, error) {
+	switch policy := ScreenSharePolicy(name); policy {
+	case ScreenShareAnyone, ScreenShareHosts, ScreenShareOnePresenter, ScreenShareApproval:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: unknown screen share policy %q", ErrInvalid, name)
+	}
+}
+
+// ScreenShareState is the room's screen-share policy with its presenters and pending requests
+type ScreenShareState struct {
+	Policy     ScreenSharePolicy `json:"policy"`
+	Presenters []string          `json:"presenters"`
+	Requests   []string          `json:"requests"`
+}
+
+// screenSharePolicy returns the room's policy, falling back to ScreenShareAnyone
+func (s RoomSettings) screenSharePolicy() ScreenSharePolicy {
+	if s.ScreenShare == "" {
+		return ScreenShareAnyone
+	}
+	return s.ScreenShare
+}
+
+// usesPresenters reports whether hosts choose who may share their screen under the policy
+func (p ScreenSharePolicy) usesPresenters() bool {
+	return p == ScreenShareOnePresenter || p == ScreenShareApproval
+}
+
+// canShareScreen reports whether a participant below host rank may share their screen
+func (s RoomSettings) canShareScreen(identity string) bool {
+	switch s.screenSharePolicy() {
+	case ScreenShareAnyone:
+		return true
+	case ScreenShareOnePresenter, ScreenShareApproval:
+		return slices.Contains(s.Presenters, identity)
+	default:
+		return false
+	}
+}
+
+// SetScreenSharePolicy changes the room's screen-share policy and applies it to everybody in the room.
+// Presenters and requests from the previous policy are dropped.
+func (h *host) SetScreenSharePolicy(ctx context.Context, roomName string, hostEmail string, policy ScreenSharePolicy) error {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermManagePolicy, "change the screen share policy"); err != nil {
+		return err
+	}
+
+	if err := h.registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		settings.ScreenShare = policy
+		settings.Presenters = nil
+		settings.PresenterRequests = nil
+	}); err != nil {
+		return fmt.Errorf("failed to store screen share policy: %w", err)
+	}
+
+	resp, err := h.client.ListParticipants(ctx, &livekit.ListParticipantsRequest{
+		Room: roomName,
+	})
+	if err != nil {
+		err = translateError(err, "failed to list participants")
+		if errors.Is(err, ErrNotFound) {
+			// Nobody is connected; the policy applies to tokens issued from now on
+			return nil
+		}
+		return err
+	}
+	for _, p := range resp.GetParticipants() {
+		if err := applyPublishPermission(ctx, h.client, h.registry, roomName, p.GetIdentity()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GrantPresenter lets identity share their screen. With ScreenShareOnePresenter the
+// previous presenter loses the right, which also stops their screen share. Policies
+// without presenters refuse with ErrConflict.
+func (h *host) GrantPresenter(ctx context.Context, roomName string, hostEmail string, identity string) error {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermManagePolicy, "grant presenter rights"); err != nil {
+		return err
+	}
+
+	var (
+		revoked []string
+		policy  ScreenSharePolicy
+	)
+	err := h.registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		policy = settings.screenSharePolicy()
+		if !policy.usesPresenters() {
+			return
+		}
+		settings.PresenterRequests = slices.DeleteFunc(settings.PresenterRequests, func(e string) bool { return e == identity })
+		switch {
+		case settings.screenSharePolicy() == ScreenShareOnePresenter:
+			revoked = slices.DeleteFunc(settings.Presenters, func(e string) bool { return e == identity })
+			settings.Presenters = []string{identity}
+		case !slices.Contains(settings.Presenters, identity):
+			settings.Presenters = append(settings.Presenters, identity)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to grant presenter: %w", err)
+	}
+	if !policy.usesPresenters() {
+		return fmt.Errorf("%w: the %s screen share policy has no presenters", ErrConflict, policy)
+	}
+
+	for _, previous := range append(revoked, identity) {
+		if err := applyPublishPermission(ctx, h.client, h.registry, roomName, previous); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevokePresenter takes away identity's presenter rights or declines their request
+func (h *host) RevokePresenter(ctx context.Context, roomName string, hostEmail string, identity string) error {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermManagePolicy, "revoke presenter rights"); err != nil {
+		return err
+	}
+
+	err := h.registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		settings.Presenters = slices.DeleteFunc(settings.Presenters, func(e string) bool { return e == identity })
+		settings.PresenterRequests = slices.DeleteFunc(settings.PresenterRequests, func(e string) bool { return e == identity })
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke presenter: %w", err)
+	}
+
+	return applyPublishPermission(ctx, h.client, h.registry, roomName, identity)
+}
+
+// GetScreenShareState returns the room's screen-share policy, presenters and pending requests
+func (h *host) GetScreenShareState(ctx context.Context, roomName string, hostEmail string) (*ScreenShareState, error) {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermManagePolicy, "view presenters"); err != nil {
+		return nil, err
+	}
+
+	record, found, err := h.registry.GetRoom(ctx, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: room %s is not registered", ErrNotFound, roomName)
+	}
+
+	state := &ScreenShareState{
+		Policy:     record.Settings.screenSharePolicy(),
+		Presenters: record.Settings.Presenters,
+		Requests:   record.Settings.PresenterRequests,
+	}
+	if state.Presenters == nil {
+		state.Presenters = []string{}
+	}
+	if state.Requests == nil {
+		state.Requests = []string{}
+	}
+	return state, nil
+}
+
+// requestPresenter queues identity for presenter approval when the room asks for it.
+// It returns ErrPresenterRequested once queued, and nil if identity may already present.
+func requestPresenter(ctx context.Context, registry RoomRegistry, roomName, identity string) error {
+	record, found, err := registry.GetRoom(ctx, roomName)
+	if err != nil {
+		return fmt.Errorf("failed to get room: %w", err)
+	}
+	if !found || record.Settings.screenSharePolicy() != ScreenShareApproval {
+		return nil
+	}
+
+	role, err := registry.GetRole(ctx, roomName, identity)
+	if err != nil {
+		return fmt.Errorf("failed to check role: %w", err)
+	}
+	if role.Outranks(RoleParticipant) || record.Settings.canShareScreen(identity) {
+		return nil
+	}
+
+	err = registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		if !slices.Contains(settings.PresenterRequests, identity) {
+			settings.PresenterRequests = append(settings.PresenterRequests, identity)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to request presenter rights: %w", err)
+	}
+	return fmt.Errorf("%w: %s in room %s", ErrPresenterRequested, identity, roomName)
+}
+
+// applyPublishPermission brings a connected participant's publish permission in line with the
+// room policy, as a fresh join token would grant it. Participants who are not connected pick
+// the policy up with their next token.
+func applyPublishPermission(ctx context.Context, client RoomServiceClient, registry RoomRegistry, roomName, identity string) error {
+	role, err := registry.GetRole(ctx, roomName, identity)
+	if err != nil {
+		return fmt.Errorf("failed to check role: %w", err)
+	}
+	guest := IsGuestIdentity(identity)
+	if guest && role != RoleViewer {
+		// Guests never hold room roles, as when their token was issued
+		role = RoleParticipant
+	}
+	if role != RoleParticipant {
+		// Hosts are never restricted and viewers never publish
+		return nil
+	}
+
+	record, found, err := registry.GetRoom(ctx, roomName)
+	if err != nil {
+		return fmt.Errorf("failed to get room: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	grant, err := videoGrant(roomName, identity, role, record.Settings, false)
+	if err != nil {
+		return err
+	}
+	if guest {
+		restrictGuest(grant)
+	}
+
+	info, err := client.GetParticipant(ctx, &livekit.RoomParticipantIdentity{
+		Room:     roomName,
+		Identity: identity,
+	})
+	if err != nil {
+		err = translateError(err, "failed to get participant %s", identity)
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	permission := &livekit.ParticipantPermission{}
+	if info.GetPermission() != nil {
+		permission = proto.Clone(info.GetPermission()).(*livekit.ParticipantPermission)
+	}
+	permission.CanPublish = grant.GetCanPublish()
+	permission.CanPublishSources = grant.GetCanPublishSources()
+
+	_, err = client.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
+		Room:       roomName,
+		Identity:   identity,
+		Permission: permission,
+	})
+	if err != nil {
+		return translateError(err, "failed to update permissions of %s", identity)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+
+	"open-meet/pkg/store/storetest"
+)
+
+const (
+	shareRoom = "standup"
+	shareHost = "host@example.com"
+)
+
+// newShareHost returns a host store for a room owned by shareHost with identities connected
+func newShareHost(t *testing.T, settings RoomSettings, identities ...string) (*host, *storetest.RoomService) {
+	t.Helper()
+	ctx := context.Background()
+	registry := NewMemoryRoomRegistry()
+	if err := registry.CreateRoom(ctx, shareRoom, shareHost); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.UpdateSettings(ctx, shareRoom, func(s *RoomSettings) { *s = settings }); err != nil {
+		t.Fatal(err)
+	}
+
+	server := storetest.NewRoomService()
+	for _, identity := range append(identities, shareHost) {
+		server.Join(shareRoom, identity)
+	}
+	return &host{client: server, registry: registry}, server
+}
+
+func TestScreenSharePolicyNeverSendsEmptySources(t *testing.T) {
+	ctx := context.Background()
+	h, server := newShareHost(t, RoomSettings{PublishSources: []string{"screen_share"}}, "ada@example.com")
+
+	if err := h.SetScreenSharePolicy(ctx, shareRoom, shareHost, ScreenShareHosts); err != nil {
+		t.Fatalf("SetScreenSharePolicy: %v", err)
+	}
+	// Screen sharing was all the room allowed, so nothing is left to publish
+	permission := server.Permission(shareRoom, "ada@example.com")
+	if permission.GetCanPublish() {
+		t.Errorf("participant may still publish %v", permission.GetCanPublishSources())
+	}
+
+	if err := h.SetScreenSharePolicy(ctx, shareRoom, shareHost, ScreenShareAnyone); err != nil {
+		t.Fatalf("SetScreenSharePolicy: %v", err)
+	}
+	permission = server.Permission(shareRoom, "ada@example.com")
+	if !permission.GetCanPublish() || len(permission.GetCanPublishSources()) != 1 {
+		t.Errorf("permission = %v, want screen sharing only", permission)
+	}
+}
+
+func TestScreenSharePolicyKeepsGuestsToCameraAndMicrophone(t *testing.T) {
+	ctx := context.Background()
+	guest := guestIdentityPrefix + "ada"
+	h, server := newShareHost(t, RoomSettings{Guests: GuestsAllowed, ScreenShare: ScreenShareHosts}, guest)
+
+	for _, change := range []func() error{
+		func() error { return h.SetScreenSharePolicy(ctx, shareRoom, shareHost, ScreenShareAnyone) },
+		func() error { return h.SetScreenSharePolicy(ctx, shareRoom, shareHost, ScreenShareApproval) },
+		func() error { return h.GrantPresenter(ctx, shareRoom, shareHost, guest) },
+	} {
+		if err := change(); err != nil {
+			t.Fatal(err)
+		}
+		permission := server.Permission(shareRoom, guest)
+		if !permission.GetCanPublish() {
+			t.Fatal("guest lost their camera and microphone")
+		}
+		want := []livekit.TrackSource{livekit.TrackSource_CAMERA, livekit.TrackSource_MICROPHONE}
+		if got := permission.GetCanPublishSources(); !slices.Equal(got, want) {
+			t.Errorf("guest may publish %v, want %v", got, want)
+		}
+	}
+}
+
+func TestGrantPresenterNeedsPresenterPolicy(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		policy ScreenSharePolicy
+		want   error
+	}{
+		{policy: ScreenShareAnyone, want: ErrConflict},
+		{policy: ScreenShareHosts, want: ErrConflict},
+		{policy: ScreenShareOnePresenter, want: nil},
+		{policy: ScreenShareApproval, want: nil},
+	} {
+		h, _ := newShareHost(t, RoomSettings{ScreenShare: tt.policy}, "ada@example.com")
+		if err := h.GrantPresenter(ctx, shareRoom, shareHost, "ada@example.com"); !errors.Is(err, tt.want) {
+			t.Errorf("GrantPresenter under %s: err = %v, want %v", tt.policy, err, tt.want)
+		}
+
+		record, _, err := h.registry.GetRoom(ctx, shareRoom)
+		if err != nil {
+			t.Fatalf("GetRoom: %v", err)
+		}
+		if granted := slices.Contains(record.Settings.Presenters, "ada@example.com"); granted != (tt.want == nil) {
+			t.Errorf("under %s: presenters = %v", tt.policy, record.Settings.Presenters)
+		}
+	}
+}
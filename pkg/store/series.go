@@ -0,0 +1,351 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+
+	"open-meet/pkg/calendar"
+)
+
+// Recurrence turns a meeting into a series. Every occurrence shares the meeting's code,
+// so the whole series meets in the same LiveKit room.
+type Recurrence struct {
+	Rule      string               `json:"rule"`              // RRULE value, e.g. "FREQ=WEEKLY;BYDAY=MO,WE"
+	ExDates   []time.Time          `json:"exdates,omitempty"` // original starts of cancelled occurrences
+	Overrides []OccurrenceOverride `json:"overrides,omitempty"`
+}
+
+// OccurrenceOverride changes a single occurrence of a series
+type OccurrenceOverride struct {
+	RecurrenceID time.Time `json:"recurrence_id"` // original start of the occurrence
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	StartAt      time.Time `json:"start_at"`
+	EndAt        time.Time `json:"end_at"`
+}
+
+// OccurrenceInput is what a host provides when changing one occurrence.
+// Empty fields keep the series' values.
+type OccurrenceInput struct {
+	Title       string
+	Description string
+	StartAt     time.Time
+	EndAt       time.Time
+}
+
+// Occurrence is one instance of a meeting
+type Occurrence struct {
+	Code         string    `json:"code"`
+	RecurrenceID time.Time `json:"recurrence_id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	StartAt      time.Time `json:"start_at"`
+	EndAt        time.Time `json:"end_at"`
+	Overridden   bool      `json:"overridden"`
+}
+
+// Occurrences returns up to limit occurrences of the meeting that have not ended by after.
+// A one-off meeting is its own single occurrence.
+func (m *Meeting) Occurrences(after time.Time, limit int) []Occurrence {
+	var list []Occurrence
+	for start := range m.starts() {
+		occurrence := m.occurrence(start)
+		if !occurrence.EndAt.After(after) {
+			continue
+		}
+		list = append(list, occurrence)
+		if len(list) >= limit {
+			break
+		}
+	}
+	// Overrides may move an occurrence past its neighbours
+	slices.SortFunc(list, func(a, b Occurrence) int {
+		return a.StartAt.Compare(b.StartAt)
+	})
+	return list
+}
+
+// starts yields the original start of every occurrence that was not cancelled
+func (m *Meeting) starts() iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		if m.Recurrence == nil {
+			yield(m.StartAt)
+			return
+		}
+		rule, loc, err := m.rule()
+		if err != nil {
+			return
+		}
+		for start := range rule.Occurrences(m.StartAt.In(loc)) {
+			start = start.UTC()
+			if slices.ContainsFunc(m.Recurrence.ExDates, start.Equal) {
+				continue
+			}
+			if !yield(start) {
+				return
+			}
+		}
+	}
+}
+
+// occurrence applies any override to the occurrence originally starting at start
+func (m *Meeting) occurrence(start time.Time) Occurrence {
+	occurrence := Occurrence{
+		Code:         m.Code,
+		RecurrenceID: start,
+		Title:        m.Title,
+		Description:  m.Description,
+		StartAt:      start,
+		EndAt:        start.Add(m.EndAt.Sub(m.StartAt)),
+	}
+	if m.Recurrence == nil {
+		return occurrence
+	}
+	if i := slices.IndexFunc(m.Recurrence.Overrides, func(o OccurrenceOverride) bool {
+		return o.RecurrenceID.Equal(start)
+	}); i >= 0 {
+		override := m.Recurrence.Overrides[i]
+		occurrence.Title = override.Title
+		occurrence.Description = override.Description
+		occurrence.StartAt = override.StartAt
+		occurrence.EndAt = override.EndAt
+		occurrence.Overridden = true
+	}
+	return occurrence
+}
+
+// isOccurrence reports whether an occurrence of the series originally starts at start
+func (m *Meeting) isOccurrence(start time.Time) bool {
+	for candidate := range m.starts() {
+		if candidate.Equal(start) {
+			return true
+		}
+		if candidate.After(start) {
+			return false
+		}
+	}
+	return false
+}
+
+// rule returns the series' recurrence rule and the location it is expanded in
+func (m *Meeting) rule() (calendar.Rule, *time.Location, error) {
+	rule, err := calendar.ParseRule(m.Recurrence.Rule)
+	if err != nil {
+		return rule, nil, err
+	}
+	loc, err := time.LoadLocation(m.Timezone)
+	if err != nil {
+		return rule, nil, err
+	}
+	return rule, loc, nil
+}
+
+// Occurrences returns up to limit upcoming occurrences of the meeting that have not ended by after
+func (m *meetings) Occurrences(ctx context.Context, code string, after time.Time, limit int) ([]Occurrence, error) {
+	meeting, err := m.Get(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if meeting.Status == MeetingCancelled {
+		return []Occurrence{}, nil
+	}
+	return meeting.Occurrences(after, limit), nil
+}
+
+// UpcomingOccurrence is an occurrence together with the meeting it belongs to
+type UpcomingOccurrence struct {
+	Occurrence
+	Meeting *Meeting
+}
+
+// Starting returns the occurrences of scheduled meetings starting in [from, to), soonest first
+func (m *meetings) Starting(ctx context.Context, from, to time.Time) ([]UpcomingOccurrence, error) {
+	list, err := m.registry.ListScheduledMeetings(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled meetings: %w", err)
+	}
+
+	var upcoming []UpcomingOccurrence
+	for i := range list {
+		meeting := &list[i]
+		for start := range meeting.starts() {
+			occurrence := meeting.occurrence(start)
+			// Overrides may move an occurrence into the window from anywhere, so only
+			// original starts past the window end the search
+			if !start.Before(to) && !occurrence.StartAt.Before(to) {
+				break
+			}
+			if !occurrence.StartAt.Before(from) && occurrence.StartAt.Before(to) {
+				upcoming = append(upcoming, UpcomingOccurrence{Occurrence: occurrence, Meeting: meeting})
+			}
+		}
+	}
+	slices.SortFunc(upcoming, func(a, b UpcomingOccurrence) int {
+		return a.StartAt.Compare(b.StartAt)
+	})
+	return upcoming, nil
+}
+
+// OverrideOccurrence changes a single occurrence of a series; only its host may
+func (m *meetings) OverrideOccurrence(ctx context.Context, code string, hostEmail string, recurrenceID time.Time, input OccurrenceInput) (*Meeting, error) {
+	meeting, err := m.seriesOccurrence(ctx, code, hostEmail, recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	recurrenceID = recurrenceID.UTC()
+	current := meeting.occurrence(recurrenceID)
+	override := OccurrenceOverride{
+		RecurrenceID: recurrenceID,
+		Title:        current.Title,
+		Description:  current.Description,
+		StartAt:      current.StartAt,
+		EndAt:        current.EndAt,
+	}
+	if input.Title != "" {
+		override.Title = input.Title
+	}
+	if input.Description != "" {
+		override.Description = input.Description
+	}
+	if !input.StartAt.IsZero() {
+		// Moving the start keeps the occurrence's length unless a new end is given too
+		override.EndAt = input.StartAt.Add(override.EndAt.Sub(override.StartAt)).UTC()
+		override.StartAt = input.StartAt.UTC()
+	}
+	if !input.EndAt.IsZero() {
+		override.EndAt = input.EndAt.UTC()
+	}
+	if !override.EndAt.After(override.StartAt) {
+		return nil, fmt.Errorf("%w: occurrence must end after it starts", ErrInvalid)
+	}
+
+	meeting.Recurrence.Overrides = slices.DeleteFunc(meeting.Recurrence.Overrides, func(o OccurrenceOverride) bool {
+		return o.RecurrenceID.Equal(recurrenceID)
+	})
+	meeting.Recurrence.Overrides = append(meeting.Recurrence.Overrides, override)
+	return m.saveSeries(ctx, meeting)
+}
+
+// CancelOccurrence calls off a single occurrence of a series; only its host may
+func (m *meetings) CancelOccurrence(ctx context.Context, code string, hostEmail string, recurrenceID time.Time) (*Meeting, error) {
+	meeting, err := m.seriesOccurrence(ctx, code, hostEmail, recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	recurrenceID = recurrenceID.UTC()
+	meeting.Recurrence.Overrides = slices.DeleteFunc(meeting.Recurrence.Overrides, func(o OccurrenceOverride) bool {
+		return o.RecurrenceID.Equal(recurrenceID)
+	})
+	meeting.Recurrence.ExDates = append(meeting.Recurrence.ExDates, recurrenceID)
+	slices.SortFunc(meeting.Recurrence.ExDates, time.Time.Compare)
+	return m.saveSeries(ctx, meeting)
+}
+
+// seriesOccurrence returns the series hosted by hostEmail if recurrenceID is one of its occurrences
+func (m *meetings) seriesOccurrence(ctx context.Context, code, hostEmail string, recurrenceID time.Time) (*Meeting, error) {
+	meeting, err := m.hostedMeeting(ctx, code, hostEmail)
+	if err != nil {
+		return nil, err
+	}
+	if meeting.Status == MeetingCancelled {
+		return nil, fmt.Errorf("%w: meeting %s is cancelled", ErrConflict, code)
+	}
+	if meeting.Recurrence == nil {
+		return nil, fmt.Errorf("%w: meeting %s does not recur", ErrInvalid, code)
+	}
+	if !meeting.isOccurrence(recurrenceID.UTC()) {
+		return nil, fmt.Errorf("%w: meeting %s has no occurrence at %s", ErrNotFound, code, recurrenceID.UTC().Format(time.RFC3339))
+	}
+	return meeting, nil
+}
+
+func (m *meetings) saveSeries(ctx context.Context, meeting *Meeting) (*Meeting, error) {
+	meeting.Sequence++
+	meeting.UpdatedAt = time.Now().UTC()
+	if err := m.registry.SaveMeeting(ctx, meeting); err != nil {
+		return nil, fmt.Errorf("failed to save meeting: %w", err)
+	}
+	return meeting, nil
+}
+
+// normalizeRecurrence validates the input's recurrence rule and moves the input's
+// start to the first occurrence, so the meeting's own times always describe one
+func normalizeRecurrence(input *MeetingInput) error {
+	if input.Recurrence == "" {
+		if len(input.ExDates) > 0 {
+			return fmt.Errorf("%w: exception dates require a recurrence rule", ErrInvalid)
+		}
+		return nil
+	}
+
+	rule, err := calendar.ParseRule(input.Recurrence)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	input.Recurrence = rule.String()
+
+	loc, err := time.LoadLocation(input.Timezone)
+	if err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalid, input.Timezone)
+	}
+	duration := input.EndAt.Sub(input.StartAt)
+	found := false
+	for first := range rule.Occurrences(input.StartAt.In(loc)) {
+		input.StartAt, input.EndAt = first, first.Add(duration)
+		found = true
+		break
+	}
+	if !found {
+		return fmt.Errorf("%w: recurrence rule has no occurrences", ErrInvalid)
+	}
+
+	exdates := make([]time.Time, 0, len(input.ExDates))
+	for _, exdate := range input.ExDates {
+		exdate = exdate.UTC()
+		if !slices.ContainsFunc(exdates, exdate.Equal) {
+			exdates = append(exdates, exdate)
+		}
+	}
+	slices.SortFunc(exdates, time.Time.Compare)
+	input.ExDates = exdates
+	return nil
+}
+
+// applyRecurrence sets the meeting's recurrence from the input, keeping the
+// overrides of occurrences that are still part of the series
+func applyRecurrence(meeting *Meeting, input MeetingInput) {
+	if input.Recurrence == "" {
+		meeting.Recurrence = nil
+		return
+	}
+
+	var overrides []OccurrenceOverride
+	if meeting.Recurrence != nil {
+		overrides = meeting.Recurrence.Overrides
+	}
+	meeting.Recurrence = &Recurrence{
+		Rule:    input.Recurrence,
+		ExDates: input.ExDates,
+	}
+	for _, override := range overrides {
+		if meeting.isOccurrence(override.RecurrenceID) {
+			meeting.Recurrence.Overrides = append(meeting.Recurrence.Overrides, override)
+		}
+	}
+}
+
+func cloneRecurrence(recurrence *Recurrence) *Recurrence {
+	if recurrence == nil {
+		return nil
+	}
+	return &Recurrence{
+		Rule:      recurrence.Rule,
+		ExDates:   slices.Clone(recurrence.ExDates),
+		Overrides: slices.Clone(recurrence.Overrides),
+	}
+}
@@ -0,0 +1,348 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"open-meet/pkg/auth"
+)
+
+const (
+	// SessionIssuer is the iss and aud of access tokens the service signs for itself
+	SessionIssuer = "open-meet"
+
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Identity is who a session belongs to, as vouched for by the identity provider at sign-in
+type Identity = auth.Identity
+
+// Session is one sign-in. Refreshing keeps the session alive; revoking it ends
+// every token issued for it.
+type Session struct {
+	ID string
+	Identity
+	CreatedAt time.Time
+	ExpiresAt time.Time // when the last refresh token issued runs out
+	RevokedAt time.Time // zero while the session is active
+}
+
+// Active reports whether the session can still be used at now
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt.IsZero() && now.Before(s.ExpiresAt)
+}
+
+// TokenPair is what a client gets on sign-in and on every refresh
+type TokenPair struct {
+	Identity
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// SessionClaims are the claims of an access token
+type SessionClaims struct {
+	Identity
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// SessionRegistry persists sessions and their refresh tokens. Only token hashes are stored.
+type SessionRegistry interface {
+	CreateSession(ctx context.Context, session *Session, refreshHash string) error
+	GetSession(ctx context.Context, id string) (*Session, bool, error)
+	// RotateRefreshToken marks the refresh token used and stores its successor, extending
+	// the session to expiresAt. It fails with ErrNotFound for an unknown token and with
+	// ErrConflict for one that was already used.
+	RotateRefreshToken(ctx context.Context, oldHash, newHash string, now, expiresAt time.Time) (*Session, error)
+	// SessionOfRefreshToken returns the session a refresh token, used or not, was issued for
+	SessionOfRefreshToken(ctx context.Context, hash string) (*Session, bool, error)
+	RevokeSession(ctx context.Context, id string, now time.Time) error
+}
+
+// Sessions issues and checks the service's own tokens
+type Sessions interface {
+	// Start signs identity in and returns its first tokens
+	Start(ctx context.Context, identity Identity) (*TokenPair, error)
+	// Refresh trades a refresh token for new tokens. Every refresh token works once;
+	// presenting one again revokes the whole session, as it must have been stolen.
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	// Revoke ends the session a refresh token belongs to
+	Revoke(ctx context.Context, refreshToken string) error
+	// End ends a session by ID
+	End(ctx context.Context, sessionID string) error
+	// Authenticate verifies an access token and that its session is still active
+	Authenticate(ctx context.Context, accessToken string) (*SessionClaims, error)
+}
+
+// sessions implements Sessions interface
+type sessions struct {
+	registry   SessionRegistry
+	key        []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewSessions creates a token issuer signing access tokens with key
+func NewSessions(registry SessionRegistry, key []byte, accessTTL, refreshTTL time.Duration) *sessions {
+	if accessTTL <= 0 {
+		accessTTL = DefaultAccessTokenTTL
+	}
+	if refreshTTL <= 0 {
+		refreshTTL = DefaultRefreshTokenTTL
+	}
+	return &sessions{
+		registry:   registry,
+		key:        key,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// Start creates a session for identity
+func (s *sessions) Start(ctx context.Context, identity Identity) (*TokenPair, error) {
+	if identity.Email == "" {
+		return nil, fmt.Errorf("%w: identity has no email", ErrInvalid)
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := &Session{
+		ID:        uuid.NewString(),
+		Identity:  identity,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.refreshTTL),
+	}
+	if err := s.registry.CreateSession(ctx, session, hashToken(refreshToken)); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return s.tokens(session, refreshToken, now)
+}
+
+// Refresh rotates the refresh token
+func (s *sessions) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	next, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session, err := s.registry.RotateRefreshToken(ctx, hashToken(refreshToken), hashToken(next), now, now.Add(s.refreshTTL))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil, fmt.Errorf("%w: unknown refresh token", ErrUnauthorized)
+	case errors.Is(err, ErrConflict):
+		// Reuse of a rotated token: whoever holds the session's tokens is not to be trusted
+		if reused, found, lookupErr := s.registry.SessionOfRefreshToken(ctx, hashToken(refreshToken)); lookupErr == nil && found {
+			if err := s.registry.RevokeSession(ctx, reused.ID, now); err != nil {
+				return nil, fmt.Errorf("failed to revoke session: %w", err)
+			}
+		}
+		return nil, fmt.Errorf("%w: refresh token was already used, session revoked", ErrUnauthorized)
+	case err != nil:
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	if !session.RevokedAt.IsZero() {
+		return nil, fmt.Errorf("%w: session was revoked", ErrUnauthorized)
+	}
+	return s.tokens(session, next, now)
+}
+
+// Revoke ends the session of a refresh token; unknown tokens are ignored
+func (s *sessions) Revoke(ctx context.Context, refreshToken string) error {
+	session, found, err := s.registry.SessionOfRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if !found {
+		return nil
+	}
+	return s.End(ctx, session.ID)
+}
+
+// End revokes the session
+func (s *sessions) End(ctx context.Context, sessionID string) error {
+	if err := s.registry.RevokeSession(ctx, sessionID, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// Authenticate verifies an access token
+func (s *sessions) Authenticate(ctx context.Context, accessToken string) (*SessionClaims, error) {
+	claims := &SessionClaims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, func(*jwt.Token) (any, error) {
+		return s.key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(SessionIssuer),
+		jwt.WithAudience(SessionIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	session, found, err := s.registry.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if !found || !session.Active(time.Now()) {
+		return nil, fmt.Errorf("%w: session has ended", ErrUnauthorized)
+	}
+	return claims, nil
+}
+
+// tokens signs an access token for session and pairs it with refreshToken
+func (s *sessions) tokens(session *Session, refreshToken string, now time.Time) (*TokenPair, error) {
+	expiresAt := now.Add(s.accessTTL)
+	claims := &SessionClaims{
+		Identity:  session.Identity,
+		SessionID: session.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    SessionIssuer,
+			Subject:   session.Email,
+			Audience:  jwt.ClaimStrings{SessionIssuer},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        uuid.NewString(),
+		},
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return &TokenPair{
+		Identity:         session.Identity,
+		AccessToken:      accessToken,
+		AccessExpiresAt:  expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func newRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// refreshTokenRecord is a refresh token as the memory registry keeps it
+type refreshTokenRecord struct {
+	sessionID string
+	expiresAt time.Time
+	used      bool
+}
+
+// memorySessionRegistry implements SessionRegistry in process memory
+type memorySessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*Session            // map[id]session
+	tokens   map[string]*refreshTokenRecord // map[tokenHash]record
+}
+
+// NewMemorySessionRegistry creates an empty in-memory session registry
+func NewMemorySessionRegistry() *memorySessionRegistry {
+	return &memorySessionRegistry{
+		sessions: make(map[string]*Session),
+		tokens:   make(map[string]*refreshTokenRecord),
+	}
+}
+
+// CreateSession stores the session and its first refresh token
+func (r *memorySessionRegistry) CreateSession(ctx context.Context, session *Session, refreshHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *session
+	r.sessions[session.ID] = &cp
+	r.tokens[refreshHash] = &refreshTokenRecord{sessionID: session.ID, expiresAt: session.ExpiresAt}
+	return nil
+}
+
+// GetSession returns the session with the given ID
+func (r *memorySessionRegistry) GetSession(ctx context.Context, id string) (*Session, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, exists := r.sessions[id]
+	if !exists {
+		return nil, false, nil
+	}
+	cp := *session
+	return &cp, true, nil
+}
+
+// RotateRefreshToken replaces a refresh token with its successor
+func (r *memorySessionRegistry) RotateRefreshToken(ctx context.Context, oldHash, newHash string, now, expiresAt time.Time) (*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, exists := r.tokens[oldHash]
+	if !exists || !now.Before(record.expiresAt) {
+		return nil, ErrNotFound
+	}
+	if record.used {
+		return nil, ErrConflict
+	}
+	session, exists := r.sessions[record.sessionID]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	record.used = true
+	r.tokens[newHash] = &refreshTokenRecord{sessionID: session.ID, expiresAt: expiresAt}
+	session.ExpiresAt = expiresAt
+	cp := *session
+	return &cp, nil
+}
+
+// SessionOfRefreshToken returns the session a refresh token was issued for
+func (r *memorySessionRegistry) SessionOfRefreshToken(ctx context.Context, hash string) (*Session, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, exists := r.tokens[hash]
+	if !exists {
+		return nil, false, nil
+	}
+	session, exists := r.sessions[record.sessionID]
+	if !exists {
+		return nil, false, nil
+	}
+	cp := *session
+	return &cp, true, nil
+}
+
+// RevokeSession ends the session and drops its refresh tokens
+func (r *memorySessionRegistry) RevokeSession(ctx context.Context, id string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, exists := r.sessions[id]
+	if !exists {
+		return nil
+	}
+	if session.RevokedAt.IsZero() {
+		session.RevokedAt = now
+	}
+	maps.DeleteFunc(r.tokens, func(_ string, record *refreshTokenRecord) bool {
+		return record.sessionID == id
+	})
+	return nil
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+// eachSessions runs test against Sessions backed by each SessionRegistry
+func eachSessions(t *testing.T, test func(t *testing.T, s Sessions)) {
+	eachRegistry(t,
+		func() SessionRegistry { return NewMemorySessionRegistry() },
+		func(db *sql.DB) SessionRegistry { return NewSQLSessionRegistry(db) },
+		func(t *testing.T, registry SessionRegistry) {
+			test(t, NewSessions(registry, []byte("test signing key"), time.Minute, time.Hour))
+		})
+}
+
+func startSession(t *testing.T, s Sessions) *TokenPair {
+	t.Helper()
+	tokens, err := s.Start(context.Background(), Identity{Email: "ada@example.com", Name: "Ada"})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	return tokens
+}
+
+func TestSessionRefreshRotatesToken(t *testing.T) {
+	eachSessions(t, func(t *testing.T, s Sessions) {
+		ctx := context.Background()
+		first := startSession(t, s)
+
+		second, err := s.Refresh(ctx, first.RefreshToken)
+		if err != nil {
+			t.Fatalf("Refresh: %v", err)
+		}
+		if second.RefreshToken == first.RefreshToken {
+			t.Error("refresh returned the same refresh token")
+		}
+		if second.Email != "ada@example.com" {
+			t.Errorf("refreshed identity = %q, want ada@example.com", second.Email)
+		}
+
+		claims, err := s.Authenticate(ctx, second.AccessToken)
+		if err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+		if claims.Email != "ada@example.com" {
+			t.Errorf("access token email = %q, want ada@example.com", claims.Email)
+		}
+
+		if _, err := s.Refresh(ctx, second.RefreshToken); err != nil {
+			t.Errorf("refreshing with the new token: %v", err)
+		}
+	})
+}
+
+func TestSessionRefreshTokenReuseRevokesSession(t *testing.T) {
+	eachSessions(t, func(t *testing.T, s Sessions) {
+		ctx := context.Background()
+		first := startSession(t, s)
+		second, err := s.Refresh(ctx, first.RefreshToken)
+		if err != nil {
+			t.Fatalf("Refresh: %v", err)
+		}
+
+		// Presenting the rotated token again means it leaked
+		if _, err := s.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("reusing a refresh token: err = %v, want ErrUnauthorized", err)
+		}
+
+		if _, err := s.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("refreshing a revoked session: err = %v, want ErrUnauthorized", err)
+		}
+		if _, err := s.Authenticate(ctx, second.AccessToken); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("authenticating in a revoked session: err = %v, want ErrUnauthorized", err)
+		}
+	})
+}
+
+func TestSessionRevoke(t *testing.T) {
+	eachSessions(t, func(t *testing.T, s Sessions) {
+		ctx := context.Background()
+		tokens := startSession(t, s)
+
+		if err := s.Revoke(ctx, tokens.RefreshToken); err != nil {
+			t.Fatalf("Revoke: %v", err)
+		}
+		if _, err := s.Authenticate(ctx, tokens.AccessToken); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Authenticate after Revoke: err = %v, want ErrUnauthorized", err)
+		}
+		if _, err := s.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Refresh after Revoke: err = %v, want ErrUnauthorized", err)
+		}
+		if err := s.Revoke(ctx, "unknown"); err != nil {
+			t.Errorf("revoking an unknown token: %v", err)
+		}
+	})
+}
+
+func TestSessionRejectsForeignTokens(t *testing.T) {
+	ctx := context.Background()
+	s := NewSessions(NewMemorySessionRegistry(), []byte("test signing key"), time.Minute, time.Hour)
+	other := NewSessions(NewMemorySessionRegistry(), []byte("another key"), time.Minute, time.Hour)
+	tokens := startSession(t, other)
+
+	if _, err := s.Authenticate(ctx, tokens.AccessToken); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("access token signed with another key: err = %v, want ErrUnauthorized", err)
+	}
+	if _, err := s.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("unknown refresh token: err = %v, want ErrUnauthorized", err)
+	}
+	if _, err := s.Start(ctx, Identity{}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("identity without email: err = %v, want ErrInvalid", err)
+	}
+}
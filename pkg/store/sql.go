@@ -0,0 +1,1334 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"time"
+
+	// Registers the "pgx" database/sql driver for PostgreSQL
+	_ "github.com/jackc/pgx/v5/stdlib"
+	// Registers the pure-Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// sqlDrivers maps configured store drivers onto registered database/sql driver names
+var sqlDrivers = map[string]string{
+	"sqlite":   "sqlite",
+	"postgres": "pgx",
+}
+
+// maxSettingsRetries bounds the compare-and-swap loop in UpdateSettings
+const maxSettingsRetries = 5
+
+// OpenDatabase opens a SQL database and applies any pending migrations.
+// The schema sticks to portable SQL so it runs on SQLite and PostgreSQL alike.
+func OpenDatabase(ctx context.Context, driver, dsn string) (*sql.DB, error) {
+	driverName, ok := sqlDrivers[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported store driver %q", driver)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %w", driver, err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to %s database: %w", driver, err)
+	}
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// migrate applies the embedded migrations that have not been recorded yet, in file name order
+func migrate(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var applied int
+		err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = $1`, name).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", name, err)
+		}
+		if applied > 0 {
+			continue
+		}
+
+		script, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %s: %w", name, err)
+		}
+		if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %s: %w", name, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)`, name, time.Now().UTC()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %s: %w", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// sqlRoomRegistry implements RoomRegistry on top of a SQL database
+type sqlRoomRegistry struct {
+	db *sql.DB
+}
+
+// NewSQLRoomRegistry creates a room registry persisted in db
+func NewSQLRoomRegistry(db *sql.DB) *sqlRoomRegistry {
+	return &sqlRoomRegistry{db: db}
+}
+
+// CreateRoom records a room with its creator as the initial host. Creating a room that is
+// already recorded, like a scheduled meeting's room opened again, keeps its settings.
+func (r *sqlRoomRegistry) CreateRoom(ctx context.Context, roomName, creatorEmail string) error {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO rooms (name, creator_email, settings, created_at) VALUES ($1, $2, '{}', $3)
+		ON CONFLICT (name) DO UPDATE SET creator_email = excluded.creator_email, created_at = excluded.created_at`,
+		roomName, creatorEmail, now)
+	if err != nil {
+		return fmt.Errorf("failed to record room %s: %w", roomName, err)
+	}
+
+	if err := upsertHost(ctx, tx, roomName, creatorEmail, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetRoom returns the room record together with its current host
+func (r *sqlRoomRegistry) GetRoom(ctx context.Context, roomName string) (*RoomRecord, bool, error) {
+	var (
+		record   RoomRecord
+		settings string
+	)
+	err := r.db.QueryRowContext(ctx, `SELECT r.name, r.creator_email, r.settings, r.created_at, COALESCE(h.host_email, '')
+		FROM rooms r LEFT JOIN room_hosts h ON h.room_name = r.name
+		WHERE r.name = $1`, roomName).
+		Scan(&record.Name, &record.CreatorEmail, &settings, &record.CreatedAt, &record.HostEmail)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get room %s: %w", roomName, err)
+	}
+
+	if err := json.Unmarshal([]byte(settings), &record.Settings); err != nil {
+		return nil, false, fmt.Errorf("failed to decode settings of room %s: %w", roomName, err)
+	}
+
+	return &record, true, nil
+}
+
+// DeleteRoom forgets the room, its host and who was present
+func (r *sqlRoomRegistry) DeleteRoom(ctx context.Context, roomName string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM room_presence WHERE room_name = $1`, roomName); err != nil {
+		return fmt.Errorf("failed to delete presence of room %s: %w", roomName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM room_roles WHERE room_name = $1`, roomName); err != nil {
+		return fmt.Errorf("failed to delete roles of room %s: %w", roomName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM room_admissions WHERE room_name = $1`, roomName); err != nil {
+		return fmt.Errorf("failed to delete admissions of room %s: %w", roomName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM participant_states WHERE room_name = $1`, roomName); err != nil {
+		return fmt.Errorf("failed to delete participant states of room %s: %w", roomName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM room_hosts WHERE room_name = $1`, roomName); err != nil {
+		return fmt.Errorf("failed to delete host of room %s: %w", roomName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM rooms WHERE name = $1`, roomName); err != nil {
+		return fmt.Errorf("failed to delete room %s: %w", roomName, err)
+	}
+
+	return tx.Commit()
+}
+
+// SetHost records hostEmail as the host of the room
+func (r *sqlRoomRegistry) SetHost(ctx context.Context, roomName, hostEmail string) error {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Rooms created before the registry knew about them are adopted by their first host
+	_, err = tx.ExecContext(ctx, `INSERT INTO rooms (name, creator_email, settings, created_at) VALUES ($1, $2, '{}', $3)
+		ON CONFLICT (name) DO NOTHING`, roomName, hostEmail, now)
+	if err != nil {
+		return fmt.Errorf("failed to record room %s: %w", roomName, err)
+	}
+
+	if err := upsertHost(ctx, tx, roomName, hostEmail, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetRoomHost returns the host email for a room
+func (r *sqlRoomRegistry) GetRoomHost(ctx context.Context, roomName string) (string, bool, error) {
+	var host string
+	err := r.db.QueryRowContext(ctx, `SELECT host_email FROM room_hosts WHERE room_name = $1`, roomName).Scan(&host)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get host of room %s: %w", roomName, err)
+	}
+	return host, true, nil
+}
+
+// IsHost checks if the given email is the host of the room
+func (r *sqlRoomRegistry) IsHost(ctx context.Context, roomName, email string) (bool, error) {
+	host, exists, err := r.GetRoomHost(ctx, roomName)
+	if err != nil {
+		return false, err
+	}
+	return exists && host == email, nil
+}
+
+// ClearHost leaves the room without a host
+func (r *sqlRoomRegistry) ClearHost(ctx context.Context, roomName string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM room_hosts WHERE room_name = $1`, roomName); err != nil {
+		return fmt.Errorf("failed to clear host of room %s: %w", roomName, err)
+	}
+	return nil
+}
+
+// SetRole grants a co-host or moderator role in the room
+func (r *sqlRoomRegistry) SetRole(ctx context.Context, roomName, email string, role Role) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO room_roles (room_name, email, role, granted_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (room_name, email) DO UPDATE SET role = excluded.role, granted_at = excluded.granted_at`,
+		roomName, email, string(role), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to set role of %s in room %s: %w", email, roomName, err)
+	}
+	return nil
+}
+
+// RemoveRole returns the user to the participant role
+func (r *sqlRoomRegistry) RemoveRole(ctx context.Context, roomName, email string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM room_roles WHERE room_name = $1 AND email = $2`, roomName, email)
+	if err != nil {
+		return fmt.Errorf("failed to remove role of %s in room %s: %w", email, roomName, err)
+	}
+	return nil
+}
+
+// GetRole returns the user's role in the room; the host is always the owner
+func (r *sqlRoomRegistry) GetRole(ctx context.Context, roomName, email string) (Role, error) {
+	isHost, err := r.IsHost(ctx, roomName, email)
+	if err != nil {
+		return "", err
+	}
+	if isHost {
+		return RoleOwner, nil
+	}
+
+	var role string
+	err = r.db.QueryRowContext(ctx, `SELECT role FROM room_roles WHERE room_name = $1 AND email = $2`, roomName, email).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return RoleParticipant, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get role of %s in room %s: %w", email, roomName, err)
+	}
+	return Role(role), nil
+}
+
+// ListRoles returns every user holding a role above participant, including the owner
+func (r *sqlRoomRegistry) ListRoles(ctx context.Context, roomName string) (map[string]Role, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT email, role FROM room_roles WHERE room_name = $1`, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles of room %s: %w", roomName, err)
+	}
+	defer rows.Close()
+
+	roles := make(map[string]Role)
+	for rows.Next() {
+		var email, role string
+		if err := rows.Scan(&email, &role); err != nil {
+			return nil, fmt.Errorf("failed to scan role of room %s: %w", roomName, err)
+		}
+		roles[email] = Role(role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	host, found, err := r.GetRoomHost(ctx, roomName)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		roles[host] = RoleOwner
+	}
+	return roles, nil
+}
+
+// UpdateSettings applies update to the room's settings.
+// The write only succeeds if nobody changed the settings in between; otherwise it is retried.
+func (r *sqlRoomRegistry) UpdateSettings(ctx context.Context, roomName string, update func(*RoomSettings)) error {
+	for attempt := 0; attempt < maxSettingsRetries; attempt++ {
+		var current string
+		err := r.db.QueryRowContext(ctx, `SELECT settings FROM rooms WHERE name = $1`, roomName).Scan(&current)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: room %s is not registered", ErrNotFound, roomName)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get settings of room %s: %w", roomName, err)
+		}
+
+		var settings RoomSettings
+		if err := json.Unmarshal([]byte(current), &settings); err != nil {
+			return fmt.Errorf("failed to decode settings of room %s: %w", roomName, err)
+		}
+		update(&settings)
+
+		encoded, err := json.Marshal(settings)
+		if err != nil {
+			return fmt.Errorf("failed to encode settings of room %s: %w", roomName, err)
+		}
+
+		res, err := r.db.ExecContext(ctx, `UPDATE rooms SET settings = $1 WHERE name = $2 AND settings = $3`,
+			string(encoded), roomName, current)
+		if err != nil {
+			return fmt.Errorf("failed to update settings of room %s: %w", roomName, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: settings of room %s changed concurrently", ErrConflict, roomName)
+}
+
+// RecordJoin marks the participant as present since joinedAt, starting them with a fresh state
+func (r *sqlRoomRegistry) RecordJoin(ctx context.Context, roomName, identity string, joinedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO room_presence (room_name, identity, joined_at, left_at) VALUES ($1, $2, $3, NULL)
+		ON CONFLICT (room_name, identity) DO UPDATE SET joined_at = excluded.joined_at, left_at = NULL`,
+		roomName, identity, joinedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record join of %s in room %s: %w", identity, roomName, err)
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM participant_states WHERE room_name = $1 AND identity = $2`, roomName, identity)
+	if err != nil {
+		return fmt.Errorf("failed to reset state of %s in room %s: %w", identity, roomName, err)
+	}
+
+	return tx.Commit()
+}
+
+// RecordLeave marks the participant as gone since leftAt
+func (r *sqlRoomRegistry) RecordLeave(ctx context.Context, roomName, identity string, leftAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE room_presence SET left_at = $1 WHERE room_name = $2 AND identity = $3`,
+		leftAt.UTC(), roomName, identity)
+	if err != nil {
+		return fmt.Errorf("failed to record leave of %s from room %s: %w", identity, roomName, err)
+	}
+	return nil
+}
+
+// ListPresence returns the room's presence records, earliest join first
+func (r *sqlRoomRegistry) ListPresence(ctx context.Context, roomName string) ([]PresenceRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT room_name, identity, joined_at, left_at FROM room_presence
+		WHERE room_name = $1 ORDER BY joined_at`, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list presence of room %s: %w", roomName, err)
+	}
+	defer rows.Close()
+
+	var records []PresenceRecord
+	for rows.Next() {
+		var (
+			record PresenceRecord
+			leftAt sql.NullTime
+		)
+		if err := rows.Scan(&record.RoomName, &record.Identity, &record.JoinedAt, &leftAt); err != nil {
+			return nil, fmt.Errorf("failed to scan presence of room %s: %w", roomName, err)
+		}
+		record.LeftAt = leftAt.Time
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// ClearPresence forgets who was in the room and their participant states
+func (r *sqlRoomRegistry) ClearPresence(ctx context.Context, roomName string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM room_presence WHERE room_name = $1`, roomName); err != nil {
+		return fmt.Errorf("failed to clear presence of room %s: %w", roomName, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM participant_states WHERE room_name = $1`, roomName); err != nil {
+		return fmt.Errorf("failed to clear participant states of room %s: %w", roomName, err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateParticipantState applies update to the participant's state and returns the result.
+// Like UpdateSettings, the write only succeeds if nobody changed the state in between.
+func (r *sqlRoomRegistry) UpdateParticipantState(ctx context.Context, roomName, identity string, update func(*ParticipantState)) (*ParticipantState, error) {
+	for attempt := 0; attempt < maxSettingsRetries; attempt++ {
+		var current string
+		err := r.db.QueryRowContext(ctx, `SELECT state FROM participant_states WHERE room_name = $1 AND identity = $2`,
+			roomName, identity).Scan(&current)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get state of %s in room %s: %w", identity, roomName, err)
+		}
+
+		var state ParticipantState
+		if current != "" {
+			if err := json.Unmarshal([]byte(current), &state); err != nil {
+				return nil, fmt.Errorf("failed to decode state of %s in room %s: %w", identity, roomName, err)
+			}
+		}
+		update(&state)
+
+		encoded, err := json.Marshal(state)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode state of %s in room %s: %w", identity, roomName, err)
+		}
+
+		var res sql.Result
+		if current == "" {
+			res, err = r.db.ExecContext(ctx, `INSERT INTO participant_states (room_name, identity, state) VALUES ($1, $2, $3)
+				ON CONFLICT (room_name, identity) DO NOTHING`, roomName, identity, string(encoded))
+		} else {
+			res, err = r.db.ExecContext(ctx, `UPDATE participant_states SET state = $1 WHERE room_name = $2 AND identity = $3 AND state = $4`,
+				string(encoded), roomName, identity, current)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update state of %s in room %s: %w", identity, roomName, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			return &state, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: state of %s in room %s changed concurrently", ErrConflict, identity, roomName)
+}
+
+// GetParticipantState returns the participant's state
+func (r *sqlRoomRegistry) GetParticipantState(ctx context.Context, roomName, identity string) (*ParticipantState, bool, error) {
+	var encoded string
+	err := r.db.QueryRowContext(ctx, `SELECT state FROM participant_states WHERE room_name = $1 AND identity = $2`,
+		roomName, identity).Scan(&encoded)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get state of %s in room %s: %w", identity, roomName, err)
+	}
+
+	var state ParticipantState
+	if err := json.Unmarshal([]byte(encoded), &state); err != nil {
+		return nil, false, fmt.Errorf("failed to decode state of %s in room %s: %w", identity, roomName, err)
+	}
+	return &state, true, nil
+}
+
+// RequestAdmission queues email for the waiting room unless a decision already exists, and returns its status
+func (r *sqlRoomRegistry) RequestAdmission(ctx context.Context, roomName, email, displayName string) (AdmissionStatus, error) {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO room_admissions (room_name, email, display_name, status, requested_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (room_name, email) DO NOTHING`, roomName, email, displayName, string(AdmissionPending), time.Now().UTC())
+	if err != nil {
+		return "", fmt.Errorf("failed to queue %s for room %s: %w", email, roomName, err)
+	}
+
+	var status string
+	err = r.db.QueryRowContext(ctx, `SELECT status FROM room_admissions WHERE room_name = $1 AND email = $2`, roomName, email).Scan(&status)
+	if err != nil {
+		return "", fmt.Errorf("failed to get admission of %s to room %s: %w", email, roomName, err)
+	}
+	return AdmissionStatus(status), nil
+}
+
+// DecideAdmission records a host's decision on a waiting-room request
+func (r *sqlRoomRegistry) DecideAdmission(ctx context.Context, roomName, email string, status AdmissionStatus, decidedBy string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE room_admissions SET status = $1, decided_at = $2, decided_by = $3
+		WHERE room_name = $4 AND email = $5`, string(status), time.Now().UTC(), decidedBy, roomName, email)
+	if err != nil {
+		return fmt.Errorf("failed to decide admission of %s to room %s: %w", email, roomName, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s is not waiting to join room %s", ErrNotFound, email, roomName)
+	}
+	return nil
+}
+
+// ListAdmissions returns waiting-room requests with the given status, or all when status is empty, oldest first
+func (r *sqlRoomRegistry) ListAdmissions(ctx context.Context, roomName string, status AdmissionStatus) ([]Admission, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT room_name, email, display_name, status, requested_at, decided_at, decided_by FROM room_admissions
+		WHERE room_name = $1 AND ($2 = '' OR status = $2) ORDER BY requested_at`, roomName, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admissions of room %s: %w", roomName, err)
+	}
+	defer rows.Close()
+
+	var admissions []Admission
+	for rows.Next() {
+		var (
+			admission Admission
+			status    string
+			decidedAt sql.NullTime
+		)
+		err := rows.Scan(&admission.RoomName, &admission.Email, &admission.DisplayName, &status, &admission.RequestedAt, &decidedAt, &admission.DecidedBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan admission of room %s: %w", roomName, err)
+		}
+		admission.Status = AdmissionStatus(status)
+		admission.DecidedAt = decidedAt.Time
+		admissions = append(admissions, admission)
+	}
+	return admissions, rows.Err()
+}
+
+// upsertHost points the room at hostEmail inside tx
+func upsertHost(ctx context.Context, tx *sql.Tx, roomName, hostEmail string, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO room_hosts (room_name, host_email, assigned_at) VALUES ($1, $2, $3)
+		ON CONFLICT (room_name) DO UPDATE SET host_email = excluded.host_email, assigned_at = excluded.assigned_at`,
+		roomName, hostEmail, now)
+	if err != nil {
+		return fmt.Errorf("failed to set host of room %s: %w", roomName, err)
+	}
+	return nil
+}
+
+// recordingColumns is the column list shared by the recording queries, in scanRecording order
+const recordingColumns = `id, room_name, egress_id, kind, track_sid, status, started_by, room_creator, file_name, location, size, duration_ms, error, started_at, ended_at`
+
+// sqlRecordingRegistry implements RecordingRegistry on top of a SQL database
+type sqlRecordingRegistry struct {
+	db *sql.DB
+}
+
+// NewSQLRecordingRegistry creates a recording registry persisted in db
+func NewSQLRecordingRegistry(db *sql.DB) *sqlRecordingRegistry {
+	return &sqlRecordingRegistry{db: db}
+}
+
+// SaveRecording inserts or replaces the recording
+func (r *sqlRecordingRegistry) SaveRecording(ctx context.Context, recording *Recording) error {
+	var endedAt sql.NullTime
+	if !recording.EndedAt.IsZero() {
+		endedAt = sql.NullTime{Time: recording.EndedAt, Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO recordings (`+recordingColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (id) DO UPDATE SET status = excluded.status, file_name = excluded.file_name,
+			location = excluded.location, size = excluded.size, duration_ms = excluded.duration_ms,
+			error = excluded.error, ended_at = excluded.ended_at`,
+		recording.ID, recording.RoomName, recording.EgressID, string(recording.Kind), recording.TrackSid,
+		string(recording.Status), recording.StartedBy, recording.RoomCreator, recording.FileName, recording.Location, recording.Size,
+		recording.Duration.Milliseconds(), recording.Error, recording.StartedAt, endedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save recording %s: %w", recording.ID, err)
+	}
+	return nil
+}
+
+// GetRecording returns the recording with the given ID
+func (r *sqlRecordingRegistry) GetRecording(ctx context.Context, id string) (*Recording, bool, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+recordingColumns+` FROM recordings WHERE id = $1`, id)
+	return scanRecording(row)
+}
+
+// GetRecordingByEgress returns the recording produced by the egress
+func (r *sqlRecordingRegistry) GetRecordingByEgress(ctx context.Context, egressID string) (*Recording, bool, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+recordingColumns+` FROM recordings WHERE egress_id = $1`, egressID)
+	return scanRecording(row)
+}
+
+// ListRecordings returns the room's recordings, newest first
+func (r *sqlRecordingRegistry) ListRecordings(ctx context.Context, roomName string) ([]Recording, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+recordingColumns+` FROM recordings
+		WHERE room_name = $1 ORDER BY started_at DESC`, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings of room %s: %w", roomName, err)
+	}
+	return scanRecordings(rows)
+}
+
+// ListUserRecordings returns the recordings email started or made in rooms they created, newest first
+func (r *sqlRecordingRegistry) ListUserRecordings(ctx context.Context, email string) ([]Recording, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+recordingColumns+` FROM recordings
+		WHERE started_by = $1 OR room_creator = $1 ORDER BY started_at DESC`, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings of %s: %w", email, err)
+	}
+	return scanRecordings(rows)
+}
+
+// DeleteRecording forgets the recording
+func (r *sqlRecordingRegistry) DeleteRecording(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM recordings WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete recording %s: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: recording %s", ErrNotFound, id)
+	}
+	return nil
+}
+
+// scanRecordings reads every row of a query selecting recordingColumns
+func scanRecordings(rows *sql.Rows) ([]Recording, error) {
+	defer rows.Close()
+
+	var recordings []Recording
+	for rows.Next() {
+		recording, _, err := scanRecording(rows)
+		if err != nil {
+			return nil, err
+		}
+		recordings = append(recordings, *recording)
+	}
+	return recordings, rows.Err()
+}
+
+// scanRecording reads one row selected with recordingColumns
+func scanRecording(row interface{ Scan(dest ...any) error }) (*Recording, bool, error) {
+	var (
+		recording    Recording
+		kind, status string
+		durationMs   int64
+		startedAt    time.Time
+		endedAt      sql.NullTime
+	)
+	err := row.Scan(&recording.ID, &recording.RoomName, &recording.EgressID, &kind, &recording.TrackSid,
+		&status, &recording.StartedBy, &recording.RoomCreator, &recording.FileName, &recording.Location, &recording.Size,
+		&durationMs, &recording.Error, &startedAt, &endedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to scan recording: %w", err)
+	}
+
+	recording.Kind = RecordingKind(kind)
+	recording.Status = RecordingStatus(status)
+	recording.Duration = time.Duration(durationMs) * time.Millisecond
+	recording.StartedAt = startedAt.UTC()
+	if endedAt.Valid {
+		recording.EndedAt = endedAt.Time.UTC()
+	}
+	return &recording, true, nil
+}
+
+// sqlMeetingRegistry implements MeetingRegistry on top of a SQL database
+type sqlMeetingRegistry struct {
+	db *sql.DB
+}
+
+// NewSQLMeetingRegistry creates a meeting registry persisted in db
+func NewSQLMeetingRegistry(db *sql.DB) *sqlMeetingRegistry {
+	return &sqlMeetingRegistry{db: db}
+}
+
+// SaveMeeting inserts or replaces the meeting and its invitees
+func (r *sqlMeetingRegistry) SaveMeeting(ctx context.Context, meeting *Meeting) error {
+	var recurrence string
+	if meeting.Recurrence != nil {
+		encoded, err := json.Marshal(meeting.Recurrence)
+		if err != nil {
+			return fmt.Errorf("failed to encode recurrence of meeting %s: %w", meeting.Code, err)
+		}
+		recurrence = string(encoded)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO meetings (`+meetingColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE SET title = excluded.title, description = excluded.description,
+			start_at = excluded.start_at, end_at = excluded.end_at, timezone = excluded.timezone,
+			status = excluded.status, sequence = excluded.sequence, recurrence = excluded.recurrence,
+			updated_at = excluded.updated_at`,
+		meeting.ID, meeting.Code, meeting.Title, meeting.Description, meeting.StartAt, meeting.EndAt,
+		meeting.Timezone, meeting.HostEmail, string(meeting.Status), meeting.Sequence, recurrence,
+		meeting.CreatedAt, meeting.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save meeting %s: %w", meeting.Code, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM meeting_invitees WHERE meeting_id = $1`, meeting.ID); err != nil {
+		return fmt.Errorf("failed to clear invitees of meeting %s: %w", meeting.Code, err)
+	}
+	for _, email := range meeting.Invitees {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO meeting_invitees (meeting_id, email) VALUES ($1, $2)`, meeting.ID, email); err != nil {
+			return fmt.Errorf("failed to invite %s to meeting %s: %w", email, meeting.Code, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetMeeting returns the meeting with the given code
+func (r *sqlMeetingRegistry) GetMeeting(ctx context.Context, code string) (*Meeting, bool, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+meetingColumns+` FROM meetings WHERE code = $1`, code)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get meeting %s: %w", code, err)
+	}
+	meetings, err := r.scanMeetings(ctx, rows)
+	if err != nil || len(meetings) == 0 {
+		return nil, false, err
+	}
+	return &meetings[0], true, nil
+}
+
+// ListMeetings returns the meetings email hosts or is invited to, soonest first
+func (r *sqlMeetingRegistry) ListMeetings(ctx context.Context, email string) ([]Meeting, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+meetingColumns+` FROM meetings
+		WHERE host_email = $1 OR id IN (SELECT meeting_id FROM meeting_invitees WHERE email = $1)
+		ORDER BY start_at`, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meetings of %s: %w", email, err)
+	}
+	return r.scanMeetings(ctx, rows)
+}
+
+// ListScheduledMeetings returns the meetings that may have an occurrence starting in [from, to)
+func (r *sqlMeetingRegistry) ListScheduledMeetings(ctx context.Context, from, to time.Time) ([]Meeting, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+meetingColumns+` FROM meetings
+		WHERE status = $1 AND (recurrence <> '' OR (start_at >= $2 AND start_at < $3))
+		ORDER BY start_at`, string(MeetingScheduled), from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled meetings: %w", err)
+	}
+	return r.scanMeetings(ctx, rows)
+}
+
+// SetFeedToken replaces email's calendar feed token
+func (r *sqlMeetingRegistry) SetFeedToken(ctx context.Context, email, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO calendar_feeds (email, token_hash, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (email) DO UPDATE SET token_hash = excluded.token_hash, created_at = excluded.created_at`,
+		email, tokenHash, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to set calendar feed of %s: %w", email, err)
+	}
+	return nil
+}
+
+// GetFeedOwner returns whose calendar feed the token opens
+func (r *sqlMeetingRegistry) GetFeedOwner(ctx context.Context, tokenHash string) (string, bool, error) {
+	var email string
+	err := r.db.QueryRowContext(ctx, `SELECT email FROM calendar_feeds WHERE token_hash = $1`, tokenHash).Scan(&email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return email, true, nil
+}
+
+// SaveCalendarAccount stores the refresh token for email's external calendar
+func (r *sqlMeetingRegistry) SaveCalendarAccount(ctx context.Context, email, refreshToken string) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO calendar_accounts (email, refresh_token, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (email) DO UPDATE SET refresh_token = excluded.refresh_token, created_at = excluded.created_at`,
+		email, refreshToken, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save calendar account of %s: %w", email, err)
+	}
+	return nil
+}
+
+// GetCalendarAccount returns the refresh token for email's external calendar
+func (r *sqlMeetingRegistry) GetCalendarAccount(ctx context.Context, email string) (string, bool, error) {
+	var refreshToken string
+	err := r.db.QueryRowContext(ctx, `SELECT refresh_token FROM calendar_accounts WHERE email = $1`, email).Scan(&refreshToken)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get calendar account of %s: %w", email, err)
+	}
+	return refreshToken, true, nil
+}
+
+// DeleteCalendarAccount forgets email's external calendar
+func (r *sqlMeetingRegistry) DeleteCalendarAccount(ctx context.Context, email string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM calendar_accounts WHERE email = $1`, email); err != nil {
+		return fmt.Errorf("failed to delete calendar account of %s: %w", email, err)
+	}
+	return nil
+}
+
+// SetCalendarEvent records the external event a meeting was pushed as
+func (r *sqlMeetingRegistry) SetCalendarEvent(ctx context.Context, meetingID, eventID string) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO calendar_events (meeting_id, event_id) VALUES ($1, $2)
+		ON CONFLICT (meeting_id) DO UPDATE SET event_id = excluded.event_id`, meetingID, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to save calendar event of meeting %s: %w", meetingID, err)
+	}
+	return nil
+}
+
+// GetCalendarEvent returns the external event a meeting was pushed as
+func (r *sqlMeetingRegistry) GetCalendarEvent(ctx context.Context, meetingID string) (string, bool, error) {
+	var eventID string
+	err := r.db.QueryRowContext(ctx, `SELECT event_id FROM calendar_events WHERE meeting_id = $1`, meetingID).Scan(&eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get calendar event of meeting %s: %w", meetingID, err)
+	}
+	return eventID, true, nil
+}
+
+// DeleteCalendarEvent forgets the external event of a meeting
+func (r *sqlMeetingRegistry) DeleteCalendarEvent(ctx context.Context, meetingID string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM calendar_events WHERE meeting_id = $1`, meetingID); err != nil {
+		return fmt.Errorf("failed to delete calendar event of meeting %s: %w", meetingID, err)
+	}
+	return nil
+}
+
+// meetingColumns is the column list scanned by scanMeetings
+const meetingColumns = `id, code, title, description, start_at, end_at, timezone, host_email, status, sequence, recurrence, created_at, updated_at`
+
+// scanMeetings reads every row of a query selecting meetingColumns and loads their invitees
+func (r *sqlMeetingRegistry) scanMeetings(ctx context.Context, rows *sql.Rows) ([]Meeting, error) {
+	var meetings []Meeting
+	for rows.Next() {
+		var (
+			meeting    Meeting
+			status     string
+			recurrence string
+		)
+		err := rows.Scan(&meeting.ID, &meeting.Code, &meeting.Title, &meeting.Description, &meeting.StartAt, &meeting.EndAt,
+			&meeting.Timezone, &meeting.HostEmail, &status, &meeting.Sequence, &recurrence, &meeting.CreatedAt, &meeting.UpdatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan meeting: %w", err)
+		}
+		if recurrence != "" {
+			meeting.Recurrence = new(Recurrence)
+			if err := json.Unmarshal([]byte(recurrence), meeting.Recurrence); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to decode recurrence of meeting %s: %w", meeting.Code, err)
+			}
+		}
+		meeting.Status = MeetingStatus(status)
+		meeting.StartAt = meeting.StartAt.UTC()
+		meeting.EndAt = meeting.EndAt.UTC()
+		meetings = append(meetings, meeting)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list meetings: %w", err)
+	}
+
+	// Invitees are loaded after the meetings are read so the two queries never hold connections at once
+	for i := range meetings {
+		invitees, err := r.invitees(ctx, meetings[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		meetings[i].Invitees = invitees
+	}
+	return meetings, nil
+}
+
+func (r *sqlMeetingRegistry) invitees(ctx context.Context, meetingID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT email FROM meeting_invitees WHERE meeting_id = $1 ORDER BY email`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitees of meeting %s: %w", meetingID, err)
+	}
+	defer rows.Close()
+
+	invitees := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan invitee: %w", err)
+		}
+		invitees = append(invitees, email)
+	}
+	return invitees, rows.Err()
+}
+
+// sqlNotificationRegistry implements NotificationRegistry on top of a SQL database
+type sqlNotificationRegistry struct {
+	db *sql.DB
+}
+
+// NewSQLNotificationRegistry creates an outbox persisted in db, which keeps
+// notifications from being sent twice across restarts
+func NewSQLNotificationRegistry(db *sql.DB) *sqlNotificationRegistry {
+	return &sqlNotificationRegistry{db: db}
+}
+
+// EnqueueNotification stores the notification unless one with its key exists
+func (r *sqlNotificationRegistry) EnqueueNotification(ctx context.Context, notification *Notification) (bool, error) {
+	recipients, err := json.Marshal(notification.Recipients)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode recipients of notification %s: %w", notification.Key, err)
+	}
+
+	res, err := r.db.ExecContext(ctx, `INSERT INTO notifications (id, dedupe_key, channel, recipients, subject, body, url,
+			status, attempts, next_attempt_at, last_error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (dedupe_key) DO NOTHING`,
+		notification.ID, notification.Key, notification.Channel, string(recipients), notification.Subject, notification.Body,
+		notification.URL, string(notification.Status), notification.Attempts, notification.NextAttemptAt.UTC(),
+		notification.LastError, notification.CreatedAt.UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue notification %s: %w", notification.Key, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue notification %s: %w", notification.Key, err)
+	}
+	return n > 0, nil
+}
+
+// ClaimNotifications returns the pending notifications due at now, oldest first.
+// Each is claimed by moving its next attempt past the lease only if it is still due,
+// so a concurrent claim, which moved it past now, wins.
+func (r *sqlNotificationRegistry) ClaimNotifications(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Notification, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+notificationColumns+` FROM notifications
+		WHERE status = $1 AND next_attempt_at <= $2 ORDER BY next_attempt_at LIMIT $3`,
+		string(NotificationPending), now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due notifications: %w", err)
+	}
+	due, err := scanNotifications(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]Notification, 0, len(due))
+	for _, notification := range due {
+		res, err := r.db.ExecContext(ctx, `UPDATE notifications SET next_attempt_at = $1
+			WHERE id = $2 AND status = $3 AND next_attempt_at <= $4`,
+			now.Add(lease).UTC(), notification.ID, string(NotificationPending), now.UTC())
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim notification %s: %w", notification.Key, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			notification.NextAttemptAt = now.Add(lease).UTC()
+			claimed = append(claimed, notification)
+		}
+	}
+	return claimed, nil
+}
+
+// SaveNotification records the outcome of a delivery attempt
+func (r *sqlNotificationRegistry) SaveNotification(ctx context.Context, notification *Notification) error {
+	var sentAt sql.NullTime
+	if !notification.SentAt.IsZero() {
+		sentAt = sql.NullTime{Time: notification.SentAt.UTC(), Valid: true}
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE notifications SET status = $1, attempts = $2, next_attempt_at = $3,
+			last_error = $4, sent_at = $5
+		WHERE id = $6`,
+		string(notification.Status), notification.Attempts, notification.NextAttemptAt.UTC(),
+		notification.LastError, sentAt, notification.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save notification %s: %w", notification.Key, err)
+	}
+	return nil
+}
+
+// notificationColumns is the column list scanned by scanNotifications
+const notificationColumns = `id, dedupe_key, channel, recipients, subject, body, url, status, attempts,
+	next_attempt_at, last_error, created_at, sent_at`
+
+// scanNotifications reads every row of a query selecting notificationColumns
+func scanNotifications(rows *sql.Rows) ([]Notification, error) {
+	defer rows.Close()
+
+	var notifications []Notification
+	for rows.Next() {
+		var (
+			notification Notification
+			recipients   string
+			status       string
+			sentAt       sql.NullTime
+		)
+		err := rows.Scan(&notification.ID, &notification.Key, &notification.Channel, &recipients, &notification.Subject,
+			&notification.Body, &notification.URL, &status, &notification.Attempts, &notification.NextAttemptAt,
+			&notification.LastError, &notification.CreatedAt, &sentAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		if err := json.Unmarshal([]byte(recipients), &notification.Recipients); err != nil {
+			return nil, fmt.Errorf("failed to decode recipients of notification %s: %w", notification.Key, err)
+		}
+		notification.Status = NotificationStatus(status)
+		if sentAt.Valid {
+			notification.SentAt = sentAt.Time.UTC()
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+// sqlSessionRegistry implements SessionRegistry on top of a SQL database
+type sqlSessionRegistry struct {
+	db *sql.DB
+}
+
+// NewSQLSessionRegistry creates a session registry persisted in db, so sign-ins survive restarts
+func NewSQLSessionRegistry(db *sql.DB) *sqlSessionRegistry {
+	return &sqlSessionRegistry{db: db}
+}
+
+// CreateSession stores the session and its first refresh token
+func (r *sqlSessionRegistry) CreateSession(ctx context.Context, session *Session, refreshHash string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO sessions (id, provider, email, name, picture, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		session.ID, session.Provider, session.Email, session.Name, session.Picture, session.CreatedAt.UTC(), session.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create session %s: %w", session.ID, err)
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO refresh_tokens (token_hash, session_id, expires_at) VALUES ($1, $2, $3)`,
+		refreshHash, session.ID, session.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to store refresh token of session %s: %w", session.ID, err)
+	}
+	return tx.Commit()
+}
+
+// GetSession returns the session with the given ID
+func (r *sqlSessionRegistry) GetSession(ctx context.Context, id string) (*Session, bool, error) {
+	return scanSession(r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, id))
+}
+
+// RotateRefreshToken marks the old token used, only if nobody else did first, and stores its successor
+func (r *sqlSessionRegistry) RotateRefreshToken(ctx context.Context, oldHash, newHash string, now, expiresAt time.Time) (*Session, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		sessionID    string
+		tokenExpires time.Time
+		usedAt       sql.NullTime
+	)
+	err = tx.QueryRowContext(ctx, `SELECT session_id, expires_at, used_at FROM refresh_tokens WHERE token_hash = $1`, oldHash).
+		Scan(&sessionID, &tokenExpires, &usedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if !now.Before(tokenExpires) {
+		return nil, ErrNotFound
+	}
+	if usedAt.Valid {
+		return nil, ErrConflict
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = $1 WHERE token_hash = $2 AND used_at IS NULL`, now.UTC(), oldHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use refresh token: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return nil, ErrConflict
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO refresh_tokens (token_hash, session_id, expires_at) VALUES ($1, $2, $3)`,
+		newHash, sessionID, expiresAt.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token of session %s: %w", sessionID, err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE sessions SET expires_at = $1 WHERE id = $2`, expiresAt.UTC(), sessionID); err != nil {
+		return nil, fmt.Errorf("failed to extend session %s: %w", sessionID, err)
+	}
+
+	session, found, err := scanSession(tx.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, sessionID))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	return session, nil
+}
+
+// SessionOfRefreshToken returns the session a refresh token was issued for
+func (r *sqlSessionRegistry) SessionOfRefreshToken(ctx context.Context, hash string) (*Session, bool, error) {
+	return scanSession(r.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions
+		WHERE id = (SELECT session_id FROM refresh_tokens WHERE token_hash = $1)`, hash))
+}
+
+// RevokeSession ends the session and drops its refresh tokens
+func (r *sqlSessionRegistry) RevokeSession(ctx context.Context, id string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, now.UTC(), id); err != nil {
+		return fmt.Errorf("failed to revoke session %s: %w", id, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE session_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete refresh tokens of session %s: %w", id, err)
+	}
+	return tx.Commit()
+}
+
+// sessionColumns is the column list scanned by scanSession
+const sessionColumns = `id, provider, email, name, picture, created_at, expires_at, revoked_at`
+
+func scanSession(row interface{ Scan(dest ...any) error }) (*Session, bool, error) {
+	var (
+		session   Session
+		revokedAt sql.NullTime
+	)
+	err := row.Scan(&session.ID, &session.Provider, &session.Email, &session.Name, &session.Picture, &session.CreatedAt, &session.ExpiresAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to scan session: %w", err)
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = revokedAt.Time.UTC()
+	}
+	return &session, true, nil
+}
+
+// sqlInviteRegistry implements InviteRegistry on top of a SQL database
+type sqlInviteRegistry struct {
+	db *sql.DB
+}
+
+// NewSQLInviteRegistry creates an invite registry persisted in db
+func NewSQLInviteRegistry(db *sql.DB) *sqlInviteRegistry {
+	return &sqlInviteRegistry{db: db}
+}
+
+// CreateInvite stores the invite
+func (r *sqlInviteRegistry) CreateInvite(ctx context.Context, invite *Invite) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO invites (id, room_name, email, role, max_uses, uses, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		invite.ID, invite.RoomName, invite.Email, string(invite.Role), invite.MaxUses, invite.Uses, invite.CreatedBy,
+		invite.CreatedAt.UTC(), invite.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create invite %s: %w", invite.ID, err)
+	}
+	return nil
+}
+
+// GetInvite returns the invite with the given ID
+func (r *sqlInviteRegistry) GetInvite(ctx context.Context, id string) (*Invite, bool, error) {
+	return scanInvite(r.db.QueryRowContext(ctx, `SELECT `+inviteColumns+` FROM invites WHERE id = $1`, id))
+}
+
+// ListInvites returns the room's invites, newest first
+func (r *sqlInviteRegistry) ListInvites(ctx context.Context, roomName string) ([]Invite, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+inviteColumns+` FROM invites WHERE room_name = $1 ORDER BY created_at DESC`, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invites of room %s: %w", roomName, err)
+	}
+	defer rows.Close()
+
+	list := make([]Invite, 0)
+	for rows.Next() {
+		invite, _, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *invite)
+	}
+	return list, rows.Err()
+}
+
+// RedeemInvite counts email's first use of the invite, only while uses remain
+func (r *sqlInviteRegistry) RedeemInvite(ctx context.Context, id, email string, now time.Time) (*Invite, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	invite, found, err := scanInvite(tx.QueryRowContext(ctx, `SELECT `+inviteColumns+` FROM invites WHERE id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+	if !invite.RevokedAt.IsZero() || !now.Before(invite.ExpiresAt) {
+		return nil, ErrConflict
+	}
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO invite_redemptions (invite_id, email, redeemed_at) VALUES ($1, $2, $3)
+		ON CONFLICT (invite_id, email) DO NOTHING`, id, email, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem invite %s: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		// A new redeemer takes a use, unless the last one went first
+		res, err := tx.ExecContext(ctx, `UPDATE invites SET uses = uses + 1
+			WHERE id = $1 AND (max_uses = 0 OR uses < max_uses)`, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count use of invite %s: %w", id, err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return nil, ErrConflict
+		}
+		invite.Uses++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to redeem invite %s: %w", id, err)
+	}
+	return invite, nil
+}
+
+// CheckInvite reports whether email could redeem the invite at now
+func (r *sqlInviteRegistry) CheckInvite(ctx context.Context, id, email string, now time.Time) error {
+	invite, found, err := r.GetInvite(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotFound
+	}
+	if !invite.RevokedAt.IsZero() || !now.Before(invite.ExpiresAt) {
+		return ErrConflict
+	}
+	if invite.Usable(now) {
+		return nil
+	}
+
+	// Used up, but earlier redeemers may come back
+	var redeemed int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM invite_redemptions WHERE invite_id = $1 AND email = $2`,
+		id, email).Scan(&redeemed); err != nil {
+		return fmt.Errorf("failed to check redemption of invite %s: %w", id, err)
+	}
+	if redeemed == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// RevokeInvite marks the invite revoked
+func (r *sqlInviteRegistry) RevokeInvite(ctx context.Context, id string, now time.Time) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE invites SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, now.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invite %s: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		if _, found, err := r.GetInvite(ctx, id); err == nil && !found {
+			return ErrNotFound
+		}
+	}
+	return nil
+}
+
+// inviteColumns is the column list scanned by scanInvite
+const inviteColumns = `id, room_name, email, role, max_uses, uses, created_by, created_at, expires_at, revoked_at`
+
+func scanInvite(row interface{ Scan(dest ...any) error }) (*Invite, bool, error) {
+	var (
+		invite    Invite
+		role      string
+		revokedAt sql.NullTime
+	)
+	err := row.Scan(&invite.ID, &invite.RoomName, &invite.Email, &role, &invite.MaxUses, &invite.Uses, &invite.CreatedBy,
+		&invite.CreatedAt, &invite.ExpiresAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to scan invite: %w", err)
+	}
+	invite.Role = Role(role)
+	invite.CreatedAt = invite.CreatedAt.UTC()
+	invite.ExpiresAt = invite.ExpiresAt.UTC()
+	if revokedAt.Valid {
+		invite.RevokedAt = revokedAt.Time.UTC()
+	}
+	return &invite, true, nil
+}
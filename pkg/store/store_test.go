@@ -0,0 +1,31 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// eachRegistry runs test against the memory implementation of a registry and
+// against its SQL implementation on a fresh SQLite database
+func eachRegistry[R any](t *testing.T, newMemory func() R, newSQL func(*sql.DB) R, test func(t *testing.T, registry R)) {
+	t.Helper()
+	t.Run("memory", func(t *testing.T) {
+		test(t, newMemory())
+	})
+	t.Run("sql", func(t *testing.T) {
+		test(t, newSQL(openTestDatabase(t)))
+	})
+}
+
+// openTestDatabase opens a migrated SQLite database that is removed when the test ends
+func openTestDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenDatabase(context.Background(), "sqlite", filepath.Join(t.TempDir(), "open-meet.db"))
+	if err != nil {
+		t.Fatalf("OpenDatabase: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
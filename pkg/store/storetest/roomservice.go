@@ -0,0 +1,190 @@
+// Package storetest provides fakes for testing code built on the store package
+package storetest
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/proto"
+)
+
+// RoomService is an in-memory LiveKit room service for tests, satisfying store.RoomServiceClient
+type RoomService struct {
+	mu           sync.Mutex
+	rooms        map[string]*livekit.Room                       // map[roomName]room
+	participants map[string]map[string]*livekit.ParticipantInfo // map[roomName]map[identity]info
+	beforeWrite  func()                                         // runs once before the next write lands
+}
+
+// NewRoomService creates a room service without rooms
+func NewRoomService() *RoomService {
+	return &RoomService{
+		rooms:        make(map[string]*livekit.Room),
+		participants: make(map[string]map[string]*livekit.ParticipantInfo),
+	}
+}
+
+// Join connects identity to the room, creating the room if needed, with permission to publish everything
+func (f *RoomService) Join(roomName, identity string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.rooms[roomName]; !exists {
+		f.rooms[roomName] = &livekit.Room{Name: roomName}
+		f.participants[roomName] = make(map[string]*livekit.ParticipantInfo)
+	}
+	f.participants[roomName][identity] = &livekit.ParticipantInfo{
+		Identity: identity,
+		Permission: &livekit.ParticipantPermission{
+			CanSubscribe:   true,
+			CanPublish:     true,
+			CanPublishData: true,
+		},
+	}
+}
+
+// Permission returns the current permission of a connected participant
+func (f *RoomService) Permission(roomName, identity string) *livekit.ParticipantPermission {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return proto.Clone(f.participants[roomName][identity].GetPermission()).(*livekit.ParticipantPermission)
+}
+
+// Publish adds a track to a connected participant
+func (f *RoomService) Publish(roomName, identity string, track *livekit.TrackInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info := f.participants[roomName][identity]
+	info.Tracks = append(info.Tracks, proto.Clone(track).(*livekit.TrackInfo))
+}
+
+// OnNextWrite runs hook once, right before the next metadata or participant update lands
+func (f *RoomService) OnNextWrite(hook func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.beforeWrite = hook
+}
+
+// interceptWrite runs and clears the beforeWrite hook
+func (f *RoomService) interceptWrite() {
+	f.mu.Lock()
+	hook := f.beforeWrite
+	f.beforeWrite = nil
+	f.mu.Unlock()
+	if hook != nil {
+		hook()
+	}
+}
+
+func (f *RoomService) CreateRoom(ctx context.Context, req *livekit.CreateRoomRequest) (*livekit.Room, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.rooms[req.GetName()]; !exists {
+		f.rooms[req.GetName()] = &livekit.Room{Name: req.GetName()}
+		f.participants[req.GetName()] = make(map[string]*livekit.ParticipantInfo)
+	}
+	return proto.Clone(f.rooms[req.GetName()]).(*livekit.Room), nil
+}
+
+func (f *RoomService) ListRooms(ctx context.Context, req *livekit.ListRoomsRequest) (*livekit.ListRoomsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &livekit.ListRoomsResponse{}
+	for name, room := range f.rooms {
+		if len(req.GetNames()) == 0 || slices.Contains(req.GetNames(), name) {
+			resp.Rooms = append(resp.Rooms, proto.Clone(room).(*livekit.Room))
+		}
+	}
+	return resp, nil
+}
+
+func (f *RoomService) DeleteRoom(ctx context.Context, req *livekit.DeleteRoomRequest) (*livekit.DeleteRoomResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.rooms[req.GetRoom()]; !exists {
+		return nil, twirp.NotFoundError("room not found")
+	}
+	delete(f.rooms, req.GetRoom())
+	delete(f.participants, req.GetRoom())
+	return &livekit.DeleteRoomResponse{}, nil
+}
+
+func (f *RoomService) UpdateRoomMetadata(ctx context.Context, req *livekit.UpdateRoomMetadataRequest) (*livekit.Room, error) {
+	f.interceptWrite()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	room, exists := f.rooms[req.GetRoom()]
+	if !exists {
+		return nil, twirp.NotFoundError("room not found")
+	}
+	room.Metadata = req.GetMetadata()
+	return proto.Clone(room).(*livekit.Room), nil
+}
+
+func (f *RoomService) ListParticipants(ctx context.Context, req *livekit.ListParticipantsRequest) (*livekit.ListParticipantsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.rooms[req.GetRoom()]; !exists {
+		return nil, twirp.NotFoundError("room not found")
+	}
+	resp := &livekit.ListParticipantsResponse{}
+	for _, info := range f.participants[req.GetRoom()] {
+		resp.Participants = append(resp.Participants, proto.Clone(info).(*livekit.ParticipantInfo))
+	}
+	return resp, nil
+}
+
+func (f *RoomService) GetParticipant(ctx context.Context, req *livekit.RoomParticipantIdentity) (*livekit.ParticipantInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, exists := f.participants[req.GetRoom()][req.GetIdentity()]
+	if !exists {
+		return nil, twirp.NotFoundError("participant not found")
+	}
+	return proto.Clone(info).(*livekit.ParticipantInfo), nil
+}
+
+func (f *RoomService) UpdateParticipant(ctx context.Context, req *livekit.UpdateParticipantRequest) (*livekit.ParticipantInfo, error) {
+	f.interceptWrite()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, exists := f.participants[req.GetRoom()][req.GetIdentity()]
+	if !exists {
+		return nil, twirp.NotFoundError("participant not found")
+	}
+	if req.GetPermission() != nil {
+		info.Permission = proto.Clone(req.GetPermission()).(*livekit.ParticipantPermission)
+	}
+	if req.GetMetadata() != "" {
+		info.Metadata = req.GetMetadata()
+	}
+	return proto.Clone(info).(*livekit.ParticipantInfo), nil
+}
+
+func (f *RoomService) RemoveParticipant(ctx context.Context, req *livekit.RoomParticipantIdentity) (*livekit.RemoveParticipantResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.participants[req.GetRoom()][req.GetIdentity()]; !exists {
+		return nil, twirp.NotFoundError("participant not found")
+	}
+	delete(f.participants[req.GetRoom()], req.GetIdentity())
+	return &livekit.RemoveParticipantResponse{}, nil
+}
+
+func (f *RoomService) MutePublishedTrack(ctx context.Context, req *livekit.MuteRoomTrackRequest) (*livekit.MuteRoomTrackResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, exists := f.participants[req.GetRoom()][req.GetIdentity()]
+	if !exists {
+		return nil, twirp.NotFoundError("participant not found")
+	}
+	for _, track := range info.GetTracks() {
+		if track.GetSid() == req.GetTrackSid() {
+			track.Muted = req.GetMuted()
+			return &livekit.MuteRoomTrackResponse{Track: proto.Clone(track).(*livekit.TrackInfo)}, nil
+		}
+	}
+	return nil, twirp.NotFoundError("track not found")
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SuccessionPolicy decides who becomes host when the host leaves a room
+type SuccessionPolicy string
+
+const (
+	// SuccessionLongestPresent hands the room to the participant who has been in it the longest
+	SuccessionLongestPresent SuccessionPolicy = "longest_present"
+	// SuccessionCoHostFirst prefers a present co-host, then a moderator, and falls back to SuccessionLongestPresent
+	SuccessionCoHostFirst SuccessionPolicy = "cohost_first"
+	// SuccessionHostless leaves the room without a host
+	SuccessionHostless SuccessionPolicy = "hostless"
+)
+
+// ParseSuccessionPolicy validates a policy name; an empty name selects SuccessionLongestPresent
+func ParseSuccessionPolicy(name string) (SuccessionPolicy, error) {
+	switch policy := SuccessionPolicy(name); policy {
+	case "":
+		return SuccessionLongestPresent, nil
+	case SuccessionLongestPresent, SuccessionCoHostFirst, SuccessionHostless:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown host succession policy %q", name)
+	}
+}
+
+// SetSuccessionPolicy configures how the room picks a new host when the current one leaves
+func (h *host) SetSuccessionPolicy(ctx context.Context, roomName string, hostEmail string, policy SuccessionPolicy) error {
+	if _, err := h.authorize(ctx, roomName, hostEmail, PermManageSuccession, "change the succession policy"); err != nil {
+		return err
+	}
+
+	if err := h.registry.UpdateSettings(ctx, roomName, func(settings *RoomSettings) {
+		settings.SuccessionPolicy = policy
+	}); err != nil {
+		return fmt.Errorf("failed to store succession policy: %w", err)
+	}
+
+	return nil
+}
+
+// HandOff passes host privileges on when the host leaves the room, following the
+// room's succession policy, and publishes the outcome in the room metadata.
+// The new host is returned, or an empty string when departedEmail was not the host
+// or the room is left without one.
+func (h *host) HandOff(ctx context.Context, roomName string, departedEmail string) (string, error) {
+	record, found, err := h.registry.GetRoom(ctx, roomName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get room: %w", err)
+	}
+	if !found || record.HostEmail != departedEmail {
+		return "", nil
+	}
+
+	policy := record.Settings.SuccessionPolicy
+	if policy == "" {
+		policy = h.succession
+	}
+
+	if policy == SuccessionHostless {
+		if err := h.registry.ClearHost(ctx, roomName); err != nil {
+			return "", fmt.Errorf("failed to clear host: %w", err)
+		}
+		return "", h.publishHost(ctx, roomName)
+	}
+
+	presence, err := h.registry.ListPresence(ctx, roomName)
+	if err != nil {
+		return "", fmt.Errorf("failed to list participants: %w", err)
+	}
+
+	roles, err := h.registry.ListRoles(ctx, roomName)
+	if err != nil {
+		return "", fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	// Only signed-in users who may take part can run the room; guests and viewers never inherit it
+	var candidates []string
+	for _, p := range presence {
+		if !p.Present() || p.Identity == departedEmail || IsGuestIdentity(p.Identity) {
+			continue
+		}
+		if role, ok := roles[p.Identity]; ok && role == RoleViewer {
+			continue
+		}
+		candidates = append(candidates, p.Identity)
+	}
+	if len(candidates) == 0 {
+		// Nobody to hand over to; the host keeps the room in case they come back
+		return "", nil
+	}
+
+	// Presence is ordered by join time, so the first match has been present the longest
+	newHost := candidates[0]
+	if policy == SuccessionCoHostFirst {
+		newHost = highestRanked(candidates, roles)
+	}
+
+	if err := h.registry.SetHost(ctx, roomName, newHost); err != nil {
+		return "", fmt.Errorf("failed to hand off host: %w", err)
+	}
+	if err := h.registry.RemoveRole(ctx, roomName, newHost); err != nil {
+		return "", fmt.Errorf("failed to clear previous role: %w", err)
+	}
+
+	return newHost, h.publishHost(ctx, roomName)
+}
+
+// highestRanked returns the first co-host among candidates, else the first moderator,
+// else the first candidate
+func highestRanked(candidates []string, roles map[string]Role) string {
+	for _, role := range []Role{RoleCoHost, RoleModerator} {
+		for _, candidate := range candidates {
+			if roles[candidate] == role {
+				return candidate
+			}
+		}
+	}
+	return candidates[0]
+}
+
+// publishHost announces the room's host in the LiveKit room metadata so clients can react
+func (h *host) publishHost(ctx context.Context, roomName string) error {
+	_, err := h.metadata.PublishRoom(ctx, roomName)
+	if errors.Is(err, ErrNotFound) {
+		// The room is already gone from LiveKit; nothing to publish to
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish host: %w", err)
+	}
+	return nil
+}
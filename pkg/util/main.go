@@ -24,3 +24,11 @@ func GetUserEmailFromContext(c *gin.Context) (string, error) {
 
 	return emailStr, nil
 }
+
+// GetUserProfileFromContext returns the authenticated user's display name and picture URL,
+// or empty strings when the identity provider did not supply them
+func GetUserProfileFromContext(c *gin.Context) (string, string) {
+	name, _ := c.Value("name").(string)
+	picture, _ := c.Value("picture").(string)
+	return name, picture
+}